@@ -0,0 +1,226 @@
+package assertions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+/*
+Function to check that every assertion is well formed before it is saved
+*/
+func Validate(assertions []models.Assertion) error {
+	for i, assertion := range assertions {
+		err := validate(assertion)
+		if err != nil {
+			return fmt.Errorf("assertion %d (%s): %w", i+1, assertion.Type, err)
+		}
+	}
+	return nil
+}
+
+func validate(assertion models.Assertion) error {
+	switch assertion.Type {
+	case models.AssertStatus:
+		if len(assertion.Codes) == 0 && assertion.Min == 0 && assertion.Max == 0 {
+			return fmt.Errorf("codes or a min/max range is required")
+		}
+		if assertion.Max != 0 && assertion.Min > assertion.Max {
+			return fmt.Errorf("min must not be greater than max")
+		}
+	case models.AssertBodyContains, models.AssertBodyNotContains:
+		if assertion.Value == "" {
+			return fmt.Errorf("value is required")
+		}
+	case models.AssertBodyRegex:
+		_, err := regexp.Compile(assertion.Value)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	case models.AssertJSONPath:
+		_, err := parsePath(assertion.Path)
+		if err != nil {
+			return err
+		}
+		if len(assertion.Expected) > 0 && !json.Valid(assertion.Expected) {
+			return fmt.Errorf("expected must be a JSON value")
+		}
+	case models.AssertHeader:
+		if assertion.Header == "" {
+			return fmt.Errorf("header is required")
+		}
+	default:
+		return fmt.Errorf("unknown assertion type")
+	}
+	return nil
+}
+
+/*
+Function to run the assertions against a response in order, returning an error
+that names the first one to fail
+*/
+func Check(assertions []models.Assertion, response *http.Response, body []byte) error {
+	/*
+		This function keeps the default 2xx rule unless the list has its own status
+		assertion, so adding a body check never makes a 500 count as up.
+	*/
+	if !hasStatusAssertion(assertions) && (response.StatusCode < 200 || response.StatusCode >= 300) {
+		return fmt.Errorf("status %d is not 2xx", response.StatusCode)
+	}
+
+	for i, assertion := range assertions {
+		err := check(assertion, response, body)
+		if err != nil {
+			return fmt.Errorf("assertion %d (%s) failed: %w", i+1, describe(assertion), err)
+		}
+	}
+	return nil
+}
+
+// Assertion types that only look at the body, so they also work on a raw TCP reply
+var bodyTypes = map[string]bool{
+	models.AssertBodyContains:    true,
+	models.AssertBodyNotContains: true,
+	models.AssertBodyRegex:       true,
+	models.AssertJSONPath:        true,
+}
+
+/*
+Function to validate assertions for a check that has a reply body but no HTTP
+response, rejecting status and header assertions
+*/
+func ValidateBody(assertions []models.Assertion) error {
+	err := Validate(assertions)
+	if err != nil {
+		return err
+	}
+
+	for i, assertion := range assertions {
+		if !bodyTypes[assertion.Type] {
+			return fmt.Errorf("assertion %d (%s): only body assertions are supported here", i+1, assertion.Type)
+		}
+	}
+	return nil
+}
+
+/*
+Function to run body assertions against a raw reply, in order
+*/
+func CheckBody(assertions []models.Assertion, body []byte) error {
+	for i, assertion := range assertions {
+		if !bodyTypes[assertion.Type] {
+			return fmt.Errorf("assertion %d (%s) needs an HTTP response", i+1, assertion.Type)
+		}
+
+		err := check(assertion, nil, body)
+		if err != nil {
+			return fmt.Errorf("assertion %d (%s) failed: %w", i+1, describe(assertion), err)
+		}
+	}
+	return nil
+}
+
+func check(assertion models.Assertion, response *http.Response, body []byte) error {
+	switch assertion.Type {
+	case models.AssertStatus:
+		if !statusAccepted(assertion, response.StatusCode) {
+			return fmt.Errorf("got status %d", response.StatusCode)
+		}
+	case models.AssertBodyContains:
+		if !bytes.Contains(body, []byte(assertion.Value)) {
+			return fmt.Errorf("body does not contain %q", assertion.Value)
+		}
+	case models.AssertBodyNotContains:
+		if bytes.Contains(body, []byte(assertion.Value)) {
+			return fmt.Errorf("body contains %q", assertion.Value)
+		}
+	case models.AssertBodyRegex:
+		pattern, err := regexp.Compile(assertion.Value)
+		if err != nil {
+			return err
+		}
+		if !pattern.Match(body) {
+			return fmt.Errorf("body does not match")
+		}
+	case models.AssertJSONPath:
+		return checkJSONPath(assertion, body)
+	case models.AssertHeader:
+		got := response.Header.Get(assertion.Header)
+		if got != assertion.Value {
+			return fmt.Errorf("expected %q, got %q", assertion.Value, got)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type")
+	}
+	return nil
+}
+
+func checkJSONPath(assertion models.Assertion, body []byte) error {
+	var document any
+	err := json.Unmarshal(body, &document)
+	if err != nil {
+		return fmt.Errorf("body is not JSON")
+	}
+
+	value, err := lookup(document, assertion.Path)
+	if err != nil {
+		return err
+	}
+
+	// Without an expected value the assertion only requires the path to exist
+	if len(assertion.Expected) == 0 {
+		return nil
+	}
+
+	var expected any
+	err = json.Unmarshal(assertion.Expected, &expected)
+	if err != nil {
+		return fmt.Errorf("expected must be a JSON value")
+	}
+
+	if !reflect.DeepEqual(value, expected) {
+		got, _ := json.Marshal(value)
+		return fmt.Errorf("expected %s, got %s", assertion.Expected, got)
+	}
+	return nil
+}
+
+func statusAccepted(assertion models.Assertion, status int) bool {
+	for _, code := range assertion.Codes {
+		if code == status {
+			return true
+		}
+	}
+
+	if assertion.Min == 0 && assertion.Max == 0 {
+		return false
+	}
+	return status >= assertion.Min && (assertion.Max == 0 || status <= assertion.Max)
+}
+
+func hasStatusAssertion(assertions []models.Assertion) bool {
+	for _, assertion := range assertions {
+		if assertion.Type == models.AssertStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// describe names an assertion in failure messages
+func describe(assertion models.Assertion) string {
+	switch assertion.Type {
+	case models.AssertJSONPath:
+		return assertion.Type + " " + assertion.Path
+	case models.AssertHeader:
+		return assertion.Type + " " + strings.ToLower(assertion.Header)
+	default:
+		return assertion.Type
+	}
+}
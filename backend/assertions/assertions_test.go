@@ -0,0 +1,90 @@
+package assertions
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResponse(status int, headers map[string]string) *http.Response {
+	response := &http.Response{StatusCode: status, Header: http.Header{}}
+	for name, value := range headers {
+		response.Header.Set(name, value)
+	}
+	return response
+}
+
+func TestCheck_DefaultsToTwoHundreds(t *testing.T) {
+	body := []byte(`ok`)
+	list := []models.Assertion{{Type: models.AssertBodyContains, Value: "ok"}}
+
+	assert.NoError(t, Check(list, testResponse(200, nil), body))
+	assert.EqualError(t, Check(list, testResponse(500, nil), body), "status 500 is not 2xx")
+}
+
+func TestCheck_StatusRules(t *testing.T) {
+	codes := []models.Assertion{{Type: models.AssertStatus, Codes: []int{200, 404}}}
+	assert.NoError(t, Check(codes, testResponse(404, nil), nil))
+	assert.Error(t, Check(codes, testResponse(201, nil), nil))
+
+	statusRange := []models.Assertion{{Type: models.AssertStatus, Min: 200, Max: 399}}
+	assert.NoError(t, Check(statusRange, testResponse(302, nil), nil))
+	assert.EqualError(t, Check(statusRange, testResponse(503, nil), nil), "assertion 1 (status) failed: got status 503")
+}
+
+func TestCheck_ReportsFirstFailure(t *testing.T) {
+	body := []byte(`{"healthy": false, "checks": [{"name": "db", "ok": true}]}`)
+	response := testResponse(200, map[string]string{"Content-Type": "application/json"})
+
+	list := []models.Assertion{
+		{Type: models.AssertHeader, Header: "Content-Type", Value: "application/json"},
+		{Type: models.AssertBodyNotContains, Value: "maintenance"},
+		{Type: models.AssertBodyRegex, Value: `"name":\s*"db"`},
+		{Type: models.AssertJSONPath, Path: "$.checks[0].ok", Expected: json.RawMessage(`true`)},
+		{Type: models.AssertJSONPath, Path: "$.healthy", Expected: json.RawMessage(`true`)},
+		{Type: models.AssertBodyContains, Value: "never reached"},
+	}
+
+	err := Check(list, response, body)
+	require.Error(t, err)
+	assert.Equal(t, "assertion 5 (json_path $.healthy) failed: expected true, got false", err.Error())
+}
+
+func TestCheck_JSONPathMissingField(t *testing.T) {
+	list := []models.Assertion{{Type: models.AssertJSONPath, Path: "$['status'].db"}}
+
+	assert.NoError(t, Check(list, testResponse(200, nil), []byte(`{"status": {"db": "up"}}`)))
+	assert.Error(t, Check(list, testResponse(200, nil), []byte(`{"status": {}}`)))
+	assert.Error(t, Check(list, testResponse(200, nil), []byte(`<html>`)))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate([]models.Assertion{
+		{Type: models.AssertStatus, Codes: []int{200}},
+		{Type: models.AssertJSONPath, Path: "$.items[2].id", Expected: json.RawMessage(`"abc"`)},
+	}))
+
+	assert.EqualError(t, Validate([]models.Assertion{{Type: models.AssertStatus}}),
+		"assertion 1 (status): codes or a min/max range is required")
+	assert.Error(t, Validate([]models.Assertion{{Type: models.AssertBodyRegex, Value: "("}}))
+	assert.Error(t, Validate([]models.Assertion{{Type: models.AssertJSONPath, Path: "healthy"}}))
+	assert.Error(t, Validate([]models.Assertion{{Type: models.AssertJSONPath, Path: "$.a[x]"}}))
+	assert.Error(t, Validate([]models.Assertion{{Type: "xpath"}}))
+}
+
+func TestCheckBody(t *testing.T) {
+	list := []models.Assertion{
+		{Type: models.AssertBodyRegex, Value: `^\+PONG`},
+		{Type: models.AssertBodyNotContains, Value: "ERR"},
+	}
+
+	assert.NoError(t, CheckBody(list, []byte("+PONG\r\n")))
+	assert.EqualError(t, CheckBody(list, []byte("-ERR unknown command\r\n")), "assertion 1 (body_regex) failed: body does not match")
+
+	assert.NoError(t, ValidateBody(list))
+	assert.Error(t, ValidateBody([]models.Assertion{{Type: models.AssertHeader, Header: "Server"}}))
+}
@@ -0,0 +1,97 @@
+package assertions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is either an object key or an array index
+type pathSegment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+/*
+Function to parse the supported JSONPath subset: $ followed by .key, ['key'] and [index]
+*/
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			segments = append(segments, pathSegment{key: key, isKey: true})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1], isKey: true})
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", inner, path)
+			}
+			segments = append(segments, pathSegment{index: index})
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
+
+/*
+Function to find the value at path in a decoded JSON document
+*/
+func lookup(document any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, segment := range segments {
+		if segment.isKey {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: not an object at %q", path, segment.key)
+			}
+			current, ok = object[segment.key]
+			if !ok {
+				return nil, fmt.Errorf("%s: no field %q", path, segment.key)
+			}
+			continue
+		}
+
+		array, ok := current.([]any)
+		if !ok || segment.index >= len(array) {
+			return nil, fmt.Errorf("%s: no element %d", path, segment.index)
+		}
+		current = array[segment.index]
+	}
+
+	return current, nil
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/logging"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// LogEvents also writes every entry to the log as an "audit" event
+var LogEvents bool
+
+/*
+Function to record a change in the audit log. entry names the action and its
+target; before and after are snapshots of the target (nil when it was created
+or deleted) and must already have their secrets left out.
+*/
+func Record(ctx context.Context, entry models.AuditEntry, before any, after any) {
+	/*
+		This function fills in who made the change from the request's caller, the
+		snapshots and the fields that changed between them. The change has already
+		been made by the time it is recorded, so a failure is logged rather than
+		failing the request.
+	*/
+	span, _ := tracer.StartSpanFromContext(ctx, "audit.record")
+	defer span.Finish()
+
+	span.SetTag("audit.action", entry.Action)
+
+	entry.Timestamp = time.Now().UTC()
+	entry.Actor, entry.ActorType, entry.ActorID = actor(ctx)
+
+	var err error
+	entry.Before, err = snapshot(before)
+	if err == nil {
+		entry.After, err = snapshot(after)
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		log.Printf("Failed to record %s of %s: %v", entry.Action, entry.TargetID, err)
+		return
+	}
+
+	entry.Diff = Diff(entry.Before, entry.After)
+
+	entry.ID, err = db.SaveAuditEntry(entry)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		log.Printf("Failed to record %s of %s: %v", entry.Action, entry.TargetID, err)
+		return
+	}
+
+	if LogEvents {
+		logging.Event("audit", entry)
+	}
+}
+
+// actor is the name, type and ID of whoever made the request
+func actor(ctx context.Context) (string, string, string) {
+	caller, ok := auth.CallerFrom(ctx)
+	switch {
+	case ok && caller.User != nil:
+		return caller.User.Email, models.ActorUser, caller.User.ID
+	case ok && caller.Key != nil:
+		return caller.Key.Name, models.ActorAPIKey, caller.Key.ID
+	default:
+		return models.ActorSystem, models.ActorSystem, ""
+	}
+}
+
+// snapshot encodes a target as JSON, leaving nil as nothing
+func snapshot(value any) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+/*
+Function to compare two JSON objects field by field, returning the top level
+fields whose values differ. A field missing on one side is null there.
+*/
+func Diff(before json.RawMessage, after json.RawMessage) map[string]models.AuditChange {
+	beforeFields := map[string]json.RawMessage{}
+	afterFields := map[string]json.RawMessage{}
+
+	// Snapshots that aren't objects have no fields to compare
+	if len(before) > 0 && json.Unmarshal(before, &beforeFields) != nil {
+		return nil
+	}
+	if len(after) > 0 && json.Unmarshal(after, &afterFields) != nil {
+		return nil
+	}
+
+	diff := map[string]models.AuditChange{}
+
+	for field, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[field]) {
+			diff[field] = models.AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, seen := beforeFields[field]; !seen {
+			diff[field] = models.AuditChange{After: value}
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+	return diff
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := json.RawMessage(`{"url": "https://a.example.com", "check_interval": 30, "paused": false}`)
+	after := json.RawMessage(`{"url": "https://a.example.com", "check_interval": 60, "paused": false, "timeout": 5}`)
+
+	diff := Diff(before, after)
+
+	assert.Len(t, diff, 2)
+	assert.JSONEq(t, `30`, string(diff["check_interval"].Before))
+	assert.JSONEq(t, `60`, string(diff["check_interval"].After))
+	assert.Nil(t, diff["timeout"].Before)
+	assert.JSONEq(t, `5`, string(diff["timeout"].After))
+}
+
+func TestDiff_CreatedAndDeleted(t *testing.T) {
+	snapshot := json.RawMessage(`{"name": "hook"}`)
+
+	assert.JSONEq(t, `"hook"`, string(Diff(nil, snapshot)["name"].After))
+	assert.JSONEq(t, `"hook"`, string(Diff(snapshot, nil)["name"].Before))
+	assert.Nil(t, Diff(snapshot, snapshot))
+}
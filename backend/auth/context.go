@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Caller is who made a request: a logged in user or an API key, the scope that
+// gives them, and the teams they belong to
+type Caller struct {
+	Scope string
+	User  *models.User
+	Key   *models.APIKey
+	Teams []string
+}
+
+// Tenant is what the caller may see: a team's key only that team, admins every
+// team, and anyone else only their own teams
+func (c Caller) Tenant() db.Tenant {
+	if c.Key != nil && c.Key.TeamID != "" {
+		return db.ForTeams(c.Key.TeamID)
+	}
+	if c.Scope == models.ScopeAdmin {
+		return db.AllTeams
+	}
+	return db.ForTeams(c.Teams...)
+}
+
+// Context key for the caller a request was authenticated as
+type contextKey struct{}
+
+// WithCaller returns a context carrying the caller a request was made by
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, caller)
+}
+
+// CallerFrom returns the caller a request was made by, if it was authenticated
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(contextKey{}).(Caller)
+	return caller, ok
+}
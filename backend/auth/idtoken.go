@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// idTokenClaims are the ID token claims we check or use
+type idTokenClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified *bool    `json:"email_verified"`
+
+	raw map[string]any // <- Every claim, for the configurable groups claim
+}
+
+// audience is the aud claim, which may be one string or a list of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return fmt.Errorf("aud must be a string or a list of strings")
+	}
+	*a = list
+	return nil
+}
+
+// groups is the user's groups from the named claim, which may be a list or one string
+func (c idTokenClaims) groups(claim string) []string {
+	switch value := c.raw[claim].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var groups []string
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+/*
+Function to verify an ID token: an RS256 signature by one of the provider's
+JWKS keys, our client as audience, the provider as issuer, not expired, and the
+nonce of the login it was issued for
+*/
+func (p *OIDCProvider) verifyIDToken(token string, nonce string, now time.Time) (idTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return idTokenClaims{}, fmt.Errorf("id token is not a JWT")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return idTokenClaims{}, fmt.Errorf("error decoding id token header: %w", err)
+	}
+
+	// Only RS256, so a token can't pick "none" or an HMAC keyed with our public key
+	if header.Algorithm != "RS256" {
+		return idTokenClaims{}, fmt.Errorf("id token is signed with %s, not RS256", header.Algorithm)
+	}
+
+	key, err := p.signingKey(header.KeyID)
+	if err != nil {
+		return idTokenClaims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return idTokenClaims{}, fmt.Errorf("error decoding id token signature: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return idTokenClaims{}, fmt.Errorf("id token signature is invalid")
+	}
+
+	var claims idTokenClaims
+	err = decodeSegment(parts[1], &claims)
+	if err == nil {
+		err = decodeSegment(parts[1], &claims.raw)
+	}
+	if err != nil {
+		return idTokenClaims{}, fmt.Errorf("error decoding id token claims: %w", err)
+	}
+
+	switch {
+	case claims.Issuer != p.config.Issuer:
+		return idTokenClaims{}, fmt.Errorf("id token is from issuer %s", claims.Issuer)
+	case !slices.Contains(claims.Audience, p.config.ClientID):
+		return idTokenClaims{}, fmt.Errorf("id token is not for this client")
+	case now.After(time.Unix(claims.Expiry, 0).Add(oidcClockSkew)):
+		return idTokenClaims{}, fmt.Errorf("id token has expired")
+	case time.Unix(claims.IssuedAt, 0).After(now.Add(oidcClockSkew)):
+		return idTokenClaims{}, fmt.Errorf("id token is issued in the future")
+	case claims.Nonce != nonce:
+		return idTokenClaims{}, fmt.Errorf("id token nonce doesn't match the login")
+	case claims.Subject == "":
+		return idTokenClaims{}, fmt.Errorf("id token has no subject")
+	case claims.Email == "":
+		return idTokenClaims{}, fmt.Errorf("id token has no email")
+	case claims.EmailVerified != nil && !*claims.EmailVerified:
+		return idTokenClaims{}, fmt.Errorf("email %s is not verified", claims.Email)
+	}
+
+	return claims, nil
+}
+
+/*
+Function to get the provider's signing key with an ID, fetching the JWKS again
+when the key is new (e.g. after the provider rotated its keys)
+*/
+func (p *OIDCProvider) signingKey(keyID string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[keyID]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+
+	err := p.getJSON(p.discovery.JWKSURI, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching jwks: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok = keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("id token is signed with unknown key %q", keyID)
+	}
+	return key, nil
+}
+
+// decodeSegment decodes one base64url JSON part of a JWT
+func decodeSegment(segment string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/google/uuid"
+)
+
+// Start of every key, so a leaked one is easy to recognise
+const keyPrefix = "um_"
+
+// How much of a key is stored in the clear, to tell keys apart in a list
+const displayedPrefix = len(keyPrefix) + 8
+
+// Scopes from least to most access; a key may do anything its scope or a lower one allows
+var scopeRanks = map[string]int{
+	models.ScopeRead:  1,
+	models.ScopeWrite: 2,
+	models.ScopeAdmin: 3,
+}
+
+// ValidScope reports whether scope is one a key can be given
+func ValidScope(scope string) bool {
+	_, ok := scopeRanks[scope]
+	return ok
+}
+
+// Allows reports whether a key with scope may do something that needs required
+func Allows(scope string, required string) bool {
+	return scopeRanks[scope] > 0 && scopeRanks[scope] >= scopeRanks[required]
+}
+
+// HashKey is what is stored for a key. Keys are long and random, so a plain
+// SHA-256 is enough; there is nothing to brute force.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+/*
+Function to create and store a new API key for a team. Returns the stored key and
+the secret itself, which is not kept and can't be shown again.
+*/
+func CreateKey(name string, scope string, teamID string) (models.APIKey, string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("error generating api key: %w", err)
+	}
+
+	return saveKey(name, scope, teamID, keyPrefix+hex.EncodeToString(secret))
+}
+
+func saveKey(name string, scope string, teamID string, secret string) (models.APIKey, string, error) {
+	key := models.APIKey{
+		ID:        uuid.New().String(),
+		TeamID:    teamID,
+		Name:      name,
+		Scope:     scope,
+		Prefix:    secret[:min(displayedPrefix, len(secret))],
+		CreatedAt: time.Now(),
+	}
+
+	err := db.SaveAPIKey(key, HashKey(secret))
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+/*
+Function to make sure there is a way in on a fresh install. Only when no API key
+or user has ever been made is the ADMIN_API_KEY value (if set) stored as an admin
+key. Revoking every key later doesn't bring it back.
+*/
+func Bootstrap(adminKey string) error {
+	keys, err := db.CountAPIKeys()
+	if err != nil {
+		return err
+	}
+	users, err := db.CountUsers()
+	if err != nil {
+		return err
+	}
+	if keys > 0 || users > 0 {
+		return nil
+	}
+
+	if adminKey == "" {
+		log.Printf("No API keys or users yet: set ADMIN_API_KEY or run create-admin to get in")
+		return nil
+	}
+
+	_, _, err = saveKey("bootstrap", models.ScopeAdmin, "", adminKey)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Stored ADMIN_API_KEY as the first admin API key")
+	return nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+	m.Run()
+}
+
+// setupTestDB initializes a clean database for each test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db.CloseDB()
+	err := db.InitDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.CloseDB()
+	})
+}
+
+func TestAllows(t *testing.T) {
+	assert.True(t, Allows(models.ScopeAdmin, models.ScopeWrite))
+	assert.True(t, Allows(models.ScopeWrite, models.ScopeRead))
+	assert.True(t, Allows(models.ScopeRead, models.ScopeRead))
+	assert.False(t, Allows(models.ScopeRead, models.ScopeWrite))
+	assert.False(t, Allows(models.ScopeWrite, models.ScopeAdmin))
+	assert.False(t, Allows("", models.ScopeRead))
+}
+
+func TestCreateKey_StoresOnlyTheHash(t *testing.T) {
+	setupTestDB(t)
+
+	key, secret, err := CreateKey("ci", models.ScopeWrite, models.DefaultTeam)
+	require.NoError(t, err)
+	assert.Len(t, secret, len(keyPrefix)+64)
+	assert.Equal(t, secret[:displayedPrefix], key.Prefix)
+
+	stored, err := db.GetActiveAPIKey(HashKey(secret))
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, stored.ID)
+	assert.Equal(t, models.ScopeWrite, stored.Scope)
+
+	_, err = db.GetActiveAPIKey(secret)
+	assert.Error(t, err, "the key itself isn't stored")
+}
+
+func TestBootstrap(t *testing.T) {
+	setupTestDB(t)
+
+	require.NoError(t, Bootstrap("um_from_the_environment"))
+
+	stored, err := db.GetActiveAPIKey(HashKey("um_from_the_environment"))
+	require.NoError(t, err)
+	assert.Equal(t, models.ScopeAdmin, stored.Scope)
+
+	// Only on an install with no keys
+	require.NoError(t, Bootstrap("um_another"))
+
+	keys, err := db.GetAPIKeys(db.AllTeams)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	// Revoking every key doesn't make the install fresh again
+	require.NoError(t, db.RevokeAPIKey(db.AllTeams, keys[0].ID, time.Now()))
+	require.NoError(t, Bootstrap("um_another"))
+
+	_, err = db.GetActiveAPIKey(HashKey("um_another"))
+	assert.Error(t, err)
+}
+
+func TestBootstrap_NeedsAKeyFromTheEnvironment(t *testing.T) {
+	setupTestDB(t)
+
+	require.NoError(t, Bootstrap(""))
+
+	count, err := db.CountAPIKeys()
+	require.NoError(t, err)
+	assert.Zero(t, count, "no key is generated, so none can end up in the log")
+}
+
+func TestBootstrap_NotOnceThereAreUsers(t *testing.T) {
+	setupTestDB(t)
+
+	_, err := CreateFirstAdmin("admin@example.com", "long enough password")
+	require.NoError(t, err)
+
+	require.NoError(t, Bootstrap("um_from_the_environment"))
+
+	count, err := db.CountAPIKeys()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,422 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/google/uuid"
+)
+
+// SSO is the identity provider users log in with, nil when single sign-on isn't configured
+var SSO *OIDCProvider
+
+// Cookie tying a login's callback to the browser that started it
+const OIDCStateCookie = "oidc_state"
+
+// How long a user has to finish logging in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// Allowed clock difference between us and the provider when checking token times
+const oidcClockSkew = time.Minute
+
+// ErrNoRole is returned when none of a user's groups maps to a role and there is no default
+var ErrNoRole = errors.New("none of your groups has access")
+
+// ErrUnverifiedEmail is returned when an SSO login matches an existing account by an email the provider hasn't verified
+var ErrUnverifiedEmail = errors.New("email isn't verified by the identity provider")
+
+// SSOChange is a change a login made to its user, for the caller to put in the audit log
+type SSOChange struct {
+	Action  string       // <- "user.create", "user.sso_link" or "user.update"
+	Before  *models.User // <- nil when the login created the user
+	After   models.User
+	Issuer  string // <- The provider identity given to the user, set when it was created or linked
+	Subject string
+}
+
+// OIDCConfig is how to reach the identity provider and how its users map to roles
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string            // <- This API's /login/oidc/callback, as registered with the provider
+	AfterLogin   string            // <- Where the browser is sent once logged in, e.g. the dashboard
+	GroupsClaim  string            // <- ID token claim listing the user's groups, "groups" if unset
+	GroupRoles   map[string]string // <- Group to role; the highest role among a user's groups wins
+	DefaultRole  string            // <- Role for users in none of GroupRoles, empty refuses them
+}
+
+// OIDCProvider logs users in with the authorization code flow and PKCE
+type OIDCProvider struct {
+	config    OIDCConfig
+	discovery oidcDiscovery
+	client    *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey // <- Signing keys from the JWKS, by key ID
+	pending map[string]oidcLogin      // <- Logins waiting for their callback, by state
+}
+
+// oidcDiscovery is the part of the provider's discovery document we use
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcLogin is what a started login needs to be finished
+type oidcLogin struct {
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+}
+
+/*
+Function to set up a provider from its discovery document, which has to be
+served at the issuer's /.well-known/openid-configuration
+*/
+func NewOIDCProvider(config OIDCConfig) (*OIDCProvider, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc needs an issuer, client id and redirect url")
+	}
+
+	for group, role := range config.GroupRoles {
+		if !ValidRole(role) {
+			return nil, fmt.Errorf("group %s maps to unknown role %q", group, role)
+		}
+	}
+	if config.DefaultRole != "" && !ValidRole(config.DefaultRole) {
+		return nil, fmt.Errorf("unknown default role %q", config.DefaultRole)
+	}
+
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.AfterLogin == "" {
+		config.AfterLogin = "/"
+	}
+
+	provider := &OIDCProvider{
+		config:  config,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		keys:    map[string]*rsa.PublicKey{},
+		pending: map[string]oidcLogin{},
+	}
+
+	err := provider.getJSON(strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", &provider.discovery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching oidc discovery document: %w", err)
+	}
+
+	if provider.discovery.Issuer != config.Issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %s, not %s", provider.discovery.Issuer, config.Issuer)
+	}
+	if provider.discovery.AuthorizationEndpoint == "" || provider.discovery.TokenEndpoint == "" || provider.discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing an endpoint")
+	}
+
+	return provider, nil
+}
+
+// AfterLogin is where the browser is sent once a login has finished
+func (p *OIDCProvider) AfterLogin() string {
+	return p.config.AfterLogin
+}
+
+/*
+Function to start a login. Returns the provider URL to send the browser to and
+the state, which the callback has to come back with.
+*/
+func (p *OIDCProvider) Begin() (string, string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	for pendingState, login := range p.pending {
+		if now.After(login.expiresAt) {
+			delete(p.pending, pendingState)
+		}
+	}
+	p.pending[state] = oidcLogin{verifier: verifier, nonce: nonce, expiresAt: now.Add(oidcLoginTTL)}
+	p.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.discovery.AuthorizationEndpoint + separator + query.Encode(), state, nil
+}
+
+/*
+Function to finish a login: trade the code for tokens, verify the ID token, and
+find or create the user with the role their groups map to. The changes made to
+the user are returned for the caller to audit, as auth can't import audit.
+*/
+func (p *OIDCProvider) Finish(ctx context.Context, state string, code string) (models.User, []SSOChange, error) {
+	p.mu.Lock()
+	login, ok := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(login.expiresAt) {
+		return models.User{}, nil, fmt.Errorf("login expired or was never started")
+	}
+
+	idToken, err := p.exchange(ctx, code, login.verifier)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	claims, err := p.verifyIDToken(idToken, login.nonce, time.Now())
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	role, err := p.roleFor(claims)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	return ssoUser(claims, role)
+}
+
+/*
+Function to trade an authorization code and its PKCE verifier for an ID token
+*/
+func (p *OIDCProvider) exchange(ctx context.Context, code string, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error building token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("error calling token endpoint: %w", err)
+	}
+	defer response.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&tokens)
+	if err != nil {
+		return "", fmt.Errorf("error decoding token response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK || tokens.Error != "" {
+		return "", fmt.Errorf("token endpoint refused the code: %d %s %s", response.StatusCode, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return "", fmt.Errorf("token response has no id_token")
+	}
+
+	return tokens.IDToken, nil
+}
+
+/*
+Function to pick a user's role from their groups: the highest role any of them
+maps to, else the default role
+*/
+func (p *OIDCProvider) roleFor(claims idTokenClaims) (string, error) {
+	role := ""
+
+	for _, group := range claims.groups(p.config.GroupsClaim) {
+		mapped, ok := p.config.GroupRoles[group]
+		if ok && scopeRanks[RoleScope(mapped)] > scopeRanks[RoleScope(role)] {
+			role = mapped
+		}
+	}
+
+	if role == "" {
+		role = p.config.DefaultRole
+	}
+	if role == "" {
+		return "", ErrNoRole
+	}
+
+	return role, nil
+}
+
+/*
+Function to find the user an SSO login is for, creating them on first login.
+Users are found by the provider's (issuer, subject) identity. An existing account
+with the same email is only linked to it when the provider says the email is
+verified, so the provider can't hand out logins to accounts it doesn't own.
+*/
+func ssoUser(claims idTokenClaims, role string) (models.User, []SSOChange, error) {
+	/*
+		The provider decides the role of the users it created, so their role
+		follows their groups (though never demoting the last admin). Local accounts
+		with a password keep the role an admin gave them.
+	*/
+	email := NormalizeEmail(claims.Email)
+	var changes []SSOChange
+
+	user, passwordHash, err := db.GetUserBySSO(claims.Issuer, claims.Subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, passwordHash, err = db.GetUserByEmail(email)
+		if errors.Is(err, sql.ErrNoRows) {
+			user = models.User{
+				ID:        uuid.New().String(),
+				Email:     email,
+				Role:      role,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			err = db.SaveSSOUser(user, claims.Issuer, claims.Subject)
+			if err != nil {
+				return models.User{}, nil, err
+			}
+			return user, []SSOChange{{Action: "user.create", After: user, Issuer: claims.Issuer, Subject: claims.Subject}}, nil
+		}
+		if err != nil {
+			return models.User{}, nil, err
+		}
+
+		if claims.EmailVerified == nil || !*claims.EmailVerified {
+			return models.User{}, nil, ErrUnverifiedEmail
+		}
+
+		err = db.LinkUserSSO(user.ID, claims.Issuer, claims.Subject)
+		if err == nil {
+			linked := user
+			changes = append(changes, SSOChange{Action: "user.sso_link", Before: &linked, After: user, Issuer: claims.Issuer, Subject: claims.Subject})
+		}
+	}
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	if passwordHash != "" || user.Role == role {
+		return user, changes, nil
+	}
+
+	if user.Role == models.RoleAdmin {
+		admins, err := db.CountUsersWithRole(models.RoleAdmin)
+		if err != nil {
+			return models.User{}, nil, err
+		}
+		if admins <= 1 {
+			log.Printf("Kept %s as admin: they are the only one left", user.Email)
+			return user, changes, nil
+		}
+	}
+
+	before := user
+	user.Role = role
+	user.UpdatedAt = time.Now()
+
+	err = db.UpdateUser(user, "")
+	if err != nil {
+		return models.User{}, nil, err
+	}
+	changes = append(changes, SSOChange{Action: "user.update", Before: &before, After: user})
+
+	return user, changes, nil
+}
+
+/*
+Function to GET a JSON document from the provider
+*/
+func (p *OIDCProvider) getJSON(rawURL string, target any) error {
+	response, err := p.client.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", rawURL, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// randomToken is a random URL-safe value for states, nonces and PKCE verifiers
+func randomToken() (string, error) {
+	value := make([]byte, 32)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", fmt.Errorf("error generating oidc token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+/*
+Function to parse OIDC_GROUP_ROLES-style settings: comma separated group=role pairs
+*/
+func ParseGroupRoles(value string) (map[string]string, error) {
+	groupRoles := map[string]string{}
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		group, role, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("group role %q should look like group=role", pair)
+		}
+		groupRoles[strings.TrimSpace(group)] = strings.TrimSpace(role)
+	}
+
+	return groupRoles, nil
+}
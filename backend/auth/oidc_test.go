@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth/oidctest"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOIDCProvider starts a mock identity provider and a client of it
+func newTestOIDCProvider(t *testing.T) (*oidctest.Provider, *OIDCProvider) {
+	t.Helper()
+
+	mock := oidctest.NewProvider(t, "url-monitor", "client-secret")
+
+	provider, err := NewOIDCProvider(OIDCConfig{
+		Issuer:       mock.Issuer(),
+		ClientID:     "url-monitor",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/login/oidc/callback",
+		GroupRoles:   map[string]string{"sre": models.RoleEditor, "platform": models.RoleAdmin},
+	})
+	require.NoError(t, err)
+
+	return mock, provider
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	mock, provider := newTestOIDCProvider(t)
+
+	valid := map[string]any{"nonce": "n1", "email": "alice@example.com"}
+	with := func(name string, value any) map[string]any {
+		claims := map[string]any{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[name] = value
+		return claims
+	}
+
+	tampered := mock.SignToken(valid)
+	parts := strings.Split(tampered, ".")
+	parts[1] = strings.Split(mock.SignToken(with("email", "mallory@example.com")), ".")[1]
+	tampered = strings.Join(parts, ".")
+
+	// An unsigned token, as if an attacker picked alg "none"
+	unsigned := "eyJhbGciOiJub25lIn0." + strings.Split(mock.SignToken(valid), ".")[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", mock.SignToken(valid), true},
+		{"audience list", mock.SignToken(with("aud", []string{"other", "url-monitor"})), true},
+		{"other audience", mock.SignToken(with("aud", "other")), false},
+		{"other issuer", mock.SignToken(with("iss", "https://evil.example.com")), false},
+		{"expired", mock.SignToken(with("exp", time.Now().Add(-time.Hour).Unix())), false},
+		{"other nonce", mock.SignToken(with("nonce", "n2")), false},
+		{"unverified email", mock.SignToken(with("email_verified", false)), false},
+		{"no subject", mock.SignToken(with("sub", "")), false},
+		{"tampered claims", tampered, false},
+		{"unsigned", unsigned, false},
+		{"not a jwt", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.verifyIDToken(tt.token, "n1", time.Now())
+			if tt.valid {
+				require.NoError(t, err)
+				assert.Equal(t, "alice@example.com", claims.Email)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOIDCRoleFor(t *testing.T) {
+	_, provider := newTestOIDCProvider(t)
+
+	role, err := provider.roleFor(idTokenClaims{raw: map[string]any{"groups": []any{"sre", "platform", "other"}}})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, role, "the highest role wins")
+
+	role, err = provider.roleFor(idTokenClaims{raw: map[string]any{"groups": "sre"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, role)
+
+	_, err = provider.roleFor(idTokenClaims{raw: map[string]any{"groups": []any{"other"}}})
+	assert.ErrorIs(t, err, ErrNoRole)
+
+	provider.config.DefaultRole = models.RoleViewer
+	role, err = provider.roleFor(idTokenClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, role)
+}
+
+func TestOIDCFinish_UnknownState(t *testing.T) {
+	setupTestDB(t)
+	_, provider := newTestOIDCProvider(t)
+
+	_, _, err := provider.Finish(t.Context(), "never-started", "code")
+	assert.Error(t, err)
+}
+
+func TestSSOUser_LinksBySubject(t *testing.T) {
+	setupTestDB(t)
+
+	claims := idTokenClaims{Issuer: "https://idp.example.com", Subject: "user-1", Email: "alice@example.com"}
+
+	created, changes, err := ssoUser(claims, models.RoleEditor)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "user.create", changes[0].Action)
+	assert.Nil(t, changes[0].Before)
+
+	// The provider's subject, not the email, says who is logging in
+	claims.Email = "alice.smith@example.com"
+	user, changes, err := ssoUser(claims, models.RoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+	assert.Equal(t, models.RoleViewer, user.Role, "SSO users' roles follow their groups")
+	require.Len(t, changes, 1)
+	assert.Equal(t, "user.update", changes[0].Action)
+	assert.Equal(t, models.RoleEditor, changes[0].Before.Role)
+
+	_, changes, err = ssoUser(claims, models.RoleViewer)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "a login that changes nothing has nothing to audit")
+
+	// Another provider's user with the same subject is someone else
+	claims.Issuer = "https://other-idp.example.com"
+	claims.Email = "bob@example.com"
+	other, _, err := ssoUser(claims, models.RoleViewer)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ID, other.ID)
+}
+
+func TestSSOUser_LocalAccounts(t *testing.T) {
+	setupTestDB(t)
+
+	local := models.User{ID: "local-1", Email: "admin@example.com", Role: models.RoleAdmin, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, db.SaveUser(local, "password-hash"))
+
+	claims := idTokenClaims{Issuer: "https://idp.example.com", Subject: "user-9", Email: "Admin@example.com"}
+
+	_, _, err := ssoUser(claims, models.RoleViewer)
+	assert.ErrorIs(t, err, ErrUnverifiedEmail, "a missing email_verified doesn't link")
+
+	verified := false
+	claims.EmailVerified = &verified
+	_, _, err = ssoUser(claims, models.RoleViewer)
+	assert.ErrorIs(t, err, ErrUnverifiedEmail)
+
+	verified = true
+	user, changes, err := ssoUser(claims, models.RoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, local.ID, user.ID)
+	assert.Equal(t, models.RoleAdmin, user.Role, "local accounts keep their role")
+	require.Len(t, changes, 1)
+	assert.Equal(t, "user.sso_link", changes[0].Action)
+	assert.Equal(t, "user-9", changes[0].Subject)
+
+	stored, err := db.GetUser(local.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, stored.Role)
+}
+
+func TestSSOUser_KeepsTheLastAdmin(t *testing.T) {
+	setupTestDB(t)
+
+	claims := idTokenClaims{Issuer: "https://idp.example.com", Subject: "user-1", Email: "alice@example.com"}
+
+	_, _, err := ssoUser(claims, models.RoleAdmin)
+	require.NoError(t, err)
+
+	user, _, err := ssoUser(claims, models.RoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+}
+
+func TestParseGroupRoles(t *testing.T) {
+	roles, err := ParseGroupRoles("sre=editor, platform = admin,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"sre": "editor", "platform": "admin"}, roles)
+
+	_, err = ParseGroupRoles("sre")
+	assert.Error(t, err)
+
+	_, err = NewOIDCProvider(OIDCConfig{Issuer: "https://idp", ClientID: "x", RedirectURL: "y",
+		GroupRoles: map[string]string{"sre": "owner"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+// Package oidctest runs a minimal OpenID Connect provider for tests: discovery,
+// JWKS, an authorize endpoint that logs the user straight in, and a token
+// endpoint that checks PKCE and returns an RS256 signed ID token.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Provider is a running mock provider. Claims set on it are added to the ID
+// token of every login, e.g. email and groups.
+type Provider struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+	Claims       map[string]any
+
+	key   *rsa.PrivateKey
+	keyID string
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+// authorization is what the token endpoint needs to redeem a code
+type authorization struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+/*
+Function to start a mock provider for a client, stopped when the test ends
+*/
+func NewProvider(t testing.TB, clientID string, clientSecret string) *Provider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating provider key: %v", err)
+	}
+
+	provider := &Provider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Claims:       map[string]any{},
+		key:          key,
+		keyID:        "test-key",
+		codes:        map[string]authorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("GET /jwks", provider.jwks)
+	mux.HandleFunc("GET /authorize", provider.authorize)
+	mux.HandleFunc("POST /token", provider.token)
+
+	provider.Server = httptest.NewServer(mux)
+	t.Cleanup(provider.Server.Close)
+
+	return provider
+}
+
+// Issuer is the provider's issuer URL
+func (p *Provider) Issuer() string {
+	return p.Server.URL
+}
+
+/*
+Function to sign an ID token with the provider's key. Standard claims the
+caller leaves out are filled in: issuer, audience and a valid lifetime.
+*/
+func (p *Provider) SignToken(claims map[string]any) string {
+	full := map[string]any{
+		"iss": p.Issuer(),
+		"aud": p.ClientID,
+		"sub": "user-1",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		full[name] = value
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.keyID})
+	payload, _ := json.Marshal(full)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 p.Issuer(),
+		"authorization_endpoint": p.Issuer() + "/authorize",
+		"token_endpoint":         p.Issuer() + "/token",
+		"jwks_uri":               p.Issuer() + "/jwks",
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": p.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize logs the user straight in and sends the browser back with a code
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != p.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+
+	random := make([]byte, 16)
+	rand.Read(random)
+	code := base64.RawURLEncoding.EncodeToString(random)
+
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+	}
+	p.mu.Unlock()
+
+	callback, _ := url.Parse(query.Get("redirect_uri"))
+	values := callback.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	callback.RawQuery = values.Encode()
+
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+// token redeems a code once, for the client and verifier it was issued to
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, _ := r.BasicAuth()
+	clientID, _ = url.QueryUnescape(clientID)
+	clientSecret, _ = url.QueryUnescape(clientSecret)
+
+	p.mu.Lock()
+	auth, ok := p.codes[r.FormValue("code")]
+	delete(p.codes, r.FormValue("code"))
+	p.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+
+	if !ok || clientID != p.ClientID || clientSecret != p.ClientSecret ||
+		r.FormValue("grant_type") != "authorization_code" ||
+		r.FormValue("redirect_uri") != auth.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := map[string]any{"nonce": auth.nonce}
+	for name, value := range p.Claims {
+		claims[name] = value
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     p.SignToken(claims),
+	})
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cookie a login's session token is sent back in
+const SessionCookie = "session"
+
+// How long a login lasts before the user has to log in again
+const SessionTTL = 7 * 24 * time.Hour
+
+// Shortest password a user may set
+const minPasswordLength = 8
+
+// Scope each role has, so users and API keys go through the same route checks
+var roleScopes = map[string]string{
+	models.RoleViewer: models.ScopeRead,
+	models.RoleEditor: models.ScopeWrite,
+	models.RoleAdmin:  models.ScopeAdmin,
+}
+
+// ErrInvalidLogin is returned for an unknown email or a wrong password alike
+var ErrInvalidLogin = errors.New("invalid email or password")
+
+// Compared against when the email is unknown, so a login takes as long either way
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// ValidRole reports whether role is one a user can be given
+func ValidRole(role string) bool {
+	_, ok := roleScopes[role]
+	return ok
+}
+
+// RoleScope is the API key scope with the same access as role
+func RoleScope(role string) string {
+	return roleScopes[role]
+}
+
+// NormalizeEmail is the form emails are stored and looked up in
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+/*
+Function to check an email and password are fit to be saved for a user
+*/
+func ValidateCredentials(email string, password string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return fmt.Errorf("email must be a valid email address")
+	}
+
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	// bcrypt only looks at the first 72 bytes
+	if len(password) > 72 {
+		return fmt.Errorf("password must be at most 72 bytes")
+	}
+
+	return nil
+}
+
+// HashPassword is what is stored for a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+	return string(hash), nil
+}
+
+/*
+Function to create and store a new user. The email is normalized and the
+password hashed before saving.
+*/
+func CreateUser(email string, password string, role string) (models.User, error) {
+	email = NormalizeEmail(email)
+
+	err := ValidateCredentials(email, password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if !ValidRole(role) {
+		return models.User{}, fmt.Errorf("role must be viewer, editor or admin")
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user := models.User{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Role:      role,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err = db.SaveUser(user, hash)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+/*
+Function to create the first admin, for the create-admin command. Refuses once
+an admin exists, so it can't be used to take over an install.
+*/
+func CreateFirstAdmin(email string, password string) (models.User, error) {
+	count, err := db.CountUsersWithRole(models.RoleAdmin)
+	if err != nil {
+		return models.User{}, err
+	}
+	if count > 0 {
+		return models.User{}, fmt.Errorf("an admin user already exists")
+	}
+
+	return CreateUser(email, password, models.RoleAdmin)
+}
+
+/*
+Function to check a user's email and password. Returns ErrInvalidLogin when
+either is wrong.
+*/
+func Login(email string, password string) (models.User, error) {
+	user, hash, err := db.GetUserByEmail(NormalizeEmail(email))
+	if errors.Is(err, sql.ErrNoRows) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return models.User{}, ErrInvalidLogin
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		return models.User{}, ErrInvalidLogin
+	}
+
+	return user, nil
+}
+
+/*
+Function to start a session for a user. Returns the session token for the
+cookie, which is stored only as a hash, and when it expires.
+*/
+func CreateSession(userID string) (string, time.Time, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error generating session token: %w", err)
+	}
+
+	token := hex.EncodeToString(secret)
+	now := time.Now()
+	expiresAt := now.Add(SessionTTL)
+
+	err = db.SaveSession(HashKey(token), userID, now, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// SessionUser is the user a session token belongs to, if it is still valid
+func SessionUser(token string) (models.User, error) {
+	return db.GetSessionUser(HashKey(token), time.Now())
+}
+
+// EndSession logs a session token out
+func EndSession(token string) error {
+	return db.DeleteSession(HashKey(token))
+}
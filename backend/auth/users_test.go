@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUser_HashesPassword(t *testing.T) {
+	setupTestDB(t)
+
+	user, err := CreateUser(" Alice@Example.com ", "correct horse", models.RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	_, hash, err := db.GetUserByEmail("alice@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+
+	_, err = CreateUser("bob@example.com", "short", models.RoleViewer)
+	assert.Error(t, err)
+	_, err = CreateUser("not an email", "long enough", models.RoleViewer)
+	assert.Error(t, err)
+	_, err = CreateUser("bob@example.com", "long enough", "owner")
+	assert.Error(t, err)
+}
+
+func TestLogin(t *testing.T) {
+	setupTestDB(t)
+
+	created, err := CreateUser("alice@example.com", "correct horse", models.RoleViewer)
+	require.NoError(t, err)
+
+	user, err := Login("ALICE@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+
+	_, err = Login("alice@example.com", "wrong horse")
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+
+	_, err = Login("nobody@example.com", "correct horse")
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+}
+
+func TestSessions(t *testing.T) {
+	setupTestDB(t)
+
+	user, err := CreateUser("alice@example.com", "correct horse", models.RoleViewer)
+	require.NoError(t, err)
+
+	token, _, err := CreateSession(user.ID)
+	require.NoError(t, err)
+
+	found, err := SessionUser(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	require.NoError(t, EndSession(token))
+	_, err = SessionUser(token)
+	assert.Error(t, err)
+}
+
+func TestCreateFirstAdmin_OnlyOnce(t *testing.T) {
+	setupTestDB(t)
+
+	admin, err := CreateFirstAdmin("admin@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, admin.Role)
+
+	_, err = CreateFirstAdmin("other@example.com", "correct horse")
+	assert.Error(t, err)
+}
+
+func TestRoleScope(t *testing.T) {
+	assert.Equal(t, models.ScopeRead, RoleScope(models.RoleViewer))
+	assert.Equal(t, models.ScopeWrite, RoleScope(models.RoleEditor))
+	assert.Equal(t, models.ScopeAdmin, RoleScope(models.RoleAdmin))
+	assert.Equal(t, "", RoleScope("owner"))
+}
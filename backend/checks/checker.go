@@ -0,0 +1,127 @@
+package checks
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Checker checks one type of monitor
+type Checker interface {
+	// Validate rejects a monitor config the checker can't run, before it is saved
+	Validate(monitor models.MonitorEntry) error
+	Check(monitor models.MonitorEntry) models.MonitorResult
+}
+
+// Every monitor type the API accepts
+var checkers = map[string]Checker{
+	models.MonitorHTTP:      HTTPChecker{},
+	models.MonitorTCP:       TCPChecker{},
+	models.MonitorDNS:       DNSChecker{},
+	models.MonitorGRPC:      GRPCChecker{},
+	models.MonitorHeartbeat: HeartbeatChecker{},
+}
+
+/*
+Function to find the checker for a monitor. Monitors saved before types existed
+have no type and are http.
+*/
+func For(monitor models.MonitorEntry) (Checker, error) {
+	monitorType := monitor.Type
+	if monitorType == "" {
+		monitorType = models.MonitorHTTP
+	}
+
+	checker, ok := checkers[monitorType]
+	if !ok {
+		return nil, fmt.Errorf("unknown monitor type %q", monitor.Type)
+	}
+	return checker, nil
+}
+
+/*
+Function to validate a monitor with the checker for its type
+*/
+func Validate(monitor models.MonitorEntry) error {
+	checker, err := For(monitor)
+	if err != nil {
+		return err
+	}
+
+	err = validateRetries(monitor)
+	if err != nil {
+		return err
+	}
+
+	if monitor.Timeout < 0 || monitor.Timeout > maxCheckTimeout {
+		return fmt.Errorf("timeout must be between 0 and %d seconds", maxCheckTimeout)
+	}
+	return checker.Validate(monitor)
+}
+
+/*
+Function to check a monitor with the checker for its type, retrying failures
+as the monitor's retry settings allow
+*/
+func Run(monitor models.MonitorEntry) models.MonitorResult {
+	if monitor.ConfigError != "" {
+		return models.MonitorResult{Timestamp: time.Now(), Error: monitor.ConfigError, FailureReason: models.FailureConfigError}
+	}
+
+	checker, err := For(monitor)
+	if err != nil {
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+	return runWithRetries(checker, monitor, time.Sleep)
+}
+
+/*
+Function to check a monitor once, without its retries or confirmation, for checks
+someone is waiting on, like a manual check over the API
+*/
+func RunOnce(monitor models.MonitorEntry) models.MonitorResult {
+	if monitor.ConfigError != "" {
+		return models.MonitorResult{Timestamp: time.Now(), Error: monitor.ConfigError, FailureReason: models.FailureConfigError}
+	}
+
+	checker, err := For(monitor)
+	if err != nil {
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+	return checker.Check(monitor)
+}
+
+// Longest a monitor may set for one attempt, in seconds
+const maxCheckTimeout = 60
+
+// checkTimeout is how long one attempt may take: the monitor's timeout, or its type's default
+func checkTimeout(monitor models.MonitorEntry, fallback time.Duration) time.Duration {
+	if monitor.Timeout > 0 {
+		return time.Duration(monitor.Timeout) * time.Second
+	}
+	return fallback
+}
+
+// sendsHTTPRequest reports whether a monitor has settings only an http check can use
+func sendsHTTPRequest(monitor models.MonitorEntry) bool {
+	return monitor.Method != "" || len(monitor.Headers) > 0 || monitor.ContentType != "" || monitor.Auth != nil ||
+		monitor.FollowRedirects != nil || monitor.MaxRedirects != 0 || monitor.IgnoreTLSErrors || monitor.MaxBodyBytes != 0
+}
+
+// hostPort splits a scheme://host:port monitor URL, as used by tcp and grpc monitors
+func hostPort(rawURL string, scheme string) (string, string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Scheme != scheme || parsedURL.Hostname() == "" {
+		return "", "", fmt.Errorf("%s monitors need a %s://host:port url", scheme, scheme)
+	}
+
+	port, err := strconv.Atoi(parsedURL.Port())
+	if err != nil || port < 1 || port > 65535 {
+		return "", "", fmt.Errorf("%s monitors need a port between 1 and 65535", scheme)
+	}
+
+	return parsedURL.Hostname(), parsedURL.Port(), nil
+}
@@ -0,0 +1,423 @@
+package checks
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"golang.org/x/net/dns/dnsmessage"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// How long a dns check may wait for its answer, retries over TCP included
+const dnsTimeout = 5 * time.Second
+
+// Read for the resolver when a dns monitor doesn't name its own
+var resolvConfPath = "/etc/resolv.conf"
+
+// Used when resolv.conf has no nameserver either
+const fallbackResolver = "127.0.0.1:53"
+
+// Record types a dns monitor can query
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	models.RecordA:     dnsmessage.TypeA,
+	models.RecordAAAA:  dnsmessage.TypeAAAA,
+	models.RecordCNAME: dnsmessage.TypeCNAME,
+	models.RecordMX:    dnsmessage.TypeMX,
+	models.RecordTXT:   dnsmessage.TypeTXT,
+	models.RecordNS:    dnsmessage.TypeNS,
+}
+
+// DNSChecker resolves a name and checks the records that come back
+type DNSChecker struct{}
+
+/*
+Function to check a dns monitor's name, record type and resolver before it is saved
+*/
+func (DNSChecker) Validate(monitor models.MonitorEntry) error {
+	_, err := dnsName(monitor.URL)
+	if err != nil {
+		return err
+	}
+
+	if sendsHTTPRequest(monitor) || monitor.Body != "" || len(monitor.Assertions) > 0 {
+		return fmt.Errorf("dns monitors check records with dns.expected or dns.contains, not request settings or assertions")
+	}
+
+	if monitor.DNS == nil {
+		return fmt.Errorf("dns monitors need a dns config with a record_type")
+	}
+
+	if _, ok := dnsRecordTypes[monitor.DNS.RecordType]; !ok {
+		return fmt.Errorf("record_type must be A, AAAA, CNAME, MX, TXT or NS")
+	}
+
+	if monitor.DNS.Resolver != "" {
+		_, err = resolverAddress(monitor.DNS.Resolver)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (DNSChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	/*
+		This function queries the resolver for the monitor's name and record type, then
+		checks the answer set against the expected records. Without an expected list
+		it fails the check whenever the answer set differs from the last one accepted,
+		so a hijacked or mis-deployed record opens an incident. A new answer set is
+		accepted once it has failed as many checks in a row as opening an incident
+		takes, so the change alerts once and round-robin answers don't flap.
+	*/
+	span := tracer.StartSpan("dns.check",
+		tracer.ResourceName(monitor.URL),
+		tracer.SpanType("dns"),
+	)
+	defer span.Finish()
+
+	config := models.DNSConfig{RecordType: models.RecordA}
+	if monitor.DNS != nil {
+		config = *monitor.DNS
+	}
+
+	startTime := time.Now()
+	records, err := lookupRecords(monitor.URL, config, startTime.Add(checkTimeout(monitor, dnsTimeout)))
+	responseTime := time.Since(startTime)
+
+	result := models.MonitorResult{
+		Timestamp:    time.Now(),
+		ResponseTime: responseTime,
+		IsUp:         true,
+		Records:      records,
+		Timings:      &models.CheckTimings{DNSLookup: responseTime},
+	}
+
+	if err == nil {
+		result.FailureReason, result.Warning, err = compareRecords(monitor, config, records)
+	}
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		result.IsUp = false
+		result.Error = err.Error()
+	}
+
+	if result.FailureReason != "" {
+		span.SetTag("check.failure_reason", result.FailureReason)
+	}
+
+	return result
+}
+
+// compareRecords checks an answer set against the monitor's expectations, or
+// against the last accepted answer set when it has no expected list. It returns
+// the failure reason, a warning for an accepted change, and the failure.
+func compareRecords(monitor models.MonitorEntry, config models.DNSConfig, records []string) (string, string, error) {
+	if len(config.Expected) > 0 {
+		expected := make([]string, len(config.Expected))
+		for i, record := range config.Expected {
+			expected[i] = normalizeRecord(config.RecordType, record)
+		}
+		slices.Sort(expected)
+
+		if !slices.Equal(expected, records) {
+			return models.FailureRecordsMismatch, "", fmt.Errorf("expected records %s, got %s", formatRecords(expected), formatRecords(records))
+		}
+	}
+
+	if config.Contains != "" && !slices.Contains(records, normalizeRecord(config.RecordType, config.Contains)) {
+		return models.FailureRecordsMismatch, "", fmt.Errorf("records %s do not include %s", formatRecords(records), config.Contains)
+	}
+
+	// An expected list already pins the answer set, and unsaved monitors have no history
+	if len(config.Expected) > 0 || monitor.ID == "" {
+		return "", "", nil
+	}
+
+	accepted, err := db.GetAcceptedRecords(monitor.ID)
+	if err != nil {
+		log.Printf("Could not compare records for monitor %s: %v", monitor.ID, err)
+		return "", "", nil
+	}
+
+	if accepted == nil || slices.Equal(accepted, records) {
+		return "", "", nil
+	}
+
+	changed := fmt.Sprintf("records changed from %s to %s", formatRecords(accepted), formatRecords(records))
+
+	confirmed, err := changeConfirmed(monitor, records)
+	if err != nil {
+		log.Printf("Could not confirm record change for monitor %s: %v", monitor.ID, err)
+	}
+	if confirmed {
+		return "", changed, nil
+	}
+
+	return models.FailureRecordsChanged, "", errors.New(changed)
+}
+
+// changeConfirmed reports whether the checks just before this one all failed on
+// the same new answer set, enough of them in a row to have opened an incident
+func changeConfirmed(monitor models.MonitorEntry, records []string) (bool, error) {
+	threshold := max(monitor.FailureThreshold, 1)
+
+	recent, err := db.GetRecentRecords(monitor.ID, threshold)
+	if err != nil || len(recent) < threshold {
+		return false, err
+	}
+
+	for _, result := range recent {
+		if result.FailureReason != models.FailureRecordsChanged || !slices.Equal(result.Records, records) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func lookupRecords(rawURL string, config models.DNSConfig, deadline time.Time) ([]string, error) {
+	name, err := dnsName(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := config.Resolver
+	if resolver == "" {
+		resolver = systemResolver()
+	}
+
+	address, err := resolverAddress(resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	recordType, ok := dnsRecordTypes[config.RecordType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %q", config.RecordType)
+	}
+
+	response, err := queryDNS(address, name, recordType, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, fmt.Errorf("%s does not exist (NXDOMAIN)", strings.TrimSuffix(name, "."))
+	default:
+		return nil, fmt.Errorf("resolver answered %s", response.RCode)
+	}
+
+	// Only the requested type: an A query may also return the CNAME chain leading to it
+	records := []string{}
+	for _, answer := range response.Answers {
+		if answer.Header.Type != recordType {
+			continue
+		}
+		records = append(records, formatRecord(answer.Body))
+	}
+	slices.Sort(records)
+
+	return records, nil
+}
+
+// queryDNS sends one query over UDP, retrying over TCP when the answer was truncated
+func queryDNS(address string, name string, recordType dnsmessage.Type, deadline time.Time) (*dnsmessage.Message, error) {
+	question, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %s: %w", name, err)
+	}
+
+	id := uint16(rand.Uint32())
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: question, Type: recordType, Class: dnsmessage.ClassINET}},
+	}
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("error building query: %w", err)
+	}
+
+	response, err := exchangeDNS("udp", address, packed, deadline)
+	if err == nil && response.Truncated {
+		response, err = exchangeDNS("tcp", address, packed, deadline)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying %s: %w", address, err)
+	}
+
+	if response.ID != id {
+		return nil, fmt.Errorf("error querying %s: answer does not match the query", address)
+	}
+
+	return response, nil
+}
+
+func exchangeDNS(network string, address string, packed []byte, deadline time.Time) (*dnsmessage.Message, error) {
+	conn, err := net.DialTimeout(network, address, time.Until(deadline))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(deadline)
+
+	var answer []byte
+
+	if network == "tcp" {
+		// DNS over TCP prefixes each message with its length
+		_, err = conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(packed))))
+		if err == nil {
+			_, err = conn.Write(packed)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var length uint16
+		err = binary.Read(conn, binary.BigEndian, &length)
+		if err != nil {
+			return nil, err
+		}
+
+		answer = make([]byte, length)
+		_, err = io.ReadFull(conn, answer)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, err = conn.Write(packed)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer := make([]byte, 65535)
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, err
+		}
+		answer = buffer[:n]
+	}
+
+	var response dnsmessage.Message
+	err = response.Unpack(answer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid answer: %w", err)
+	}
+
+	return &response, nil
+}
+
+// formatRecord writes a record the way it is stored and compared
+func formatRecord(body dnsmessage.ResourceBody) string {
+	switch record := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(record.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(record.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		return normalizeName(record.CNAME.String())
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", record.Pref, normalizeName(record.MX.String()))
+	case *dnsmessage.NSResource:
+		return normalizeName(record.NS.String())
+	case *dnsmessage.TXTResource:
+		return strings.Join(record.TXT, "")
+	default:
+		return body.GoString()
+	}
+}
+
+// normalizeRecord writes an expected value the same way formatRecord writes answers
+func normalizeRecord(recordType string, value string) string {
+	value = strings.TrimSpace(value)
+
+	switch recordType {
+	case models.RecordA, models.RecordAAAA:
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	case models.RecordCNAME, models.RecordNS:
+		return normalizeName(value)
+	case models.RecordMX:
+		preference, host, found := strings.Cut(value, " ")
+		if found {
+			return preference + " " + normalizeName(strings.TrimSpace(host))
+		}
+	}
+	return value
+}
+
+// normalizeName lowercases a domain name and drops the root dot
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func formatRecords(records []string) string {
+	return "[" + strings.Join(records, ", ") + "]"
+}
+
+// dnsName turns a dns://example.com monitor URL into the fully qualified name to query
+func dnsName(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Scheme != models.MonitorDNS || parsedURL.Host == "" || parsedURL.Port() != "" {
+		return "", fmt.Errorf("dns monitors need a dns://name url")
+	}
+
+	name := parsedURL.Host
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name, nil
+}
+
+// resolverAddress adds the default DNS port to a resolver given without one
+func resolverAddress(resolver string) (string, error) {
+	host, port, err := net.SplitHostPort(resolver)
+	if err != nil {
+		host, port = strings.Trim(resolver, "[]"), "53"
+	}
+
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		return "", fmt.Errorf("resolver must be a host or host:port")
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// systemResolver is the first nameserver in resolv.conf
+func systemResolver() string {
+	file, err := os.Open(resolvConfPath)
+	if err != nil {
+		return fallbackResolver
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+
+	return fallbackResolver
+}
@@ -0,0 +1,253 @@
+package checks
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// testDNSServer answers UDP queries from a table of records that tests can change
+type testDNSServer struct {
+	address string
+	mu      sync.Mutex
+	records map[dnsmessage.Type][]dnsmessage.ResourceBody // <- For example.com only
+}
+
+func newDNSServer(t *testing.T) *testDNSServer {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	server := &testDNSServer{
+		address: conn.LocalAddr().String(),
+		records: map[dnsmessage.Type][]dnsmessage.ResourceBody{},
+	}
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			answer, err := server.answer(buffer[:n])
+			if err == nil {
+				conn.WriteTo(answer, from)
+			}
+		}
+	}()
+
+	return server
+}
+
+func (s *testDNSServer) set(recordType dnsmessage.Type, bodies ...dnsmessage.ResourceBody) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[recordType] = bodies
+}
+
+func (s *testDNSServer) answer(packet []byte) ([]byte, error) {
+	var query dnsmessage.Message
+	err := query.Unpack(packet)
+	if err != nil || len(query.Questions) != 1 {
+		return nil, err
+	}
+
+	question := query.Questions[0]
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+
+	if question.Name.String() != "example.com." {
+		response.RCode = dnsmessage.RCodeNameError
+		return response.Pack()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, body := range s.records[question.Type] {
+		response.Answers = append(response.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   body,
+		})
+	}
+	return response.Pack()
+}
+
+func dnsMonitor(server *testDNSServer, config models.DNSConfig) models.MonitorEntry {
+	config.Resolver = server.address
+	return models.MonitorEntry{Type: models.MonitorDNS, URL: "dns://example.com", DNS: &config}
+}
+
+func TestDNSChecker_RecordTypes(t *testing.T) {
+	server := newDNSServer(t)
+	server.set(dnsmessage.TypeA,
+		&dnsmessage.AResource{A: [4]byte{192, 0, 2, 20}},
+		&dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}},
+	)
+	server.set(dnsmessage.TypeAAAA, &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}})
+	server.set(dnsmessage.TypeCNAME, &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("Edge.Example.NET.")})
+	server.set(dnsmessage.TypeMX, &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.com.")})
+	server.set(dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}})
+	server.set(dnsmessage.TypeNS, &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns1.example.com.")})
+
+	cases := map[string][]string{
+		models.RecordA:     {"192.0.2.10", "192.0.2.20"},
+		models.RecordAAAA:  {"2001:db8::1"},
+		models.RecordCNAME: {"edge.example.net"},
+		models.RecordMX:    {"10 mail.example.com"},
+		models.RecordTXT:   {"v=spf1 -all"},
+		models.RecordNS:    {"ns1.example.com"},
+	}
+
+	for recordType, expected := range cases {
+		t.Run(recordType, func(t *testing.T) {
+			monitor := dnsMonitor(server, models.DNSConfig{RecordType: recordType})
+			require.NoError(t, Validate(monitor))
+
+			result := Run(monitor)
+
+			assert.True(t, result.IsUp, result.Error)
+			assert.Equal(t, expected, result.Records)
+			assert.Greater(t, result.Timings.DNSLookup, time.Duration(0))
+		})
+	}
+}
+
+func TestDNSChecker_ExpectedAndContains(t *testing.T) {
+	server := newDNSServer(t)
+	server.set(dnsmessage.TypeA,
+		&dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}},
+		&dnsmessage.AResource{A: [4]byte{192, 0, 2, 20}},
+	)
+
+	result := Run(dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA, Expected: []string{"192.0.2.20", "192.0.2.10"}}))
+	assert.True(t, result.IsUp, result.Error)
+
+	result = Run(dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA, Expected: []string{"192.0.2.10"}}))
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailureRecordsMismatch, result.FailureReason)
+	assert.Equal(t, "expected records [192.0.2.10], got [192.0.2.10, 192.0.2.20]", result.Error)
+
+	result = Run(dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA, Contains: "192.0.2.20"}))
+	assert.True(t, result.IsUp, result.Error)
+
+	result = Run(dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA, Contains: "198.51.100.7"}))
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailureRecordsMismatch, result.FailureReason)
+
+	// Names that don't exist fail without records
+	monitor := dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA})
+	monitor.URL = "dns://missing.example.com"
+	result = Run(monitor)
+	assert.False(t, result.IsUp)
+	assert.Equal(t, "missing.example.com does not exist (NXDOMAIN)", result.Error)
+	assert.Nil(t, result.Records)
+}
+
+func TestDNSChecker_FailsWhenRecordsChange(t *testing.T) {
+	_, err := db.GetDB().Exec(`DELETE FROM results`)
+	require.NoError(t, err)
+
+	server := newDNSServer(t)
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}})
+
+	monitor := dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA})
+	monitor.ID = "dns-monitor"
+
+	check := func() models.MonitorResult {
+		result := Run(monitor)
+		require.NoError(t, db.SaveResult(monitor.ID, result))
+		return result
+	}
+
+	assert.True(t, check().IsUp, "the first answer becomes the baseline")
+	assert.True(t, check().IsUp)
+
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{203, 0, 113, 66}})
+
+	result := check()
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailureRecordsChanged, result.FailureReason)
+	assert.Equal(t, "records changed from [192.0.2.10] to [203.0.113.66]", result.Error)
+
+	stored, err := db.GetLatestResult(monitor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"203.0.113.66"}, stored.Records)
+
+	// The change has alerted once, so the changed answer becomes the new baseline
+	result = check()
+	assert.True(t, result.IsUp)
+	assert.Equal(t, "records changed from [192.0.2.10] to [203.0.113.66]", result.Warning)
+	assert.True(t, check().IsUp)
+	assert.Empty(t, check().Warning)
+}
+
+func TestDNSChecker_RecordChangesWaitForTheFailureThreshold(t *testing.T) {
+	_, err := db.GetDB().Exec(`DELETE FROM results`)
+	require.NoError(t, err)
+
+	server := newDNSServer(t)
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}})
+
+	monitor := dnsMonitor(server, models.DNSConfig{RecordType: models.RecordA})
+	monitor.ID = "dns-monitor"
+	monitor.FailureThreshold = 3
+
+	check := func() models.MonitorResult {
+		result := Run(monitor)
+		require.NoError(t, db.SaveResult(monitor.ID, result))
+		return result
+	}
+
+	assert.True(t, check().IsUp)
+
+	// Round-robin answers only fail the checks that differ from the baseline
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 11}})
+	assert.False(t, check().IsUp)
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}})
+	assert.True(t, check().IsUp)
+
+	// A real change fails enough checks in a row to open an incident before it is accepted
+	server.set(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{203, 0, 113, 66}})
+	for i := 0; i < 3; i++ {
+		result := check()
+		assert.False(t, result.IsUp)
+		assert.Equal(t, "records changed from [192.0.2.10] to [203.0.113.66]", result.Error)
+	}
+	assert.True(t, check().IsUp)
+	assert.True(t, check().IsUp)
+}
+
+func TestDNSChecker_Validate(t *testing.T) {
+	valid := models.MonitorEntry{Type: models.MonitorDNS, URL: "dns://example.com", DNS: &models.DNSConfig{RecordType: models.RecordMX, Resolver: "1.1.1.1"}}
+	assert.NoError(t, Validate(valid))
+
+	missingConfig := valid
+	missingConfig.DNS = nil
+	assert.Error(t, Validate(missingConfig))
+
+	badType := valid
+	badType.DNS = &models.DNSConfig{RecordType: "SRV"}
+	assert.Error(t, Validate(badType))
+
+	badURL := valid
+	badURL.URL = "https://example.com"
+	assert.Error(t, Validate(badURL))
+
+	withAssertions := valid
+	withAssertions.Assertions = []models.Assertion{{Type: models.AssertBodyContains, Value: "x"}}
+	assert.Error(t, Validate(withAssertions))
+}
@@ -0,0 +1,127 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	grpctrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/google.golang.org/grpc"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// How long a grpc check may take, connecting included
+const grpcTimeout = 10 * time.Second
+
+// GRPCChecker calls the standard grpc.health.v1.Health/Check and expects SERVING
+type GRPCChecker struct{}
+
+/*
+Function to check a grpc monitor's address before it is saved
+*/
+func (GRPCChecker) Validate(monitor models.MonitorEntry) error {
+	_, _, err := hostPort(monitor.URL, models.MonitorGRPC)
+	if err != nil {
+		return err
+	}
+
+	if sendsHTTPRequest(monitor) || monitor.Body != "" || len(monitor.Assertions) > 0 {
+		return fmt.Errorf("grpc monitors only take a service name and tls, not request settings or assertions")
+	}
+
+	if monitor.CertWarningDays < 0 {
+		return fmt.Errorf("cert_warning_days must not be negative")
+	}
+
+	return nil
+}
+
+func (GRPCChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	/*
+		This function dials the target (over TLS when configured, verifying the
+		certificate like an https check does), asks the health service about the
+		monitor's service and records the latency and the status it returned
+	*/
+	span := tracer.StartSpan("grpc.check",
+		tracer.ResourceName(monitor.URL),
+		tracer.SpanType("grpc"),
+	)
+	defer span.Finish()
+
+	config := models.GRPCConfig{}
+	if monitor.GRPC != nil {
+		config = *monitor.GRPC
+	}
+
+	host, port, err := hostPort(monitor.URL, models.MonitorGRPC)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+
+	transport := insecure.NewCredentials()
+
+	var certs *certChecker
+	if config.TLS {
+		certs = newCertChecker(host)
+		transport = credentials.NewTLS(certs.tlsConfig())
+	}
+
+	conn, err := grpc.NewClient(net.JoinHostPort(host, port),
+		grpc.WithTransportCredentials(transport),
+		grpc.WithUnaryInterceptor(grpctrace.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(tracer.ContextWithSpan(context.Background(), span), checkTimeout(monitor, grpcTimeout))
+	defer cancel()
+
+	startTime := time.Now()
+
+	// The client connects lazily, so the latency includes the dial and handshake
+	response, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{
+		Service: config.Service,
+	})
+
+	result := models.MonitorResult{
+		Timestamp:    time.Now(),
+		ResponseTime: time.Since(startTime),
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.GRPCStatus = response.GetStatus().String()
+		result.IsUp = response.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+		if !result.IsUp {
+			result.Error = "health status is " + result.GRPCStatus
+		}
+	}
+
+	if certs != nil {
+		result.Certificate, result.FailureReason = certs.result()
+		if result.FailureReason != "" {
+			span.SetTag("check.failure_reason", result.FailureReason)
+		} else if result.IsUp {
+			result.Warning = certWarning(monitor, result.Certificate)
+		}
+	}
+
+	if !result.IsUp {
+		span.SetTag("error", true)
+		span.SetTag("error.message", result.Error)
+	}
+
+	return result
+}
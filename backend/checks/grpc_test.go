@@ -0,0 +1,108 @@
+package checks
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// newHealthServer serves the standard health service on localhost, over TLS when
+// given a certificate, and returns its grpc:// URL
+func newHealthServer(t *testing.T, certificate *tls.Certificate) (string, *health.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var options []grpc.ServerOption
+	if certificate != nil {
+		options = append(options, grpc.Creds(credentials.NewServerTLSFromCert(certificate)))
+	}
+
+	server := grpc.NewServer(options...)
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return "grpc://" + listener.Addr().String(), healthServer
+}
+
+func TestGRPCChecker_HealthStatus(t *testing.T) {
+	url, healthServer := newHealthServer(t, nil)
+	healthServer.SetServingStatus("payments.v1.Payments", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	result := Run(models.MonitorEntry{Type: models.MonitorGRPC, URL: url})
+
+	assert.True(t, result.IsUp, result.Error)
+	assert.Equal(t, "SERVING", result.GRPCStatus)
+	assert.Greater(t, result.ResponseTime, time.Duration(0))
+
+	monitor := models.MonitorEntry{
+		Type: models.MonitorGRPC,
+		URL:  url,
+		GRPC: &models.GRPCConfig{Service: "payments.v1.Payments"},
+	}
+	result = Run(monitor)
+
+	assert.False(t, result.IsUp)
+	assert.Equal(t, "NOT_SERVING", result.GRPCStatus)
+	assert.Equal(t, "health status is NOT_SERVING", result.Error)
+
+	healthServer.SetServingStatus("payments.v1.Payments", grpc_health_v1.HealthCheckResponse_SERVING)
+	assert.True(t, Run(monitor).IsUp)
+
+	// A service the server doesn't know about is an RPC error, not a status
+	monitor.GRPC.Service = "missing.v1.Missing"
+	result = Run(monitor)
+
+	assert.False(t, result.IsUp)
+	assert.Empty(t, result.GRPCStatus)
+	assert.Contains(t, result.Error, "NotFound")
+}
+
+func TestGRPCChecker_TLS(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.leaf(t, 10*24*time.Hour+time.Hour, localhostIP, nil)
+	certificate := tls.Certificate{
+		Certificate: [][]byte{leaf.cert.Raw, pki.intermediate.cert.Raw},
+		PrivateKey:  leaf.key,
+	}
+
+	url, _ := newHealthServer(t, &certificate)
+
+	result := Run(models.MonitorEntry{Type: models.MonitorGRPC, URL: url, GRPC: &models.GRPCConfig{TLS: true}})
+
+	assert.True(t, result.IsUp, result.Error)
+	require.NotNil(t, result.Certificate)
+	assert.Equal(t, 10, result.Certificate.DaysRemaining)
+	assert.Equal(t, "certificate expires in 10 days", result.Warning)
+
+	// Plaintext against a TLS server fails without a status
+	result = Run(models.MonitorEntry{Type: models.MonitorGRPC, URL: url})
+
+	assert.False(t, result.IsUp)
+	assert.Empty(t, result.GRPCStatus)
+}
+
+func TestGRPCChecker_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	result := Run(models.MonitorEntry{Type: models.MonitorGRPC, URL: "grpc://" + address})
+
+	assert.False(t, result.IsUp)
+	assert.Contains(t, result.Error, "Unavailable")
+}
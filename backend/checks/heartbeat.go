@@ -0,0 +1,87 @@
+package checks
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// HeartbeatChecker judges a heartbeat monitor by its pings instead of polling it.
+// Its results come from /ping/{token}; the worker only records the missed ones.
+type HeartbeatChecker struct{}
+
+/*
+Function to check a heartbeat monitor's period and grace before it is saved
+*/
+func (HeartbeatChecker) Validate(monitor models.MonitorEntry) error {
+	parsedURL, err := url.Parse(monitor.URL)
+	if err != nil || parsedURL.Scheme != models.MonitorHeartbeat {
+		return fmt.Errorf("heartbeat monitors need a heartbeat://name url")
+	}
+
+	if sendsHTTPRequest(monitor) || monitor.Body != "" || len(monitor.Assertions) > 0 {
+		return fmt.Errorf("heartbeat monitors are pinged, so they take no request settings or assertions")
+	}
+
+	if monitor.Retries > 0 || monitor.ConfirmFailures || monitor.Timeout > 0 {
+		return fmt.Errorf("heartbeat monitors aren't polled, so they take no timeout and can't retry or confirm failures")
+	}
+
+	if monitor.Heartbeat == nil || monitor.Heartbeat.Period < 1 {
+		return fmt.Errorf("heartbeat monitors need a heartbeat config with a period of at least 1 second")
+	}
+
+	if monitor.Heartbeat.Grace < 0 {
+		return fmt.Errorf("grace must not be negative")
+	}
+
+	return nil
+}
+
+func (HeartbeatChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	result, overdue, err := MissedPing(monitor, time.Now())
+	if err != nil {
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+	if !overdue {
+		return models.MonitorResult{Timestamp: time.Now(), IsUp: true}
+	}
+	return result
+}
+
+/*
+Function to work out whether a heartbeat monitor's ping is overdue. The next ping
+is due period + grace after the monitor's newest result (a ping, or an earlier
+missed ping), or after it was created when there are none yet. Returns the down
+result to record when it is overdue.
+*/
+func MissedPing(monitor models.MonitorEntry, now time.Time) (models.MonitorResult, bool, error) {
+	if monitor.Heartbeat == nil {
+		return models.MonitorResult{}, false, fmt.Errorf("monitor %s has no heartbeat config", monitor.ID)
+	}
+
+	latest, err := db.GetResults(monitor.ID, db.ResultFilter{Limit: 1})
+	if err != nil {
+		return models.MonitorResult{}, false, err
+	}
+
+	since := monitor.CreatedAt
+	if len(latest) > 0 {
+		since = latest[0].Timestamp
+	}
+
+	window := time.Duration(monitor.Heartbeat.Period+monitor.Heartbeat.Grace) * time.Second
+	if now.Before(since.Add(window)) {
+		return models.MonitorResult{}, false, nil
+	}
+
+	return models.MonitorResult{
+		Timestamp:     now,
+		IsUp:          false,
+		Error:         fmt.Sprintf("no ping received since %s", since.UTC().Format(time.RFC3339)),
+		FailureReason: models.FailurePingOverdue,
+	}, true, nil
+}
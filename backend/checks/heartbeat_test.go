@@ -0,0 +1,58 @@
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatChecker_Validate(t *testing.T) {
+	valid := models.MonitorEntry{
+		Type:      models.MonitorHeartbeat,
+		URL:       "heartbeat://nightly-backup",
+		Heartbeat: &models.HeartbeatConfig{Period: 3600, Grace: 300},
+	}
+	assert.NoError(t, Validate(valid))
+
+	tests := []struct {
+		name   string
+		change func(monitor *models.MonitorEntry)
+	}{
+		{"http url", func(m *models.MonitorEntry) { m.URL = "https://example.com" }},
+		{"no config", func(m *models.MonitorEntry) { m.Heartbeat = nil }},
+		{"zero period", func(m *models.MonitorEntry) { m.Heartbeat = &models.HeartbeatConfig{} }},
+		{"negative grace", func(m *models.MonitorEntry) { m.Heartbeat = &models.HeartbeatConfig{Period: 60, Grace: -1} }},
+		{"request method", func(m *models.MonitorEntry) { m.Method = "POST" }},
+		{"retries", func(m *models.MonitorEntry) { m.Retries = 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := valid
+			tt.change(&monitor)
+			assert.Error(t, Validate(monitor))
+		})
+	}
+}
+
+func TestMissedPing_Window(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	monitor := models.MonitorEntry{
+		ID:        "no-results",
+		Type:      models.MonitorHeartbeat,
+		Heartbeat: &models.HeartbeatConfig{Period: 3000, Grace: 600},
+		CreatedAt: created,
+	}
+
+	_, overdue, err := MissedPing(monitor, created.Add(3599*time.Second))
+	assert.NoError(t, err)
+	assert.False(t, overdue, "still inside period + grace")
+
+	result, overdue, err := MissedPing(monitor, created.Add(3600*time.Second))
+	assert.NoError(t, err)
+	assert.True(t, overdue)
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailurePingOverdue, result.FailureReason)
+}
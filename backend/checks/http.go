@@ -0,0 +1,336 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nethttptrace "net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/assertions"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Methods a monitor may send its check with
+var checkMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// HTTPChecker sends the monitor's request and judges the response
+type HTTPChecker struct{}
+
+/*
+Function to check the URL, method, headers, credentials and assertions of an
+http monitor before it is saved
+*/
+func (HTTPChecker) Validate(monitor models.MonitorEntry) error {
+	parsedURL, err := url.Parse(monitor.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return fmt.Errorf("http monitors need an http or https url")
+	}
+
+	err = assertions.Validate(monitor.Assertions)
+	if err != nil {
+		return err
+	}
+
+	if monitor.Method != "" && !checkMethods[monitor.Method] {
+		return fmt.Errorf("method %s is not supported", monitor.Method)
+	}
+
+	for name := range monitor.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+
+	if monitor.CertWarningDays < 0 {
+		return fmt.Errorf("cert_warning_days must not be negative")
+	}
+
+	if monitor.MaxRedirects < 0 || monitor.MaxRedirects > maxAllowedRedirects {
+		return fmt.Errorf("max_redirects must be between 0 and %d", maxAllowedRedirects)
+	}
+
+	if monitor.MaxBodyBytes < 0 || monitor.MaxBodyBytes > maxAllowedBody {
+		return fmt.Errorf("max_body_bytes must be between 0 and %d", maxAllowedBody)
+	}
+
+	if monitor.Auth == nil {
+		return nil
+	}
+
+	switch monitor.Auth.Type {
+	case models.AuthBasic:
+		if monitor.Auth.Username == "" {
+			return fmt.Errorf("basic auth requires a username")
+		}
+	case models.AuthBearer:
+		if monitor.Auth.Token == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	default:
+		return fmt.Errorf("auth type must be basic or bearer")
+	}
+
+	return nil
+}
+
+func (HTTPChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	return PerformCheck(monitor)
+}
+
+func PerformCheck(monitor models.MonitorEntry) models.MonitorResult {
+	/*
+		This function creates an HTTP client with the monitor's timeout and redirect
+		policy, builds the monitor's request (method, headers, body, credentials),
+		checks duration and the time spent in each network phase, verifies the TLS
+		certificate for https, reads the body up to the monitor's cap, runs the
+		monitor's assertions and returns the result
+	*/
+	targetURL := monitor.URL
+
+	method := monitor.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	span := tracer.StartSpan("http.check",
+		tracer.ResourceName(method+" "+targetURL),
+		tracer.SpanType("http"),
+	)
+	defer span.Finish()
+
+	// A fresh connection every check, so DNS, connect and TLS are measured each time
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+
+	checkRequest, err := buildCheckRequest(monitor, method)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorResult{Timestamp: time.Now(), Error: err.Error()}
+	}
+
+	certs := newCertChecker(checkRequest.URL.Hostname())
+	certs.ignoreErrors = monitor.IgnoreTLSErrors
+	transport.TLSClientConfig = certs.tlsConfig()
+
+	redirects := &redirectRecorder{follow: followsRedirects(monitor), max: maxRedirects(monitor)}
+
+	client := httptrace.WrapClient(&http.Client{
+		Timeout:       checkTimeout(monitor, httpTimeout),
+		Transport:     transport,
+		CheckRedirect: redirects.check,
+	})
+
+	timer := &checkTimer{}
+	checkRequest = checkRequest.WithContext(nethttptrace.WithClientTrace(checkRequest.Context(), timer.trace()))
+
+	startTime := time.Now()
+
+	resp, err := client.Do(checkRequest)
+
+	responseTime := time.Since(startTime)
+
+	result := models.MonitorResult{
+		Timestamp:     time.Now(),
+		ResponseTime:  responseTime,
+		RedirectChain: redirects.hops,
+	}
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		result.IsUp = false
+		result.Error = err.Error()
+		result.StatusCode = 0
+		if errors.Is(err, errTooManyRedirects) {
+			result.FailureReason = models.FailureTooManyRedirects
+		}
+	} else {
+		defer resp.Body.Close()
+		result.IsUp = resp.StatusCode >= 200 && resp.StatusCode < 300
+		result.StatusCode = resp.StatusCode
+		result.Error = ""
+		if len(redirects.hops) > 0 {
+			result.FinalURL = resp.Request.URL.String()
+		}
+
+		// Reading the body measures the content transfer and feeds the assertions
+		body, readErr := readCheckBody(monitor, resp.Body)
+		timer.finishBody()
+
+		if errors.Is(readErr, errBodyTooLarge) {
+			result.IsUp = false
+			result.Error = readErr.Error()
+			result.FailureReason = models.FailureBodyTooLarge
+		} else if readErr != nil {
+			result.IsUp = false
+			result.Error = "error reading response body: " + readErr.Error()
+		} else if len(monitor.Assertions) > 0 {
+			checkAssertions(span, monitor.Assertions, resp, body, &result)
+		}
+	}
+
+	result.Timings = timer.timings()
+
+	// https only: what certificate was presented and whether it is close to expiry
+	var certFailure string
+	result.Certificate, certFailure = certs.result()
+	if certFailure != "" {
+		result.FailureReason = certFailure
+	}
+
+	if result.FailureReason != "" {
+		span.SetTag("check.failure_reason", result.FailureReason)
+	} else if problem := certs.ignoredProblem(); problem != "" && result.IsUp {
+		result.Warning = "ignored certificate problem: " + problem
+	} else if result.IsUp {
+		result.Warning = certWarning(monitor, result.Certificate)
+	}
+
+	return result
+}
+
+// How long an http check may take when the monitor doesn't set a timeout
+const httpTimeout = 10 * time.Second
+
+// Largest response body read per check, unless the monitor sets max_body_bytes
+const maxCheckBody = 1 << 20
+
+// Most redirects followed, unless the monitor sets max_redirects
+const defaultMaxRedirects = 10
+
+// Upper bounds on what a monitor may set for max_redirects and max_body_bytes
+const (
+	maxAllowedRedirects = 20
+	maxAllowedBody      = 10 << 20
+)
+
+var (
+	errTooManyRedirects = errors.New("too many redirects")
+	errBodyTooLarge     = errors.New("response body is too large")
+)
+
+// followsRedirects reports whether a monitor's check follows redirects (the default)
+func followsRedirects(monitor models.MonitorEntry) bool {
+	return monitor.FollowRedirects == nil || *monitor.FollowRedirects
+}
+
+// maxRedirects is the most redirects a monitor's check follows
+func maxRedirects(monitor models.MonitorEntry) int {
+	if monitor.MaxRedirects > 0 {
+		return monitor.MaxRedirects
+	}
+	return defaultMaxRedirects
+}
+
+// redirectRecorder applies a monitor's redirect policy and keeps the hops it followed
+type redirectRecorder struct {
+	follow bool
+	max    int
+	hops   []models.RedirectHop
+}
+
+func (r *redirectRecorder) check(next *http.Request, via []*http.Request) error {
+	// Not following returns the redirect itself as the response being checked
+	if !r.follow {
+		return http.ErrUseLastResponse
+	}
+
+	if next.Response != nil {
+		r.hops = append(r.hops, models.RedirectHop{
+			URL:        next.Response.Request.URL.String(),
+			StatusCode: next.Response.StatusCode,
+		})
+	}
+
+	if len(via) > r.max {
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, r.max)
+	}
+	return nil
+}
+
+/*
+Function to read a response body for a check. A monitor with max_body_bytes fails
+a larger body without reading the rest of it; otherwise the body is cut off at
+maxCheckBody like it always was.
+*/
+func readCheckBody(monitor models.MonitorEntry, body io.Reader) ([]byte, error) {
+	limit := int64(maxCheckBody)
+	if monitor.MaxBodyBytes > 0 {
+		limit = monitor.MaxBodyBytes
+	}
+
+	// One byte past the limit tells a body that fits exactly from one that doesn't
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		if monitor.MaxBodyBytes > 0 {
+			return nil, fmt.Errorf("%w: more than %d bytes", errBodyTooLarge, limit)
+		}
+		data = data[:limit]
+	}
+
+	return data, nil
+}
+
+// checkAssertions replaces the default 2xx rule with the monitor's own assertions
+func checkAssertions(span tracer.Span, list []models.Assertion, resp *http.Response, body []byte, result *models.MonitorResult) {
+	err := assertions.Check(list, resp, body)
+	if err != nil {
+		span.SetTag("assertion.failed", err.Error())
+		result.IsUp = false
+		result.Error = err.Error()
+		return
+	}
+
+	result.IsUp = true
+}
+
+// buildCheckRequest turns a monitor's request config into the HTTP request a check sends
+func buildCheckRequest(monitor models.MonitorEntry, method string) (*http.Request, error) {
+	var body io.Reader
+	if monitor.Body != "" {
+		body = strings.NewReader(monitor.Body)
+	}
+
+	checkRequest, err := http.NewRequest(method, monitor.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+
+	if monitor.ContentType != "" {
+		checkRequest.Header.Set("Content-Type", monitor.ContentType)
+	}
+
+	for name, value := range monitor.Headers {
+		checkRequest.Header.Set(name, value)
+	}
+
+	if monitor.Auth != nil {
+		switch monitor.Auth.Type {
+		case models.AuthBasic:
+			checkRequest.SetBasicAuth(monitor.Auth.Username, monitor.Auth.Password)
+		case models.AuthBearer:
+			checkRequest.Header.Set("Authorization", "Bearer "+monitor.Auth.Token)
+		}
+	}
+
+	return checkRequest, nil
+}
@@ -0,0 +1,203 @@
+package checks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain: dns checks compare against stored results, so they need a DB
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+
+	m.Run()
+}
+
+// Test PerformCheck with valid URL
+func TestPerformCheck_ValidURL(t *testing.T) {
+	testURL := "https://www.datadoghq.com"
+
+	result := PerformCheck(models.MonitorEntry{URL: testURL})
+
+	assert.True(t, result.IsUp, "Datadog website should be up")
+	assert.Equal(t, 200, result.StatusCode)
+	assert.Greater(t, result.ResponseTime, time.Duration(0))
+	assert.Empty(t, result.Error)
+}
+
+// Test PerformCheck with invalid URL
+func TestPerformCheck_InvalidURL(t *testing.T) {
+	testURL := "https://this-domain-definitely-does-not-exist-12345.com"
+
+	result := PerformCheck(models.MonitorEntry{URL: testURL})
+
+	assert.False(t, result.IsUp, "Invalid domain should be down")
+	assert.NotEmpty(t, result.Error)
+}
+
+// Test PerformCheck with unreachable server
+func TestPerformCheck_UnreachableServer(t *testing.T) {
+	testURL := "http://localhost:9999"
+
+	result := PerformCheck(models.MonitorEntry{URL: testURL})
+
+	assert.False(t, result.IsUp)
+	assert.NotEmpty(t, result.Error)
+}
+
+// Test PerformCheck reports the failing assertion on a 200 response
+func TestPerformCheck_Assertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"healthy": false}`))
+	}))
+	defer server.Close()
+
+	monitor := models.MonitorEntry{
+		URL: server.URL,
+		Assertions: []models.Assertion{
+			{Type: models.AssertHeader, Header: "Content-Type", Value: "application/json"},
+			{Type: models.AssertJSONPath, Path: "$.healthy", Expected: json.RawMessage(`true`)},
+		},
+	}
+
+	result := PerformCheck(monitor)
+
+	assert.False(t, result.IsUp)
+	assert.Equal(t, 200, result.StatusCode)
+	assert.Equal(t, "assertion 2 (json_path $.healthy) failed: expected true, got false", result.Error)
+
+	// A status assertion can accept a non-2xx response
+	monitor.Assertions = []models.Assertion{{Type: models.AssertStatus, Codes: []int{200, 204}}}
+	assert.True(t, PerformCheck(monitor).IsUp)
+}
+
+// Test PerformCheck sends the monitor's method, headers, body and credentials
+func TestPerformCheck_RequestConfig(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	result := PerformCheck(models.MonitorEntry{
+		URL:         server.URL,
+		Method:      http.MethodPost,
+		Headers:     map[string]string{"X-Probe": "url-monitor"},
+		Body:        `{"ping": true}`,
+		ContentType: "application/json",
+		Auth:        &models.RequestAuth{Type: models.AuthBearer, Token: "s3cret"},
+	})
+
+	require.True(t, result.IsUp)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "url-monitor", received.Header.Get("X-Probe"))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer s3cret", received.Header.Get("Authorization"))
+	assert.Equal(t, `{"ping": true}`, string(receivedBody))
+}
+
+// newRedirectServer redirects /hop/N to /hop/N-1 and answers 200 at /hop/0
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hop/{n}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		if n == 0 {
+			w.Write([]byte("arrived"))
+			return
+		}
+		http.Redirect(w, r, "/hop/"+strconv.Itoa(n-1), http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPerformCheck_RedirectPolicy(t *testing.T) {
+	server := newRedirectServer(t)
+	noFollow := false
+
+	// Followed by default, recording each hop and where they ended
+	result := PerformCheck(models.MonitorEntry{URL: server.URL + "/hop/2"})
+
+	require.True(t, result.IsUp, result.Error)
+	assert.Equal(t, server.URL+"/hop/0", result.FinalURL)
+	assert.Equal(t, []models.RedirectHop{
+		{URL: server.URL + "/hop/2", StatusCode: http.StatusFound},
+		{URL: server.URL + "/hop/1", StatusCode: http.StatusFound},
+	}, result.RedirectChain)
+
+	// Not followed, the redirect itself is checked
+	result = PerformCheck(models.MonitorEntry{URL: server.URL + "/hop/2", FollowRedirects: &noFollow})
+
+	assert.False(t, result.IsUp)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Empty(t, result.FinalURL)
+	assert.Empty(t, result.RedirectChain)
+
+	// Past the hop limit
+	result = PerformCheck(models.MonitorEntry{URL: server.URL + "/hop/3", MaxRedirects: 2})
+
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailureTooManyRedirects, result.FailureReason)
+	assert.Len(t, result.RedirectChain, 3)
+
+	result = PerformCheck(models.MonitorEntry{URL: server.URL + "/hop/2", MaxRedirects: 2})
+	assert.True(t, result.IsUp, result.Error)
+}
+
+func TestPerformCheck_MaxBodyBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	result := PerformCheck(models.MonitorEntry{URL: server.URL, MaxBodyBytes: 100})
+	assert.True(t, result.IsUp, result.Error)
+
+	result = PerformCheck(models.MonitorEntry{URL: server.URL, MaxBodyBytes: 99})
+	assert.False(t, result.IsUp)
+	assert.Equal(t, models.FailureBodyTooLarge, result.FailureReason)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+}
+
+func TestPerformCheck_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(1500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	result := PerformCheck(models.MonitorEntry{URL: server.URL, Timeout: 1})
+
+	assert.False(t, result.IsUp)
+	assert.Contains(t, result.Error, "Client.Timeout exceeded")
+	assert.Less(t, result.ResponseTime, 1400*time.Millisecond)
+}
+
+func TestHTTPChecker_ValidateLimits(t *testing.T) {
+	assert.NoError(t, Validate(models.MonitorEntry{URL: "https://example.com", Timeout: 30, MaxRedirects: 5, MaxBodyBytes: 4096}))
+	assert.Error(t, Validate(models.MonitorEntry{URL: "https://example.com", Timeout: 61}))
+	assert.Error(t, Validate(models.MonitorEntry{URL: "https://example.com", MaxRedirects: -1}))
+	assert.Error(t, Validate(models.MonitorEntry{URL: "https://example.com", MaxBodyBytes: 1 << 30}))
+	assert.Error(t, Validate(models.MonitorEntry{URL: "tcp://127.0.0.1:25", Type: models.MonitorTCP, IgnoreTLSErrors: true}))
+}
@@ -0,0 +1,79 @@
+package checks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Limits on retry settings, so a failing monitor can't hold a worker for long
+const (
+	maxRetries    = 5
+	maxRetryDelay = 60 // <- Seconds
+)
+
+// validateRetries checks the retry settings every monitor type shares
+func validateRetries(monitor models.MonitorEntry) error {
+	if monitor.Retries < 0 || monitor.Retries > maxRetries {
+		return fmt.Errorf("retries must be between 0 and %d", maxRetries)
+	}
+
+	if monitor.RetryDelay < 0 || monitor.RetryDelay > maxRetryDelay {
+		return fmt.Errorf("retry_delay must be between 0 and %d seconds", maxRetryDelay)
+	}
+
+	return nil
+}
+
+/*
+Function to check a monitor, retrying a failure up to its retries setting with
+retry_delay between attempts. When confirm_failures is set, a failure that survives
+the retries is checked once more before it counts. Every checker opens its own
+connection per check, so the confirmation can't ride on the failed attempt's socket.
+If the confirmation is up the failure is treated as a blip and the result is up.
+*/
+func runWithRetries(checker Checker, monitor models.MonitorEntry, sleep func(time.Duration)) models.MonitorResult {
+	result := checker.Check(monitor)
+	attempts := []models.CheckAttempt{attemptOf(result, false)}
+
+	for retry := 0; retry < monitor.Retries && !result.IsUp; retry++ {
+		sleep(time.Duration(monitor.RetryDelay) * time.Second)
+		result = checker.Check(monitor)
+		attempts = append(attempts, attemptOf(result, false))
+	}
+
+	if !result.IsUp && monitor.ConfirmFailures {
+		confirmation := checker.Check(monitor)
+		attempts = append(attempts, attemptOf(confirmation, true))
+
+		if confirmation.IsUp {
+			result = confirmation
+			if result.Warning == "" {
+				result.Warning = "failure was not confirmed by a second check"
+			}
+		}
+	}
+
+	if len(attempts) > 1 {
+		result.Attempts = attempts
+
+		if result.IsUp && result.Warning == "" {
+			result.Warning = fmt.Sprintf("up after %d attempts", len(attempts))
+		}
+	}
+
+	return result
+}
+
+// attemptOf summarises one check as an attempt
+func attemptOf(result models.MonitorResult, confirmation bool) models.CheckAttempt {
+	return models.CheckAttempt{
+		Timestamp:    result.Timestamp,
+		IsUp:         result.IsUp,
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.ResponseTime,
+		Error:        result.Error,
+		Confirmation: confirmation,
+	}
+}
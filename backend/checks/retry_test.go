@@ -0,0 +1,134 @@
+package checks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedChecker returns its results in order, one per check
+type scriptedChecker struct {
+	results []bool
+	calls   int
+}
+
+func (c *scriptedChecker) Validate(monitor models.MonitorEntry) error { return nil }
+
+func (c *scriptedChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	isUp := c.results[c.calls]
+	c.calls++
+
+	result := models.MonitorResult{Timestamp: time.Now(), IsUp: isUp, StatusCode: 200}
+	if !isUp {
+		result.StatusCode = 503
+		result.Error = "unexpected status code 503"
+	}
+	return result
+}
+
+func TestRunWithRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		monitor  models.MonitorEntry
+		results  []bool
+		isUp     bool
+		attempts int
+		sleeps   int
+	}{
+		{"up first time", models.MonitorEntry{Retries: 3}, []bool{true}, true, 0, 0},
+		{"no retries", models.MonitorEntry{}, []bool{false}, false, 0, 0},
+		{"recovers on retry", models.MonitorEntry{Retries: 3, RetryDelay: 2}, []bool{false, false, true}, true, 3, 2},
+		{"retries exhausted", models.MonitorEntry{Retries: 2, RetryDelay: 2}, []bool{false, false, false}, false, 3, 2},
+		{"confirmed", models.MonitorEntry{ConfirmFailures: true}, []bool{false, false}, false, 2, 0},
+		{"not confirmed", models.MonitorEntry{Retries: 1, ConfirmFailures: true}, []bool{false, false, true}, true, 3, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &scriptedChecker{results: tt.results}
+			var slept []time.Duration
+
+			result := runWithRetries(checker, tt.monitor, func(d time.Duration) { slept = append(slept, d) })
+
+			assert.Equal(t, tt.isUp, result.IsUp)
+			assert.Len(t, result.Attempts, tt.attempts)
+			assert.Len(t, slept, tt.sleeps)
+			assert.Equal(t, len(tt.results), checker.calls)
+			for _, d := range slept {
+				assert.Equal(t, time.Duration(tt.monitor.RetryDelay)*time.Second, d)
+			}
+		})
+	}
+}
+
+func TestRunWithRetries_RecordsAttempts(t *testing.T) {
+	checker := &scriptedChecker{results: []bool{false, false, true}}
+	monitor := models.MonitorEntry{Retries: 1, ConfirmFailures: true}
+
+	result := runWithRetries(checker, monitor, func(time.Duration) {})
+
+	require.Len(t, result.Attempts, 3)
+	assert.False(t, result.Attempts[0].IsUp)
+	assert.Equal(t, 503, result.Attempts[0].StatusCode)
+	assert.Equal(t, "unexpected status code 503", result.Attempts[1].Error)
+	assert.False(t, result.Attempts[1].Confirmation)
+	assert.True(t, result.Attempts[2].Confirmation)
+	assert.True(t, result.Attempts[2].IsUp)
+	assert.Equal(t, "failure was not confirmed by a second check", result.Warning)
+}
+
+func TestRun_RetriesHTTP(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := Run(models.MonitorEntry{URL: server.URL, Retries: 2})
+
+	assert.True(t, result.IsUp)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Len(t, result.Attempts, 2)
+	assert.Equal(t, "up after 2 attempts", result.Warning)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestRunOnce_DoesNotRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	result := RunOnce(models.MonitorEntry{URL: server.URL, Retries: 3, RetryDelay: 10, ConfirmFailures: true})
+
+	assert.False(t, result.IsUp)
+	assert.Empty(t, result.Attempts)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestValidate_RetryLimits(t *testing.T) {
+	monitor := models.MonitorEntry{URL: "https://example.com"}
+
+	monitor.Retries = maxRetries
+	monitor.RetryDelay = maxRetryDelay
+	assert.NoError(t, Validate(monitor))
+
+	monitor.Retries = maxRetries + 1
+	assert.Error(t, Validate(monitor))
+
+	monitor.Retries = 1
+	monitor.RetryDelay = -1
+	assert.Error(t, Validate(monitor))
+}
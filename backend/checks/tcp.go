@@ -0,0 +1,169 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/assertions"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// How long a tcp check may take, from the lookup to the end of the reply
+const tcpTimeout = 10 * time.Second
+
+// TCPChecker connects to host:port and can exchange a payload for a reply
+type TCPChecker struct{}
+
+/*
+Function to check a tcp monitor's address and assertions before it is saved
+*/
+func (TCPChecker) Validate(monitor models.MonitorEntry) error {
+	_, _, err := hostPort(monitor.URL, models.MonitorTCP)
+	if err != nil {
+		return err
+	}
+
+	if sendsHTTPRequest(monitor) {
+		return fmt.Errorf("tcp monitors only send a body, not a method, headers, auth or other http settings")
+	}
+
+	// The reply is raw bytes, so only the body assertions apply (the banner or a regex)
+	return assertions.ValidateBody(monitor.Assertions)
+}
+
+func (TCPChecker) Check(monitor models.MonitorEntry) models.MonitorResult {
+	/*
+		This function resolves the host and connects to it, timing both, sends the
+		monitor's body as the payload, and when the monitor has assertions reads the
+		reply until they pass, the server closes the connection or the check times out
+	*/
+	span := tracer.StartSpan("tcp.check",
+		tracer.ResourceName(monitor.URL),
+		tracer.SpanType("tcp"),
+	)
+	defer span.Finish()
+
+	startTime := time.Now()
+	timings := &models.CheckTimings{}
+
+	err := checkTCP(monitor, startTime.Add(checkTimeout(monitor, tcpTimeout)), timings)
+
+	result := models.MonitorResult{
+		Timestamp:    time.Now(),
+		ResponseTime: time.Since(startTime),
+		IsUp:         err == nil,
+		Timings:      timings,
+	}
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func checkTCP(monitor models.MonitorEntry, deadline time.Time, timings *models.CheckTimings) error {
+	host, port, err := hostPort(monitor.URL, models.MonitorTCP)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	conn, err := dialTCP(ctx, host, port, timings)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(deadline)
+	sentAt := time.Now()
+
+	if monitor.Body != "" {
+		_, err = io.WriteString(conn, monitor.Body)
+		if err != nil {
+			return fmt.Errorf("error sending payload: %w", err)
+		}
+		sentAt = time.Now()
+	}
+
+	if len(monitor.Assertions) == 0 {
+		return nil
+	}
+
+	return readReply(conn, monitor.Assertions, sentAt, timings)
+}
+
+// dialTCP resolves host (unless it is an IP) and connects to the first address that answers
+func dialTCP(ctx context.Context, host string, port string, timings *models.CheckTimings) (net.Conn, error) {
+	addresses := []string{host}
+
+	if net.ParseIP(host) == nil {
+		lookupStart := time.Now()
+		resolved, err := net.DefaultResolver.LookupHost(ctx, host)
+		timings.DNSLookup = time.Since(lookupStart)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving %s: %w", host, err)
+		}
+		addresses = resolved
+	}
+
+	var dialer net.Dialer
+	var err error
+
+	connectStart := time.Now()
+	for _, address := range addresses {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, port))
+		if err == nil {
+			timings.TCPConnect = time.Since(connectStart)
+			return conn, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error connecting: %w", err)
+}
+
+func readReply(conn net.Conn, list []models.Assertion, sentAt time.Time, timings *models.CheckTimings) error {
+	/*
+		This function reads the reply a chunk at a time and stops as soon as the
+		assertions pass, so a server that keeps the connection open after its banner
+		doesn't hold the check until the timeout
+	*/
+	var reply []byte
+	var firstByte time.Time
+	chunk := make([]byte, 4096)
+
+	for len(reply) < maxCheckBody {
+		n, err := conn.Read(chunk)
+		if n > 0 {
+			if firstByte.IsZero() {
+				firstByte = time.Now()
+				timings.TimeToFirstByte = firstByte.Sub(sentAt)
+			}
+			reply = append(reply, chunk[:n]...)
+			timings.ContentTransfer = time.Since(firstByte)
+
+			if assertions.CheckBody(list, reply) == nil {
+				return nil
+			}
+		}
+
+		if err != nil {
+			if len(reply) == 0 && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("error reading reply: %w", err)
+			}
+			break
+		}
+	}
+
+	return assertions.CheckBody(list, reply)
+}
@@ -0,0 +1,141 @@
+package checks
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTCPServer accepts connections on localhost and hands each one to handle
+func newTCPServer(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestTCPChecker_ConnectOnly(t *testing.T) {
+	url := newTCPServer(t, func(conn net.Conn) {})
+
+	result := Run(models.MonitorEntry{Type: models.MonitorTCP, URL: url})
+
+	assert.True(t, result.IsUp)
+	assert.Empty(t, result.Error)
+	require.NotNil(t, result.Timings)
+	assert.Zero(t, result.Timings.DNSLookup, "an IP address needs no lookup")
+	assert.Greater(t, result.Timings.TCPConnect, time.Duration(0))
+	assert.Greater(t, result.ResponseTime, time.Duration(0))
+}
+
+func TestTCPChecker_Banner(t *testing.T) {
+	// Sends its banner and then keeps the connection open, like SMTP or SSH
+	url := newTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP ready\r\n"))
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	monitor := models.MonitorEntry{
+		Type:       models.MonitorTCP,
+		URL:        url,
+		Assertions: []models.Assertion{{Type: models.AssertBodyRegex, Value: `^220 .*ESMTP`}},
+	}
+
+	startTime := time.Now()
+	result := Run(monitor)
+
+	assert.True(t, result.IsUp, result.Error)
+	assert.Less(t, time.Since(startTime), 400*time.Millisecond, "the check stops once the banner matches")
+
+	monitor.Assertions = []models.Assertion{{Type: models.AssertBodyContains, Value: "SSH-2.0"}}
+	result = Run(monitor)
+
+	assert.False(t, result.IsUp)
+	assert.Equal(t, `assertion 1 (body_contains) failed: body does not contain "SSH-2.0"`, result.Error)
+}
+
+func TestTCPChecker_PayloadAndReply(t *testing.T) {
+	url := newTCPServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	result := Run(models.MonitorEntry{
+		Type:       models.MonitorTCP,
+		URL:        url,
+		Body:       "PING\r\n",
+		Assertions: []models.Assertion{{Type: models.AssertBodyContains, Value: "+PONG"}},
+	})
+
+	assert.True(t, result.IsUp, result.Error)
+	assert.Greater(t, result.Timings.TimeToFirstByte, time.Duration(0))
+}
+
+func TestTCPChecker_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	result := Run(models.MonitorEntry{Type: models.MonitorTCP, URL: "tcp://" + address})
+
+	assert.False(t, result.IsUp)
+	assert.Contains(t, result.Error, "error connecting")
+}
+
+func TestValidate_MonitorTypes(t *testing.T) {
+	cases := []struct {
+		name    string
+		monitor models.MonitorEntry
+		valid   bool
+	}{
+		{"http by default", models.MonitorEntry{URL: "https://example.com"}, true},
+		{"http with tcp url", models.MonitorEntry{URL: "tcp://example.com:25"}, false},
+		{"tcp", models.MonitorEntry{Type: models.MonitorTCP, URL: "tcp://example.com:25"}, true},
+		{"tcp without port", models.MonitorEntry{Type: models.MonitorTCP, URL: "tcp://example.com"}, false},
+		{"tcp with http url", models.MonitorEntry{Type: models.MonitorTCP, URL: "https://example.com:443"}, false},
+		{"tcp with method", models.MonitorEntry{Type: models.MonitorTCP, URL: "tcp://example.com:25", Method: "POST"}, false},
+		{"tcp with status assertion", models.MonitorEntry{
+			Type:       models.MonitorTCP,
+			URL:        "tcp://example.com:25",
+			Assertions: []models.Assertion{{Type: models.AssertStatus, Codes: []int{200}}},
+		}, false},
+		{"unknown type", models.MonitorEntry{Type: "gopher", URL: "gopher://example.com"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.monitor)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package checks
+
+import (
+	"crypto/tls"
+	nethttptrace "net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// checkTimer records when each phase of a check request starts and ends.
+// The trace hooks can fire from the transport's own goroutines, hence the mutex.
+type checkTimer struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyDone     time.Time
+}
+
+func (c *checkTimer) trace() *nethttptrace.ClientTrace {
+	return &nethttptrace.ClientTrace{
+		DNSStart: func(nethttptrace.DNSStartInfo) { c.mark(&c.dnsStart) },
+		DNSDone:  func(nethttptrace.DNSDoneInfo) { c.mark(&c.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Dual-stack dials start several connects; the phase begins with the first
+			c.mu.Lock()
+			if c.connectStart.IsZero() {
+				c.connectStart = time.Now()
+			}
+			c.mu.Unlock()
+		},
+		ConnectDone: func(network string, addr string, err error) {
+			if err == nil {
+				c.mark(&c.connectDone)
+			}
+		},
+		TLSHandshakeStart:    func() { c.mark(&c.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { c.mark(&c.tlsDone) },
+		WroteRequest:         func(nethttptrace.WroteRequestInfo) { c.mark(&c.wroteRequest) },
+		GotFirstResponseByte: func() { c.mark(&c.firstByte) },
+	}
+}
+
+// finishBody marks the end of the content transfer
+func (c *checkTimer) finishBody() {
+	c.mark(&c.bodyDone)
+}
+
+func (c *checkTimer) mark(field *time.Time) {
+	c.mu.Lock()
+	*field = time.Now()
+	c.mu.Unlock()
+}
+
+/*
+Function to turn the recorded marks into per-phase durations. Phases that didn't
+happen (no DNS for an IP, no TLS for http) are left at zero.
+*/
+func (c *checkTimer) timings() *models.CheckTimings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &models.CheckTimings{
+		DNSLookup:       between(c.dnsStart, c.dnsDone),
+		TCPConnect:      between(c.connectStart, c.connectDone),
+		TLSHandshake:    between(c.tlsStart, c.tlsDone),
+		TimeToFirstByte: between(c.wroteRequest, c.firstByte),
+		ContentTransfer: between(c.firstByte, c.bodyDone),
+	}
+}
+
+func between(start time.Time, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,34 @@
+package checks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerformCheck_RecordsPhaseTimings(t *testing.T) {
+	// Slow to answer, then slow to finish the body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("first half"))
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("second half"))
+	}))
+	defer server.Close()
+
+	result := PerformCheck(models.MonitorEntry{URL: server.URL})
+
+	require.True(t, result.IsUp)
+	require.NotNil(t, result.Timings)
+	assert.Zero(t, result.Timings.DNSLookup, "an IP address needs no lookup")
+	assert.Zero(t, result.Timings.TLSHandshake, "plain http has no handshake")
+	assert.Greater(t, result.Timings.TCPConnect, time.Duration(0))
+	assert.GreaterOrEqual(t, result.Timings.TimeToFirstByte, 50*time.Millisecond)
+	assert.GreaterOrEqual(t, result.Timings.ContentTransfer, 30*time.Millisecond)
+}
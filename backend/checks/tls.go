@@ -0,0 +1,159 @@
+package checks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Days before expiry a certificate starts raising a warning, unless the monitor sets its own
+const defaultCertWarningDays = 14
+
+// Roots https checks trust. nil means the system pool; tests swap in their own CA.
+var checkRootCAs *x509.CertPool
+
+// certChecker verifies the chain an https monitor presents and keeps what it saw,
+// so the certificate can be reported even when verification fails
+type certChecker struct {
+	hostname     string // <- Only for handshakes that don't say which server they dialled
+	roots        *x509.CertPool
+	ignoreErrors bool // <- Accept a bad chain, keeping the problem for a warning
+
+	mu      sync.Mutex
+	info    *models.CertificateInfo
+	reason  string
+	ignored string
+}
+
+func newCertChecker(hostname string) *certChecker {
+	return &certChecker{hostname: hostname, roots: checkRootCAs}
+}
+
+/*
+Function to build the TLS config for a check. Go's own verification is replaced
+by verify, which tells apart the ways a chain can be bad.
+*/
+func (c *certChecker) tlsConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true, // <- verify does the checking below
+		VerifyConnection:   c.verify,
+	}
+}
+
+func (c *certChecker) verify(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	// Each handshake is checked against the host it was for, which after a
+	// redirect isn't the monitor's own
+	hostname := state.ServerName
+	if hostname == "" {
+		hostname = c.hostname
+	}
+
+	now := time.Now()
+	info := certificateInfo(state.PeerCertificates[0], now)
+	reason, err := verifyChain(state.PeerCertificates, hostname, c.roots, now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.info = info
+	if err != nil && c.ignoreErrors {
+		c.ignored = err.Error()
+		return nil
+	}
+
+	c.reason = reason
+	return err
+}
+
+// result returns the certificate seen and why it was rejected, if it was
+func (c *certChecker) result() (*models.CertificateInfo, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info, c.reason
+}
+
+// ignoredProblem is what was wrong with a chain accepted because of ignoreErrors
+func (c *certChecker) ignoredProblem() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ignored
+}
+
+/*
+Function to verify a presented chain, returning one of the certificate failure
+reasons alongside the error
+*/
+func verifyChain(certs []*x509.Certificate, hostname string, roots *x509.CertPool, now time.Time) (string, error) {
+	leaf := certs[0]
+
+	if now.After(leaf.NotAfter) {
+		return models.FailureCertExpired, fmt.Errorf("certificate expired on %s", leaf.NotAfter.UTC().Format(time.RFC1123))
+	}
+
+	err := leaf.VerifyHostname(hostname)
+	if err != nil {
+		return models.FailureHostnameMismatch, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err == nil {
+		return "", nil
+	}
+
+	// A chain that stops short of a self-signed root is missing its intermediates
+	var unknownAuthority x509.UnknownAuthorityError
+	last := certs[len(certs)-1]
+	if errors.As(err, &unknownAuthority) && !bytes.Equal(last.RawIssuer, last.RawSubject) {
+		return models.FailureIncompleteChain, fmt.Errorf("incomplete certificate chain: %w", err)
+	}
+
+	return models.FailureUntrustedCert, err
+}
+
+// certificateInfo summarises a leaf certificate as of now
+func certificateInfo(cert *x509.Certificate, now time.Time) *models.CertificateInfo {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return &models.CertificateInfo{
+		Subject:       cert.Subject.String(),
+		Issuer:        cert.Issuer.String(),
+		SANs:          sans,
+		NotAfter:      cert.NotAfter,
+		DaysRemaining: int(cert.NotAfter.Sub(now).Hours() / 24),
+	}
+}
+
+// certWarning is the warning for a certificate expiring within the monitor's window
+func certWarning(monitor models.MonitorEntry, info *models.CertificateInfo) string {
+	warningDays := monitor.CertWarningDays
+	if warningDays <= 0 {
+		warningDays = defaultCertWarningDays
+	}
+
+	if info == nil || info.DaysRemaining >= warningDays {
+		return ""
+	}
+	return fmt.Sprintf("certificate expires in %d days", info.DaysRemaining)
+}
@@ -0,0 +1,218 @@
+package checks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert is a generated certificate and its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issueCert creates a certificate signed by parent, or a self-signed one when parent is nil
+func issueCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+// testPKI is a root CA, an intermediate it signed, and trust in the root for checks
+type testPKI struct {
+	root         *testCert
+	intermediate *testCert
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	ca := func(name string) *x509.Certificate {
+		return &x509.Certificate{
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+
+	root := issueCert(t, ca("Test Root CA"), nil)
+	intermediate := issueCert(t, ca("Test Intermediate CA"), root)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(root.cert)
+
+	original := checkRootCAs
+	checkRootCAs = pool
+	t.Cleanup(func() { checkRootCAs = original })
+
+	return &testPKI{root: root, intermediate: intermediate}
+}
+
+// leaf issues a server certificate from the intermediate
+func (p *testPKI) leaf(t *testing.T, validFor time.Duration, ips []net.IP, dnsNames []string) *testCert {
+	t.Helper()
+	return issueCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "monitored.test"},
+		NotBefore:   time.Now().Add(-48 * time.Hour),
+		NotAfter:    time.Now().Add(validFor),
+		IPAddresses: ips,
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, p.intermediate)
+}
+
+// newCertServer serves https with the given chain (leaf first)
+func newCertServer(t *testing.T, leaf *testCert, chain ...*testCert) *httptest.Server {
+	t.Helper()
+
+	certificate := tls.Certificate{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}
+	for _, cert := range chain {
+		certificate.Certificate = append(certificate.Certificate, cert.cert.Raw)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+var localhostIP = []net.IP{net.ParseIP("127.0.0.1")}
+
+func TestPerformCheck_CertificateInfoAndWarning(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.leaf(t, 30*24*time.Hour+time.Hour, localhostIP, []string{"monitored.test"})
+	server := newCertServer(t, leaf, pki.intermediate)
+
+	result := PerformCheck(models.MonitorEntry{URL: server.URL})
+
+	require.True(t, result.IsUp, result.Error)
+	require.NotNil(t, result.Certificate)
+	assert.Equal(t, 30, result.Certificate.DaysRemaining)
+	assert.Equal(t, "CN=Test Intermediate CA", result.Certificate.Issuer)
+	assert.Equal(t, []string{"monitored.test", "127.0.0.1"}, result.Certificate.SANs)
+	assert.Empty(t, result.FailureReason)
+	assert.Empty(t, result.Warning, "30 days is outside the default 14 day window")
+	assert.Greater(t, result.Timings.TLSHandshake, time.Duration(0))
+
+	// A wider per-monitor window turns the same certificate into a warning, while staying up
+	result = PerformCheck(models.MonitorEntry{URL: server.URL, CertWarningDays: 45})
+
+	assert.True(t, result.IsUp)
+	assert.Equal(t, "certificate expires in 30 days", result.Warning)
+}
+
+func TestPerformCheck_CertificateFailureReasons(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name   string
+		server func() *httptest.Server
+		reason string
+	}{
+		{
+			name: "expired",
+			server: func() *httptest.Server {
+				return newCertServer(t, pki.leaf(t, -time.Hour, localhostIP, nil), pki.intermediate)
+			},
+			reason: models.FailureCertExpired,
+		},
+		{
+			name: "hostname mismatch",
+			server: func() *httptest.Server {
+				return newCertServer(t, pki.leaf(t, 90*24*time.Hour, nil, []string{"other.test"}), pki.intermediate)
+			},
+			reason: models.FailureHostnameMismatch,
+		},
+		{
+			name: "incomplete chain",
+			server: func() *httptest.Server {
+				return newCertServer(t, pki.leaf(t, 90*24*time.Hour, localhostIP, nil))
+			},
+			reason: models.FailureIncompleteChain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := PerformCheck(models.MonitorEntry{URL: tt.server().URL})
+
+			assert.False(t, result.IsUp)
+			assert.Equal(t, tt.reason, result.FailureReason)
+			assert.NotEmpty(t, result.Error)
+			assert.NotNil(t, result.Certificate, "the certificate is reported even when rejected")
+		})
+	}
+}
+
+func TestPerformCheck_CrossHostRedirectVerifiesTheNewHost(t *testing.T) {
+	// 127.0.0.1 redirects to localhost; each certificate only covers its own host
+	pki := newTestPKI(t)
+	target := newCertServer(t, pki.leaf(t, 90*24*time.Hour, nil, []string{"localhost"}), pki.intermediate)
+	_, targetPort, err := net.SplitHostPort(target.Listener.Addr().String())
+	require.NoError(t, err)
+
+	leaf := pki.leaf(t, 90*24*time.Hour, localhostIP, nil)
+	redirect := httptest.NewUnstartedServer(http.RedirectHandler("https://localhost:"+targetPort+"/", http.StatusFound))
+	redirect.TLS = &tls.Config{Certificates: []tls.Certificate{{
+		Certificate: [][]byte{leaf.cert.Raw, pki.intermediate.cert.Raw},
+		PrivateKey:  leaf.key,
+	}}}
+	redirect.StartTLS()
+	t.Cleanup(redirect.Close)
+
+	result := PerformCheck(models.MonitorEntry{URL: redirect.URL})
+
+	require.True(t, result.IsUp, result.Error)
+	assert.Empty(t, result.FailureReason)
+	assert.Equal(t, "https://localhost:"+targetPort+"/", result.FinalURL)
+}
+
+func TestPerformCheck_IgnoreTLSErrors(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newCertServer(t, pki.leaf(t, -time.Hour, localhostIP, nil), pki.intermediate)
+
+	result := PerformCheck(models.MonitorEntry{URL: server.URL, IgnoreTLSErrors: true})
+
+	require.True(t, result.IsUp, result.Error)
+	assert.Empty(t, result.FailureReason)
+	assert.Contains(t, result.Warning, "ignored certificate problem: certificate expired")
+	assert.NotNil(t, result.Certificate)
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every API key read, in the order scanAPIKey expects
+const apiKeyColumns = `id, team_id, name, scope, prefix, created_at, last_used_at, revoked_at`
+
+/*
+Function to scan a row selected with apiKeyColumns into an APIKey
+*/
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.TeamID,
+		&key.Name,
+		&key.Scope,
+		&key.Prefix,
+		&key.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
+
+/*
+Function to save a new API key along with the hash of its secret
+*/
+func SaveAPIKey(key models.APIKey, keyHash string) error {
+	span := tracer.StartSpan("db.save_api_key",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO api_keys"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO api_keys (id, team_id, name, scope, prefix, key_hash, created_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	// Admin keys belong to no team; everything else is filed under one
+	teamID := ""
+	if key.Scope != models.ScopeAdmin {
+		teamID = teamOrDefault(key.TeamID)
+	}
+
+	_, err := db.Exec(query,
+		key.ID,
+		teamID,
+		key.Name,
+		key.Scope,
+		key.Prefix,
+		keyHash,
+		key.CreatedAt.UTC(),
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving api key to db: %w", err)
+	}
+
+	log.Printf("API key %s saved successfully", key.ID)
+	return nil
+}
+
+/*
+Function to find the unrevoked API key with the given hash
+*/
+func GetActiveAPIKey(keyHash string) (models.APIKey, error) {
+	span := tracer.StartSpan("db.get_active_api_key",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM api_keys WHERE key_hash = ?"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL`
+
+	key, err := scanAPIKey(db.QueryRow(query, keyHash))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.APIKey{}, fmt.Errorf("error querying db for api key: %w", err)
+	}
+
+	return key, nil
+}
+
+/*
+Function to get one of the tenant's API keys by ID, revoked or not
+*/
+func GetAPIKey(tenant Tenant, id string) (models.APIKey, error) {
+	span := tracer.StartSpan("db.get_api_key",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM api_keys WHERE id = ?"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("team_id")
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ? AND ` + condition
+
+	key, err := scanAPIKey(db.QueryRow(query, append([]any{id}, args...)...))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.APIKey{}, fmt.Errorf("error querying db for api key: %w", err)
+	}
+
+	return key, nil
+}
+
+/*
+Function to list the tenant's API keys, revoked ones included, oldest first
+*/
+func GetAPIKeys(tenant Tenant) ([]models.APIKey, error) {
+	span := tracer.StartSpan("db.get_api_keys",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM api_keys"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("team_id")
+
+	rows, err := db.Query(`SELECT `+apiKeyColumns+` FROM api_keys WHERE `+condition+` ORDER BY created_at`, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning api key: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+/*
+Function to count every API key ever made, revoked ones included
+*/
+func CountAPIKeys() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM api_keys`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting api keys: %w", err)
+	}
+	return count, nil
+}
+
+/*
+Function to revoke one of the tenant's API keys. Returns sql.ErrNoRows when it
+has no such unrevoked key.
+*/
+func RevokeAPIKey(tenant Tenant, id string, revokedAt time.Time) error {
+	span := tracer.StartSpan("db.revoke_api_key",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE api_keys SET revoked_at"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("team_id")
+
+	res, err := db.Exec(`UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL AND `+condition,
+		append([]any{revokedAt.UTC(), id}, args...)...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	log.Printf("API key %s revoked", id)
+	return nil
+}
+
+/*
+Function to record when an API key was last used
+*/
+func TouchAPIKey(id string, usedAt time.Time) error {
+	_, err := db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, usedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("error updating api key last use: %w", err)
+	}
+	return nil
+}
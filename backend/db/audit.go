@@ -0,0 +1,208 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every audit read, in the order scanAuditEntry expects
+const auditColumns = `id, timestamp, actor, actor_type, actor_id, action, target_type, target_id, team_id, before, after, diff`
+
+/*
+Function to scan a row selected with auditColumns into an AuditEntry
+*/
+func scanAuditEntry(row rowScanner) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var actorID, teamID, before, after, diff sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.Timestamp,
+		&entry.Actor,
+		&entry.ActorType,
+		&actorID,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetID,
+		&teamID,
+		&before,
+		&after,
+		&diff,
+	)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+
+	entry.ActorID = actorID.String
+	entry.TeamID = teamID.String
+
+	if before.Valid {
+		entry.Before = json.RawMessage(before.String)
+	}
+	if after.Valid {
+		entry.After = json.RawMessage(after.String)
+	}
+	if diff.Valid {
+		err = json.Unmarshal([]byte(diff.String), &entry.Diff)
+		if err != nil {
+			return models.AuditEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
+
+// nullJSON stores empty JSON as NULL rather than an empty string
+func nullJSON(value []byte) sql.NullString {
+	return sql.NullString{String: string(value), Valid: len(value) > 0}
+}
+
+/*
+Function to append an entry to the audit log, returning its ID
+*/
+func SaveAuditEntry(entry models.AuditEntry) (int64, error) {
+	span := tracer.StartSpan("db.save_audit_entry",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO audit_log"),
+	)
+	defer span.Finish()
+
+	var diff []byte
+	if len(entry.Diff) > 0 {
+		var err error
+		diff, err = json.Marshal(entry.Diff)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return 0, fmt.Errorf("error encoding audit diff: %w", err)
+		}
+	}
+
+	query := `
+    INSERT INTO audit_log (timestamp, actor, actor_type, actor_id, action, target_type, target_id, team_id, before, after, diff)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(query,
+		entry.Timestamp.UTC(),
+		entry.Actor,
+		entry.ActorType,
+		sql.NullString{String: entry.ActorID, Valid: entry.ActorID != ""},
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		sql.NullString{String: entry.TeamID, Valid: entry.TeamID != ""},
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		nullJSON(diff),
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return 0, fmt.Errorf("error saving audit entry to db: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// AuditFilter narrows an audit log listing. Empty fields mean "no filter".
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	TeamID     string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Tenant     Tenant // <- Only changes to the tenant's teams
+}
+
+/*
+Function to list audit log entries, most recent first
+*/
+func GetAuditEntries(filter AuditFilter) ([]models.AuditEntry, error) {
+	span := tracer.StartSpan("db.get_audit_entries",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM audit_log ORDER BY timestamp DESC"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE 1 = 1`
+	var args []any
+
+	if filter.Actor != "" {
+		query += ` AND actor = ?`
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		query += ` AND action = ?`
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		query += ` AND target_type = ?`
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query += ` AND target_id = ?`
+		args = append(args, filter.TargetID)
+	}
+	if filter.TeamID != "" {
+		query += ` AND team_id = ?`
+		args = append(args, filter.TeamID)
+	}
+	if filter.Tenant.scoped {
+		condition, tenantArgs := filter.Tenant.where("team_id")
+		query += ` AND ` + condition
+		args = append(args, tenantArgs...)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND timestamp <= ?`
+		args = append(args, filter.Until.UTC())
+	}
+
+	query += ` ORDER BY timestamp DESC, id DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through audit entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every channel read, in the order scanChannel expects
+const channelColumns = `id, team_id, name, type, config, enabled, created_at, updated_at`
+
+/*
+Function to scan a row selected with channelColumns into a NotificationChannel
+*/
+func scanChannel(row rowScanner) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	var config string
+
+	err := row.Scan(
+		&channel.ID,
+		&channel.TeamID,
+		&channel.Name,
+		&channel.Type,
+		&config,
+		&channel.Enabled,
+		&channel.CreatedAt,
+		&channel.UpdatedAt,
+	)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+
+	channel.Config = []byte(config)
+
+	return channel, nil
+}
+
+/*
+Function to save a new notification channel
+*/
+func SaveChannel(channel models.NotificationChannel) error {
+	span := tracer.StartSpan("db.save_channel",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO notification_channels"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO notification_channels (id, team_id, name, type, config, enabled, created_at, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Exec(query,
+		channel.ID,
+		teamOrDefault(channel.TeamID),
+		channel.Name,
+		channel.Type,
+		string(channel.Config),
+		channel.Enabled,
+		channel.CreatedAt,
+		channel.UpdatedAt,
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving channel to db: %w", err)
+	}
+
+	log.Printf("Notification channel %s saved successfully", channel.ID)
+	return nil
+}
+
+/*
+Function to update the name, config and enabled flag of a channel
+*/
+func UpdateChannel(channel models.NotificationChannel) error {
+	span := tracer.StartSpan("db.update_channel",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE notification_channels"),
+	)
+	defer span.Finish()
+
+	query := `
+    UPDATE notification_channels
+    SET name = ?, config = ?, enabled = ?, updated_at = ?
+    WHERE id = ?`
+
+	_, err := db.Exec(query,
+		channel.Name,
+		string(channel.Config),
+		channel.Enabled,
+		channel.UpdatedAt,
+		channel.ID,
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating channel: %w", err)
+	}
+
+	log.Printf("Notification channel %s updated successfully", channel.ID)
+	return nil
+}
+
+/*
+Function to get a single notification channel of the tenant's
+*/
+func GetChannel(tenant Tenant, id string) (models.NotificationChannel, error) {
+	span := tracer.StartSpan("db.get_channel",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM notification_channels WHERE id = ?"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("team_id")
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE id = ? AND ` + condition
+
+	channel, err := scanChannel(db.QueryRow(query, append([]any{id}, args...)...))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.NotificationChannel{}, fmt.Errorf("error querying db for channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+/*
+Function to list the tenant's notification channels, only the enabled ones if
+enabledOnly is set
+*/
+func GetChannels(tenant Tenant, enabledOnly bool) ([]models.NotificationChannel, error) {
+	span := tracer.StartSpan("db.get_channels",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM notification_channels"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("team_id")
+
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE ` + condition
+	if enabledOnly {
+		query += ` AND enabled = 1`
+	}
+	query += ` ORDER BY created_at`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for channels: %w", err)
+	}
+	defer rows.Close()
+
+	channels := []models.NotificationChannel{}
+
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning channel: %w", err)
+		}
+
+		channels = append(channels, channel)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+/*
+Function to delete a notification channel
+*/
+func DeleteChannel(id string) error {
+	span := tracer.StartSpan("db.delete_channel",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("DELETE FROM notification_channels"),
+	)
+	defer span.Finish()
+
+	tx, err := db.Begin()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error starting delete transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Delivery history goes with the channel it was sent to
+	_, err = tx.Exec(`DELETE FROM notification_deliveries WHERE channel_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting deliveries for channel: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM notification_channels WHERE id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting channel: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error committing channel delete: %w", err)
+	}
+
+	log.Printf("Notification channel %s deleted successfully", id)
+	return nil
+}
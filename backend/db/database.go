@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/KerlynD/URL-Monitor/backend/models"
 	_ "modernc.org/sqlite"
 )
 
@@ -23,6 +25,10 @@ func InitDB(dbPath string) error {
 		return fmt.Errorf("error opening the DB connection pool: %w", err)
 	}
 
+	// SQLite only allows one writer; serialising on a single connection avoids
+	// "database is locked" errors from concurrent checks (and keeps :memory: DBs shared)
+	db.SetMaxOpenConns(1)
+
 	// Test
 	err = db.Ping()
 	if err != nil {
@@ -59,7 +65,34 @@ func createTables() error {
         url TEXT NOT NULL,
         check_interval INTEGER NOT NULL,
         created_at DATETIME,
-        updated_at DATETIME
+        updated_at DATETIME,
+        last_check_at DATETIME,
+        next_check_at DATETIME,
+        paused BOOLEAN NOT NULL DEFAULT 0,
+        failure_threshold INTEGER NOT NULL DEFAULT 1,
+        recovery_threshold INTEGER NOT NULL DEFAULT 1,
+        assertions TEXT,
+        method TEXT,
+        headers TEXT,
+        body TEXT,
+        content_type TEXT,
+        auth TEXT,
+        cert_warning_days INTEGER NOT NULL DEFAULT 0,
+        type TEXT NOT NULL DEFAULT 'http',
+        dns TEXT,
+        grpc TEXT,
+        heartbeat TEXT,
+        ping_token TEXT,
+        ping_started_at DATETIME,
+        retries INTEGER NOT NULL DEFAULT 0,
+        retry_delay INTEGER NOT NULL DEFAULT 0,
+        confirm_failures BOOLEAN NOT NULL DEFAULT 0,
+        timeout INTEGER NOT NULL DEFAULT 0,
+        follow_redirects BOOLEAN,
+        max_redirects INTEGER NOT NULL DEFAULT 0,
+        ignore_tls_errors BOOLEAN NOT NULL DEFAULT 0,
+        max_body_bytes INTEGER NOT NULL DEFAULT 0,
+        team_id TEXT NOT NULL DEFAULT 'default'
     );`
 
 	resultsTable := `
@@ -71,9 +104,188 @@ func createTables() error {
         is_up BOOLEAN,
         error TEXT,
         timestamp DATETIME,
+        dns_lookup_us INTEGER,
+        tcp_connect_us INTEGER,
+        tls_handshake_us INTEGER,
+        ttfb_us INTEGER,
+        content_transfer_us INTEGER,
+        cert_subject TEXT,
+        cert_issuer TEXT,
+        cert_sans TEXT,
+        cert_not_after DATETIME,
+        cert_days_remaining INTEGER,
+        failure_reason TEXT,
+        warning TEXT,
+        dns_records TEXT,
+        grpc_status TEXT,
+        attempts TEXT,
+        final_url TEXT,
+        redirect_chain TEXT,
+        run_time_ms INTEGER,
         FOREIGN KEY (monitor_id) REFERENCES monitors(id)
     );`
 
+	incidentsTable := `
+    CREATE TABLE IF NOT EXISTS incidents (
+        id TEXT PRIMARY KEY,
+        monitor_id TEXT NOT NULL,
+        status TEXT NOT NULL,
+        cause TEXT,
+        started_at DATETIME NOT NULL,
+        resolved_at DATETIME,
+        FOREIGN KEY (monitor_id) REFERENCES monitors(id)
+    );`
+
+	incidentsIndex := `
+    CREATE INDEX IF NOT EXISTS idx_incidents_monitor_started
+    ON incidents (monitor_id, started_at);`
+
+	channelsTable := `
+    CREATE TABLE IF NOT EXISTS notification_channels (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        type TEXT NOT NULL,
+        config TEXT NOT NULL,
+        enabled BOOLEAN NOT NULL DEFAULT 1,
+        created_at DATETIME,
+        updated_at DATETIME,
+        team_id TEXT NOT NULL DEFAULT 'default'
+    );`
+
+	// One row per attempt to deliver an incident event to a channel
+	deliveriesTable := `
+    CREATE TABLE IF NOT EXISTS notification_deliveries (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        channel_id TEXT NOT NULL,
+        monitor_id TEXT NOT NULL,
+        incident_id TEXT NOT NULL,
+        event TEXT NOT NULL,
+        attempt INTEGER NOT NULL,
+        status TEXT NOT NULL,
+        error TEXT,
+        created_at DATETIME NOT NULL
+    );`
+
+	deliveriesIndex := `
+    CREATE INDEX IF NOT EXISTS idx_deliveries_channel_created
+    ON notification_deliveries (channel_id, created_at);`
+
+	// Keys are looked up by the hash of the key a request sent
+	apiKeysTable := `
+    CREATE TABLE IF NOT EXISTS api_keys (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        scope TEXT NOT NULL,
+        prefix TEXT NOT NULL,
+        key_hash TEXT NOT NULL UNIQUE,
+        created_at DATETIME NOT NULL,
+        last_used_at DATETIME,
+        revoked_at DATETIME,
+        team_id TEXT NOT NULL DEFAULT 'default'
+    );`
+
+	usersTable := `
+    CREATE TABLE IF NOT EXISTS users (
+        id TEXT PRIMARY KEY,
+        email TEXT NOT NULL UNIQUE,
+        password_hash TEXT NOT NULL,
+        role TEXT NOT NULL,
+        created_at DATETIME NOT NULL,
+        updated_at DATETIME NOT NULL,
+        sso_issuer TEXT,
+        sso_subject TEXT
+    );`
+
+	// Sessions are looked up by the hash of the cookie a browser sent
+	sessionsTable := `
+    CREATE TABLE IF NOT EXISTS sessions (
+        token_hash TEXT PRIMARY KEY,
+        user_id TEXT NOT NULL,
+        created_at DATETIME NOT NULL,
+        expires_at DATETIME NOT NULL,
+        FOREIGN KEY (user_id) REFERENCES users(id)
+    );`
+
+	organizationsTable := `
+    CREATE TABLE IF NOT EXISTS organizations (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        created_at DATETIME NOT NULL
+    );`
+
+	teamsTable := `
+    CREATE TABLE IF NOT EXISTS teams (
+        id TEXT PRIMARY KEY,
+        org_id TEXT NOT NULL,
+        name TEXT NOT NULL,
+        created_at DATETIME NOT NULL,
+        UNIQUE (org_id, name),
+        FOREIGN KEY (org_id) REFERENCES organizations(id)
+    );`
+
+	teamMembersTable := `
+    CREATE TABLE IF NOT EXISTS team_members (
+        team_id TEXT NOT NULL,
+        user_id TEXT NOT NULL,
+        created_at DATETIME NOT NULL,
+        PRIMARY KEY (team_id, user_id),
+        FOREIGN KEY (team_id) REFERENCES teams(id),
+        FOREIGN KEY (user_id) REFERENCES users(id)
+    );`
+
+	// Every change made through the API. Rows are only ever inserted; the
+	// triggers below refuse updates and deletes.
+	auditTable := `
+    CREATE TABLE IF NOT EXISTS audit_log (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        timestamp DATETIME NOT NULL,
+        actor TEXT NOT NULL,
+        actor_type TEXT NOT NULL,
+        actor_id TEXT,
+        action TEXT NOT NULL,
+        target_type TEXT NOT NULL,
+        target_id TEXT NOT NULL,
+        team_id TEXT,
+        before TEXT,
+        after TEXT,
+        diff TEXT
+    );`
+
+	auditIndex := `
+    CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp
+    ON audit_log (timestamp);`
+
+	auditNoUpdate := `
+    CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;`
+
+	auditNoDelete := `
+    CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;`
+
+	// Pings look their heartbeat monitor up by token. Created after migrateTables,
+	// since older DB files only get the column there.
+	pingTokenIndex := `
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_monitors_ping_token
+    ON monitors (ping_token);`
+
+	// A single sign-on identity belongs to at most one user. Created after
+	// migrateTables, like the ping token index.
+	ssoIdentityIndex := `
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_users_sso_identity
+    ON users (sso_issuer, sso_subject);`
+
+	// History queries filter by monitor and walk back through time
+	resultsIndex := `
+    CREATE INDEX IF NOT EXISTS idx_results_monitor_timestamp
+    ON results (monitor_id, timestamp);`
+
 	// Execute
 	_, err := db.Exec(monitorsTable)
 	if err != nil {
@@ -85,6 +297,255 @@ func createTables() error {
 		return fmt.Errorf("error creating results table: %w", err)
 	}
 
+	_, err = db.Exec(resultsIndex)
+	if err != nil {
+		return fmt.Errorf("error creating results index: %w", err)
+	}
+
+	_, err = db.Exec(incidentsTable)
+	if err != nil {
+		return fmt.Errorf("error creating incidents table: %w", err)
+	}
+
+	_, err = db.Exec(incidentsIndex)
+	if err != nil {
+		return fmt.Errorf("error creating incidents index: %w", err)
+	}
+
+	_, err = db.Exec(channelsTable)
+	if err != nil {
+		return fmt.Errorf("error creating notification channels table: %w", err)
+	}
+
+	_, err = db.Exec(deliveriesTable)
+	if err != nil {
+		return fmt.Errorf("error creating notification deliveries table: %w", err)
+	}
+
+	_, err = db.Exec(deliveriesIndex)
+	if err != nil {
+		return fmt.Errorf("error creating notification deliveries index: %w", err)
+	}
+
+	_, err = db.Exec(apiKeysTable)
+	if err != nil {
+		return fmt.Errorf("error creating api keys table: %w", err)
+	}
+
+	_, err = db.Exec(usersTable)
+	if err != nil {
+		return fmt.Errorf("error creating users table: %w", err)
+	}
+
+	_, err = db.Exec(sessionsTable)
+	if err != nil {
+		return fmt.Errorf("error creating sessions table: %w", err)
+	}
+
+	_, err = db.Exec(organizationsTable)
+	if err != nil {
+		return fmt.Errorf("error creating organizations table: %w", err)
+	}
+
+	_, err = db.Exec(teamsTable)
+	if err != nil {
+		return fmt.Errorf("error creating teams table: %w", err)
+	}
+
+	_, err = db.Exec(teamMembersTable)
+	if err != nil {
+		return fmt.Errorf("error creating team members table: %w", err)
+	}
+
+	_, err = db.Exec(auditTable)
+	if err != nil {
+		return fmt.Errorf("error creating audit log table: %w", err)
+	}
+
+	_, err = db.Exec(auditIndex)
+	if err != nil {
+		return fmt.Errorf("error creating audit log index: %w", err)
+	}
+
+	_, err = db.Exec(auditNoUpdate)
+	if err != nil {
+		return fmt.Errorf("error creating audit log update trigger: %w", err)
+	}
+
+	_, err = db.Exec(auditNoDelete)
+	if err != nil {
+		return fmt.Errorf("error creating audit log delete trigger: %w", err)
+	}
+
+	err = createDefaultTeam()
+	if err != nil {
+		return fmt.Errorf("error creating default team: %w", err)
+	}
+
+	err = migrateTables()
+	if err != nil {
+		return fmt.Errorf("error migrating tables: %w", err)
+	}
+
+	_, err = db.Exec(pingTokenIndex)
+	if err != nil {
+		return fmt.Errorf("error creating ping token index: %w", err)
+	}
+
+	_, err = db.Exec(ssoIdentityIndex)
+	if err != nil {
+		return fmt.Errorf("error creating sso identity index: %w", err)
+	}
+
 	log.Println("Tables created successfully")
 	return nil
 }
+
+func migrateTables() error {
+	/*
+		Function to add columns introduced after a table was first created, so
+		existing DB files pick them up without being recreated
+	*/
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"monitors", "last_check_at", "DATETIME"},
+		{"monitors", "next_check_at", "DATETIME"},
+		{"monitors", "paused", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"monitors", "failure_threshold", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitors", "recovery_threshold", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitors", "assertions", "TEXT"},
+		{"monitors", "method", "TEXT"},
+		{"monitors", "headers", "TEXT"},
+		{"monitors", "body", "TEXT"},
+		{"monitors", "content_type", "TEXT"},
+		{"monitors", "auth", "TEXT"},
+		{"results", "dns_lookup_us", "INTEGER"},
+		{"results", "tcp_connect_us", "INTEGER"},
+		{"results", "tls_handshake_us", "INTEGER"},
+		{"results", "ttfb_us", "INTEGER"},
+		{"results", "content_transfer_us", "INTEGER"},
+		{"monitors", "cert_warning_days", "INTEGER NOT NULL DEFAULT 0"},
+		{"results", "cert_subject", "TEXT"},
+		{"results", "cert_issuer", "TEXT"},
+		{"results", "cert_sans", "TEXT"},
+		{"results", "cert_not_after", "DATETIME"},
+		{"results", "cert_days_remaining", "INTEGER"},
+		{"results", "failure_reason", "TEXT"},
+		{"results", "warning", "TEXT"},
+		{"monitors", "type", "TEXT NOT NULL DEFAULT 'http'"},
+		{"monitors", "dns", "TEXT"},
+		{"results", "dns_records", "TEXT"},
+		{"monitors", "grpc", "TEXT"},
+		{"results", "grpc_status", "TEXT"},
+		{"monitors", "heartbeat", "TEXT"},
+		{"monitors", "ping_token", "TEXT"},
+		{"monitors", "ping_started_at", "DATETIME"},
+		{"monitors", "retries", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "retry_delay", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "confirm_failures", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"results", "attempts", "TEXT"},
+		{"monitors", "timeout", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "follow_redirects", "BOOLEAN"},
+		{"monitors", "max_redirects", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "ignore_tls_errors", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"monitors", "max_body_bytes", "INTEGER NOT NULL DEFAULT 0"},
+		{"results", "final_url", "TEXT"},
+		{"results", "redirect_chain", "TEXT"},
+		{"results", "run_time_ms", "INTEGER"},
+		{"monitors", "team_id", "TEXT NOT NULL DEFAULT 'default'"},
+		{"notification_channels", "team_id", "TEXT NOT NULL DEFAULT 'default'"},
+		{"api_keys", "team_id", "TEXT NOT NULL DEFAULT 'default'"},
+		{"users", "sso_issuer", "TEXT"},
+		{"users", "sso_subject", "TEXT"},
+	}
+
+	for _, column := range columns {
+		err := addColumnIfMissing(column.table, column.name, column.definition)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Admin keys reach every team, so they belong to none. Keys made before that
+	// rule were filed under the default team.
+	_, err := db.Exec(`UPDATE api_keys SET team_id = '' WHERE scope = ? AND team_id != ''`, models.ScopeAdmin)
+	if err != nil {
+		return fmt.Errorf("error moving admin keys out of teams: %w", err)
+	}
+
+	return nil
+}
+
+func createDefaultTeam() error {
+	/*
+		Function to make sure the default organization and team exist, since
+		everything made before teams existed is migrated into them
+	*/
+	now := time.Now().UTC()
+
+	_, err := db.Exec(`INSERT OR IGNORE INTO organizations (id, name, created_at) VALUES (?, ?, ?)`,
+		models.DefaultTeam, "Default", now)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT OR IGNORE INTO teams (id, org_id, name, created_at) VALUES (?, ?, ?, ?)`,
+		models.DefaultTeam, models.DefaultTeam, "Default", now)
+	return err
+}
+
+func addColumnIfMissing(table, column, definition string) error {
+	exists, err := columnExists(table, column)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("error adding column %s.%s: %w", table, column, err)
+	}
+
+	log.Printf("Added column %s.%s", table, column)
+	return nil
+}
+
+func columnExists(table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("error reading columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      bool
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+
+		err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey)
+		if err != nil {
+			return false, fmt.Errorf("error scanning columns of %s: %w", table, err)
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return false, fmt.Errorf("error iterating columns of %s: %w", table, err)
+	}
+
+	return false, nil
+}
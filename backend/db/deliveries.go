@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every delivery read, in the order scanDelivery expects
+const deliveryColumns = `id, channel_id, monitor_id, incident_id, event, attempt, status, error, created_at`
+
+/*
+Function to scan a row selected with deliveryColumns into a NotificationDelivery
+*/
+func scanDelivery(row rowScanner) (models.NotificationDelivery, error) {
+	var delivery models.NotificationDelivery
+	var deliveryError sql.NullString
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.ChannelID,
+		&delivery.MonitorID,
+		&delivery.IncidentID,
+		&delivery.Event,
+		&delivery.Attempt,
+		&delivery.Status,
+		&deliveryError,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		return models.NotificationDelivery{}, err
+	}
+
+	delivery.Error = deliveryError.String
+	return delivery, nil
+}
+
+/*
+Function to record one delivery attempt
+*/
+func SaveDelivery(delivery models.NotificationDelivery) error {
+	span := tracer.StartSpan("db.save_delivery",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO notification_deliveries"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO notification_deliveries (channel_id, monitor_id, incident_id, event, attempt, status, error, created_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Exec(query,
+		delivery.ChannelID,
+		delivery.MonitorID,
+		delivery.IncidentID,
+		delivery.Event,
+		delivery.Attempt,
+		delivery.Status,
+		delivery.Error,
+		delivery.CreatedAt.UTC(),
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving delivery to db: %w", err)
+	}
+
+	return nil
+}
+
+// DeliveryFilter narrows a delivery listing. Empty fields mean "no filter".
+type DeliveryFilter struct {
+	ChannelID string
+	Status    string
+	Limit     int
+	Tenant    Tenant // <- Only deliveries to the tenant's channels
+}
+
+/*
+Function to list delivery attempts, most recent first
+*/
+func GetDeliveries(filter DeliveryFilter) ([]models.NotificationDelivery, error) {
+	span := tracer.StartSpan("db.get_deliveries",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM notification_deliveries ORDER BY created_at DESC"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + deliveryColumns + ` FROM notification_deliveries WHERE 1 = 1`
+	var args []any
+
+	if filter.ChannelID != "" {
+		query += ` AND channel_id = ?`
+		args = append(args, filter.ChannelID)
+	}
+	if filter.Tenant.scoped {
+		condition, tenantArgs := filter.Tenant.where("team_id")
+		query += ` AND channel_id IN (SELECT id FROM notification_channels WHERE ` + condition + `)`
+		args = append(args, tenantArgs...)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,211 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every incident read, in the order scanIncident expects
+const incidentColumns = `id, monitor_id, status, cause, started_at, resolved_at`
+
+/*
+Function to scan a row selected with incidentColumns into an Incident
+*/
+func scanIncident(row rowScanner) (models.Incident, error) {
+	var incident models.Incident
+	var cause sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(
+		&incident.ID,
+		&incident.MonitorID,
+		&incident.Status,
+		&cause,
+		&incident.StartedAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return models.Incident{}, err
+	}
+
+	incident.Cause = cause.String
+
+	if resolvedAt.Valid {
+		incident.ResolvedAt = &resolvedAt.Time
+		incident.Duration = resolvedAt.Time.Sub(incident.StartedAt)
+	} else {
+		incident.Duration = time.Since(incident.StartedAt)
+	}
+
+	return incident, nil
+}
+
+/*
+Function to save a newly opened incident
+*/
+func CreateIncident(incident models.Incident) error {
+	span := tracer.StartSpan("db.create_incident",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO incidents"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO incidents (id, monitor_id, status, cause, started_at)
+    VALUES (?, ?, ?, ?, ?)`
+
+	_, err := db.Exec(query,
+		incident.ID,
+		incident.MonitorID,
+		incident.Status,
+		incident.Cause,
+		incident.StartedAt.UTC(),
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving incident to db: %w", err)
+	}
+
+	log.Printf("Incident %s opened for monitor %s", incident.ID, incident.MonitorID)
+	return nil
+}
+
+/*
+Function to mark an open incident as resolved
+*/
+func ResolveIncident(id string, resolvedAt time.Time) error {
+	span := tracer.StartSpan("db.resolve_incident",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE incidents SET status = resolved"),
+	)
+	defer span.Finish()
+
+	query := `UPDATE incidents SET status = ?, resolved_at = ? WHERE id = ? AND status = ?`
+
+	_, err := db.Exec(query, models.IncidentResolved, resolvedAt.UTC(), id, models.IncidentOpen)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error resolving incident: %w", err)
+	}
+
+	log.Printf("Incident %s resolved", id)
+	return nil
+}
+
+/*
+Function to get the open incident for a monitor, nil if the monitor is healthy
+*/
+func GetOpenIncident(monitorID string) (*models.Incident, error) {
+	span := tracer.StartSpan("db.get_open_incident",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM incidents WHERE monitor_id = ? AND status = open"),
+	)
+	defer span.Finish()
+
+	query := `
+    SELECT ` + incidentColumns + `
+    FROM incidents
+    WHERE monitor_id = ? AND status = ?
+    ORDER BY started_at DESC
+    LIMIT 1`
+
+	incident, err := scanIncident(db.QueryRow(query, monitorID, models.IncidentOpen))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for open incident: %w", err)
+	}
+
+	return &incident, nil
+}
+
+// IncidentFilter narrows an incident listing. Empty fields mean "no filter".
+type IncidentFilter struct {
+	MonitorID   string
+	Status      string
+	ActiveSince time.Time // <- Incidents that were open at any point after this time
+	Limit       int
+	Tenant      Tenant // <- Only incidents of the tenant's monitors
+}
+
+/*
+Function to list incidents, most recent first
+*/
+func GetIncidents(filter IncidentFilter) ([]models.Incident, error) {
+	span := tracer.StartSpan("db.get_incidents",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM incidents ORDER BY started_at DESC"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + incidentColumns + ` FROM incidents WHERE 1 = 1`
+	var args []any
+
+	if filter.MonitorID != "" {
+		query += ` AND monitor_id = ?`
+		args = append(args, filter.MonitorID)
+	}
+	if filter.Tenant.scoped {
+		condition, tenantArgs := filter.Tenant.where("team_id")
+		query += ` AND monitor_id IN (SELECT id FROM monitors WHERE ` + condition + `)`
+		args = append(args, tenantArgs...)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if !filter.ActiveSince.IsZero() {
+		query += ` AND (status = ? OR started_at >= ? OR resolved_at >= ?)`
+		since := filter.ActiveSince.UTC()
+		args = append(args, models.IncidentOpen, since, since)
+	}
+
+	query += ` ORDER BY started_at DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []models.Incident{}
+
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning incident: %w", err)
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through incidents: %w", err)
+	}
+
+	return incidents, nil
+}
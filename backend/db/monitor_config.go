@@ -0,0 +1,240 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/KerlynD/URL-Monitor/backend/secrets"
+)
+
+// Columns holding how a monitor is checked, after the core monitor columns.
+// monitorConfigRow and monitorConfigArgs keep to this order.
+var monitorConfigFields = []string{"assertions", "method", "headers", "body", "content_type", "auth",
+	"cert_warning_days", "type", "dns", "grpc", "heartbeat", "ping_token", "retries", "retry_delay", "confirm_failures",
+	"timeout", "follow_redirects", "max_redirects", "ignore_tls_errors", "max_body_bytes"}
+
+// monitorConfigRow receives the raw config columns of one monitor row
+type monitorConfigRow struct {
+	assertions  sql.NullString
+	method      sql.NullString
+	headers     sql.NullString
+	body        sql.NullString
+	contentType sql.NullString
+	auth        sql.NullString // <- Sealed with the secrets key
+	certWarning sql.NullInt64
+	monitorType sql.NullString
+	dns         sql.NullString
+	grpc        sql.NullString
+	heartbeat   sql.NullString
+	pingToken   sql.NullString
+	retries     sql.NullInt64
+	retryDelay  sql.NullInt64
+	confirm     sql.NullBool
+	timeout     sql.NullInt64
+	follow      sql.NullBool // <- NULL follows redirects
+	maxRedirect sql.NullInt64
+	ignoreTLS   sql.NullBool
+	maxBody     sql.NullInt64
+}
+
+func (r *monitorConfigRow) dest() []any {
+	return []any{&r.assertions, &r.method, &r.headers, &r.body, &r.contentType, &r.auth, &r.certWarning, &r.monitorType, &r.dns, &r.grpc, &r.heartbeat,
+		&r.pingToken, &r.retries, &r.retryDelay, &r.confirm, &r.timeout, &r.follow, &r.maxRedirect, &r.ignoreTLS, &r.maxBody}
+}
+
+/*
+Function to decode the config columns onto a monitor, decrypting its credentials
+*/
+func (r *monitorConfigRow) apply(monitor *models.MonitorEntry) error {
+	err := decodeJSONColumn(r.assertions, &monitor.Assertions)
+	if err != nil {
+		return fmt.Errorf("error decoding assertions: %w", err)
+	}
+
+	err = decodeJSONColumn(r.headers, &monitor.Headers)
+	if err != nil {
+		return fmt.Errorf("error decoding headers: %w", err)
+	}
+
+	err = decodeJSONColumn(r.dns, &monitor.DNS)
+	if err != nil {
+		return fmt.Errorf("error decoding dns config: %w", err)
+	}
+
+	err = decodeJSONColumn(r.grpc, &monitor.GRPC)
+	if err != nil {
+		return fmt.Errorf("error decoding grpc config: %w", err)
+	}
+
+	err = decodeJSONColumn(r.heartbeat, &monitor.Heartbeat)
+	if err != nil {
+		return fmt.Errorf("error decoding heartbeat config: %w", err)
+	}
+
+	monitor.Method = r.method.String
+	monitor.Body = r.body.String
+	monitor.ContentType = r.contentType.String
+	monitor.CertWarningDays = int(r.certWarning.Int64)
+	monitor.PingToken = r.pingToken.String
+	monitor.Retries = int(r.retries.Int64)
+	monitor.RetryDelay = int(r.retryDelay.Int64)
+	monitor.ConfirmFailures = r.confirm.Bool
+	monitor.Timeout = int(r.timeout.Int64)
+	monitor.MaxRedirects = int(r.maxRedirect.Int64)
+	monitor.IgnoreTLSErrors = r.ignoreTLS.Bool
+	monitor.MaxBodyBytes = r.maxBody.Int64
+	if r.follow.Valid {
+		monitor.FollowRedirects = &r.follow.Bool
+	}
+	monitor.Type = typeOrDefault(r.monitorType.String)
+
+	/*
+		Credentials that can't be read, e.g. after the secrets key changed, only fail
+		this monitor's checks rather than every query that lists monitors
+	*/
+	if r.auth.Valid && r.auth.String != "" {
+		auth, err := openAuth(r.auth.String)
+		if err != nil {
+			log.Printf("Monitor %s has unreadable credentials: %v", monitor.ID, err)
+			monitor.ConfigError = "stored credentials can't be decrypted, set auth again"
+		}
+		monitor.Auth = auth
+	}
+
+	return nil
+}
+
+// openAuth decrypts and decodes a monitor's sealed credentials
+func openAuth(sealed string) (*models.RequestAuth, error) {
+	plaintext, err := secrets.Open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting credentials: %w", err)
+	}
+
+	auth := &models.RequestAuth{}
+	err = json.Unmarshal(plaintext, auth)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding credentials: %w", err)
+	}
+
+	return auth, nil
+}
+
+/*
+Function to encode a monitor's config into values for monitorConfigFields
+*/
+func monitorConfigArgs(entry models.MonitorEntry) ([]any, error) {
+	assertions, err := encodeJSONColumn(entry.Assertions, len(entry.Assertions) == 0)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding assertions: %w", err)
+	}
+
+	headers, err := encodeJSONColumn(entry.Headers, len(entry.Headers) == 0)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding headers: %w", err)
+	}
+
+	dns, err := encodeJSONColumn(entry.DNS, entry.DNS == nil)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding dns config: %w", err)
+	}
+
+	grpc, err := encodeJSONColumn(entry.GRPC, entry.GRPC == nil)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding grpc config: %w", err)
+	}
+
+	heartbeat, err := encodeJSONColumn(entry.Heartbeat, entry.Heartbeat == nil)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding heartbeat config: %w", err)
+	}
+
+	var auth sql.NullString
+	if entry.Auth != nil {
+		plaintext, err := json.Marshal(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding credentials: %w", err)
+		}
+
+		sealed, err := secrets.Seal(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("error encrypting credentials: %w", err)
+		}
+		auth = sql.NullString{String: sealed, Valid: true}
+	}
+
+	var follow sql.NullBool
+	if entry.FollowRedirects != nil {
+		follow = sql.NullBool{Bool: *entry.FollowRedirects, Valid: true}
+	}
+
+	return []any{
+		assertions,
+		nullString(entry.Method),
+		headers,
+		nullString(entry.Body),
+		nullString(entry.ContentType),
+		auth,
+		entry.CertWarningDays,
+		typeOrDefault(entry.Type),
+		dns,
+		grpc,
+		heartbeat,
+		nullString(entry.PingToken), // <- NULL, not "", so the unique index allows many
+		entry.Retries,
+		entry.RetryDelay,
+		entry.ConfirmFailures,
+		entry.Timeout,
+		follow,
+		entry.MaxRedirects,
+		entry.IgnoreTLSErrors,
+		entry.MaxBodyBytes,
+	}, nil
+}
+
+// monitorConfigAssignments is the "field = ?, ..." list for updating the config columns
+func monitorConfigAssignments() string {
+	assignments := make([]string, len(monitorConfigFields))
+	for i, field := range monitorConfigFields {
+		assignments[i] = field + " = ?"
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// typeOrDefault treats an unset monitor type as http, the only type before types existed
+func typeOrDefault(monitorType string) string {
+	if monitorType == "" {
+		return models.MonitorHTTP
+	}
+	return monitorType
+}
+
+// encodeJSONColumn stores a value as JSON, NULL when empty
+func encodeJSONColumn(value any, empty bool) (sql.NullString, error) {
+	if empty {
+		return sql.NullString{}, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// decodeJSONColumn leaves target untouched for NULL columns
+func decodeJSONColumn(column sql.NullString, target any) error {
+	if !column.Valid || column.String == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(column.String), target)
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -4,11 +4,69 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/KerlynD/URL-Monitor/backend/models"
 	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
+// Columns selected for every monitor read, in the order scanMonitor expects
+var monitorColumns = `id, team_id, url, check_interval, created_at, updated_at, last_check_at, next_check_at, paused, failure_threshold, recovery_threshold, ping_started_at, ` +
+	strings.Join(monitorConfigFields, ", ")
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+/*
+Function to scan a row selected with monitorColumns into a MonitorEntry
+*/
+func scanMonitor(row rowScanner) (models.MonitorEntry, error) {
+	var monitor models.MonitorEntry
+	var lastCheckAt, nextCheckAt, pingStartedAt sql.NullTime
+	var config monitorConfigRow
+
+	dest := []any{
+		&monitor.ID,
+		&monitor.TeamID,
+		&monitor.URL,
+		&monitor.CheckInterval,
+		&monitor.CreatedAt,
+		&monitor.UpdatedAt,
+		&lastCheckAt,
+		&nextCheckAt,
+		&monitor.Paused,
+		&monitor.FailureThreshold,
+		&monitor.RecoveryThreshold,
+		&pingStartedAt,
+	}
+
+	err := row.Scan(append(dest, config.dest()...)...) // <- Scans row to make sure struct fields match & puts into fields
+	if err != nil {
+		return models.MonitorEntry{}, err
+	}
+
+	if lastCheckAt.Valid {
+		monitor.LastCheckAt = &lastCheckAt.Time
+	}
+	if nextCheckAt.Valid {
+		monitor.NextCheckAt = &nextCheckAt.Time
+	}
+	if pingStartedAt.Valid {
+		monitor.PingStartedAt = &pingStartedAt.Time
+	}
+
+	err = config.apply(&monitor)
+	if err != nil {
+		return models.MonitorEntry{}, err
+	}
+
+	return monitor, nil
+}
+
 /*
 Function to save monitor into sqlite DB
 */
@@ -20,17 +78,31 @@ func SaveMonitor(entry models.MonitorEntry) error {
 	defer span.Finish()
 
 	query := `
-	INSERT OR REPLACE INTO monitors (id, url, check_interval, created_at, updated_at)
-    VALUES (?, ?, ?, ?, ?)
+	INSERT OR REPLACE INTO monitors (id, team_id, url, check_interval, created_at, updated_at, paused,
+        failure_threshold, recovery_threshold, ` + strings.Join(monitorConfigFields, ", ") + `)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?` + strings.Repeat(", ?", len(monitorConfigFields)) + `)
 	`
 
-	_, err := db.Exec(query,
+	config, err := monitorConfigArgs(entry)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return err
+	}
+
+	args := []any{
 		entry.ID,
+		teamOrDefault(entry.TeamID),
 		entry.URL,
 		entry.CheckInterval,
 		entry.CreatedAt,
 		entry.UpdatedAt,
-	)
+		entry.Paused,
+		thresholdOrDefault(entry.FailureThreshold),
+		thresholdOrDefault(entry.RecoveryThreshold),
+	}
+
+	_, err = db.Exec(query, append(args, config...)...)
 
 	if err != nil {
 		span.SetTag("error", true)
@@ -43,30 +115,23 @@ func SaveMonitor(entry models.MonitorEntry) error {
 }
 
 /*
-Function to get a single monitor from the DB
+Function to get a single monitor of the tenant's from the DB
 */
-func GetMonitor(id string) (models.MonitorEntry, error) {
+func GetMonitor(tenant Tenant, id string) (models.MonitorEntry, error) {
 	span := tracer.StartSpan("db.get_monitor",
 		tracer.SpanType("sql"),
 		tracer.ResourceName("SELECT * FROM monitors WHERE id = ?"),
 	)
 	defer span.Finish()
 
-	query := `SELECT id, url, check_interval, created_at, updated_at 
+	condition, args := tenant.where("team_id")
+	query := `SELECT ` + monitorColumns + `
               FROM monitors 
-              WHERE id = ?`
+              WHERE id = ? AND ` + condition
 
-	var monitor models.MonitorEntry
+	row := db.QueryRow(query, append([]any{id}, args...)...)
 
-	row := db.QueryRow(query, id)
-
-	err := row.Scan( // <- Scans row to make sure struct fields match & puts into fields
-		&monitor.ID,
-		&monitor.URL,
-		&monitor.CheckInterval,
-		&monitor.CreatedAt,
-		&monitor.UpdatedAt,
-	)
+	monitor, err := scanMonitor(row)
 
 	if err == sql.ErrNoRows || err != nil {
 		span.SetTag("error", true)
@@ -78,19 +143,45 @@ func GetMonitor(id string) (models.MonitorEntry, error) {
 }
 
 /*
-Function to get all current URLs we are monitoring
+Function to get the heartbeat monitor a ping token belongs to
 */
-func GetAllMonitors() ([]models.MonitorEntry, error) {
+func GetMonitorByPingToken(token string) (models.MonitorEntry, error) {
+	span := tracer.StartSpan("db.get_monitor_by_ping_token",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM monitors WHERE ping_token = ?"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + monitorColumns + `
+              FROM monitors
+              WHERE ping_token = ?`
+
+	monitor, err := scanMonitor(db.QueryRow(query, token))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorEntry{}, fmt.Errorf("error querying db for ping token: %w", err)
+	}
+
+	return monitor, nil
+}
+
+/*
+Function to get all current URLs the tenant is monitoring
+*/
+func GetAllMonitors(tenant Tenant) ([]models.MonitorEntry, error) {
 	span := tracer.StartSpan("db.get_all_monitors",
 		tracer.SpanType("sql"),
 		tracer.ResourceName("SELECT * FROM monitors"),
 	)
 	defer span.Finish()
 
-	query := `SELECT id, url, check_interval, created_at, updated_at 
-              FROM monitors`
+	condition, args := tenant.where("team_id")
+	query := `SELECT ` + monitorColumns + `
+              FROM monitors
+              WHERE ` + condition
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
@@ -101,16 +192,7 @@ func GetAllMonitors() ([]models.MonitorEntry, error) {
 	var monitors []models.MonitorEntry
 
 	for rows.Next() {
-		var monitor models.MonitorEntry
-
-		err := rows.Scan(
-			&monitor.ID,
-			&monitor.URL,
-			&monitor.CheckInterval,
-			&monitor.CreatedAt,
-			&monitor.UpdatedAt,
-		)
-
+		monitor, err := scanMonitor(rows)
 		if err != nil {
 			span.SetTag("error", true)
 			span.SetTag("error.message", err.Error())
@@ -129,3 +211,194 @@ func GetAllMonitors() ([]models.MonitorEntry, error) {
 	log.Printf("Monitors retrieved successfully")
 	return monitors, nil
 }
+
+/*
+Function to record when the worker last checked a monitor and when it is next due
+*/
+func UpdateMonitorSchedule(id string, lastCheckAt time.Time, nextCheckAt time.Time) error {
+	span := tracer.StartSpan("db.update_monitor_schedule",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE monitors SET last_check_at, next_check_at"),
+	)
+	defer span.Finish()
+
+	query := `UPDATE monitors SET last_check_at = ?, next_check_at = ? WHERE id = ?`
+
+	_, err := db.Exec(query, lastCheckAt, nextCheckAt, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating monitor schedule: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Function to record when a heartbeat monitor's job said it started, nil once it finished
+*/
+func SetPingStarted(id string, startedAt *time.Time) error {
+	span := tracer.StartSpan("db.set_ping_started",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE monitors SET ping_started_at"),
+	)
+	defer span.Finish()
+
+	var value sql.NullTime
+	if startedAt != nil {
+		value = sql.NullTime{Time: startedAt.UTC(), Valid: true}
+	}
+
+	_, err := db.Exec(`UPDATE monitors SET ping_started_at = ? WHERE id = ?`, value, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating ping start: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Function to update the editable fields of an existing monitor
+*/
+func UpdateMonitor(entry models.MonitorEntry) error {
+	span := tracer.StartSpan("db.update_monitor",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE monitors"),
+	)
+	defer span.Finish()
+
+	query := `
+	UPDATE monitors
+	SET url = ?, check_interval = ?, paused = ?, failure_threshold = ?, recovery_threshold = ?,
+	    updated_at = ?, ` + monitorConfigAssignments() + `
+	WHERE id = ?`
+
+	config, err := monitorConfigArgs(entry)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return err
+	}
+
+	// Credentials that couldn't be decrypted are kept as they are until new ones are set
+	if entry.Auth == nil && entry.ConfigError != "" {
+		err = db.QueryRow(`SELECT auth FROM monitors WHERE id = ?`, entry.ID).Scan(&config[slices.Index(monitorConfigFields, "auth")])
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return fmt.Errorf("error reading stored credentials: %w", err)
+		}
+	}
+
+	args := []any{
+		entry.URL,
+		entry.CheckInterval,
+		entry.Paused,
+		thresholdOrDefault(entry.FailureThreshold),
+		thresholdOrDefault(entry.RecoveryThreshold),
+		entry.UpdatedAt,
+	}
+	args = append(args, config...)
+
+	_, err = db.Exec(query, append(args, entry.ID)...)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating monitor: %w", err)
+	}
+
+	log.Printf("Monitor %s updated successfully", entry.ID)
+	return nil
+}
+
+/*
+Function to delete a monitor along with all of its results
+*/
+func DeleteMonitor(id string) error {
+	span := tracer.StartSpan("db.delete_monitor",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("DELETE FROM monitors"),
+	)
+	defer span.Finish()
+
+	tx, err := db.Begin()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error starting delete transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Results, incidents and their deliveries reference the monitor, so they go first
+	_, err = tx.Exec(`DELETE FROM results WHERE monitor_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting results for monitor: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM notification_deliveries WHERE monitor_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting notification deliveries for monitor: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM incidents WHERE monitor_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting incidents for monitor: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM monitors WHERE id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting monitor: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error committing monitor delete: %w", err)
+	}
+
+	log.Printf("Monitor %s deleted successfully", id)
+	return nil
+}
+
+/*
+Function to pause or resume checks for a monitor
+*/
+func SetMonitorPaused(id string, paused bool, updatedAt time.Time) error {
+	span := tracer.StartSpan("db.set_monitor_paused",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE monitors SET paused"),
+	)
+	defer span.Finish()
+
+	query := `UPDATE monitors SET paused = ?, updated_at = ? WHERE id = ?`
+
+	_, err := db.Exec(query, paused, updatedAt, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating monitor paused state: %w", err)
+	}
+
+	log.Printf("Monitor %s paused=%t", id, paused)
+	return nil
+}
+
+// thresholdOrDefault treats an unset incident threshold as 1 (a single check)
+func thresholdOrDefault(threshold int) int {
+	if threshold < 1 {
+		return 1
+	}
+	return threshold
+}
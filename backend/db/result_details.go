@@ -0,0 +1,167 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Columns holding what a check found beyond up/down, after the core result columns.
+// resultDetailRow and resultDetailArgs keep to this order. Phase timings are stored in
+// microseconds, since DNS and connect are often under a millisecond.
+var resultDetailFields = []string{
+	"dns_lookup_us", "tcp_connect_us", "tls_handshake_us", "ttfb_us", "content_transfer_us",
+	"cert_subject", "cert_issuer", "cert_sans", "cert_not_after", "cert_days_remaining",
+	"failure_reason", "warning", "dns_records", "grpc_status", "attempts",
+	"final_url", "redirect_chain", "run_time_ms",
+}
+
+// resultDetailRow receives the raw detail columns of one result row
+type resultDetailRow struct {
+	dnsLookup         sql.NullInt64
+	tcpConnect        sql.NullInt64
+	tlsHandshake      sql.NullInt64
+	ttfb              sql.NullInt64
+	contentTransfer   sql.NullInt64
+	certSubject       sql.NullString
+	certIssuer        sql.NullString
+	certSANs          sql.NullString
+	certNotAfter      sql.NullTime
+	certDaysRemaining sql.NullInt64
+	failureReason     sql.NullString
+	warning           sql.NullString
+	dnsRecords        sql.NullString
+	grpcStatus        sql.NullString
+	attempts          sql.NullString
+	finalURL          sql.NullString
+	redirectChain     sql.NullString
+	runTime           sql.NullInt64
+}
+
+func (r *resultDetailRow) dest() []any {
+	return []any{
+		&r.dnsLookup, &r.tcpConnect, &r.tlsHandshake, &r.ttfb, &r.contentTransfer,
+		&r.certSubject, &r.certIssuer, &r.certSANs, &r.certNotAfter, &r.certDaysRemaining,
+		&r.failureReason, &r.warning, &r.dnsRecords, &r.grpcStatus, &r.attempts,
+		&r.finalURL, &r.redirectChain, &r.runTime,
+	}
+}
+
+/*
+Function to decode the detail columns onto a result
+*/
+func (r *resultDetailRow) apply(result *models.MonitorResult) error {
+	// Results from before timings were recorded have none
+	if r.ttfb.Valid {
+		result.Timings = &models.CheckTimings{
+			DNSLookup:       time.Duration(r.dnsLookup.Int64) * time.Microsecond,
+			TCPConnect:      time.Duration(r.tcpConnect.Int64) * time.Microsecond,
+			TLSHandshake:    time.Duration(r.tlsHandshake.Int64) * time.Microsecond,
+			TimeToFirstByte: time.Duration(r.ttfb.Int64) * time.Microsecond,
+			ContentTransfer: time.Duration(r.contentTransfer.Int64) * time.Microsecond,
+		}
+	}
+
+	if r.certNotAfter.Valid {
+		result.Certificate = &models.CertificateInfo{
+			Subject:       r.certSubject.String,
+			Issuer:        r.certIssuer.String,
+			NotAfter:      r.certNotAfter.Time,
+			DaysRemaining: int(r.certDaysRemaining.Int64),
+		}
+
+		err := decodeJSONColumn(r.certSANs, &result.Certificate.SANs)
+		if err != nil {
+			return fmt.Errorf("error decoding certificate SANs: %w", err)
+		}
+	}
+
+	err := decodeJSONColumn(r.dnsRecords, &result.Records)
+	if err != nil {
+		return fmt.Errorf("error decoding dns records: %w", err)
+	}
+
+	err = decodeJSONColumn(r.attempts, &result.Attempts)
+	if err != nil {
+		return fmt.Errorf("error decoding attempts: %w", err)
+	}
+
+	err = decodeJSONColumn(r.redirectChain, &result.RedirectChain)
+	if err != nil {
+		return fmt.Errorf("error decoding redirect chain: %w", err)
+	}
+
+	result.FinalURL = r.finalURL.String
+	result.FailureReason = r.failureReason.String
+	result.Warning = r.warning.String
+	result.GRPCStatus = r.grpcStatus.String
+	result.RunTime = time.Duration(r.runTime.Int64) * time.Millisecond
+	return nil
+}
+
+/*
+Function to encode a result's details into values for resultDetailFields
+*/
+func resultDetailArgs(result models.MonitorResult) ([]any, error) {
+	var dnsLookup, tcpConnect, tlsHandshake, ttfb, contentTransfer sql.NullInt64
+	if result.Timings != nil {
+		dnsLookup = sql.NullInt64{Int64: result.Timings.DNSLookup.Microseconds(), Valid: true}
+		tcpConnect = sql.NullInt64{Int64: result.Timings.TCPConnect.Microseconds(), Valid: true}
+		tlsHandshake = sql.NullInt64{Int64: result.Timings.TLSHandshake.Microseconds(), Valid: true}
+		ttfb = sql.NullInt64{Int64: result.Timings.TimeToFirstByte.Microseconds(), Valid: true}
+		contentTransfer = sql.NullInt64{Int64: result.Timings.ContentTransfer.Microseconds(), Valid: true}
+	}
+
+	var certSubject, certIssuer, certSANs sql.NullString
+	var certNotAfter sql.NullTime
+	var certDaysRemaining sql.NullInt64
+	if result.Certificate != nil {
+		var err error
+		certSANs, err = encodeJSONColumn(result.Certificate.SANs, len(result.Certificate.SANs) == 0)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding certificate SANs: %w", err)
+		}
+
+		certSubject = nullString(result.Certificate.Subject)
+		certIssuer = nullString(result.Certificate.Issuer)
+		certNotAfter = sql.NullTime{Time: result.Certificate.NotAfter.UTC(), Valid: true}
+		certDaysRemaining = sql.NullInt64{Int64: int64(result.Certificate.DaysRemaining), Valid: true}
+	}
+
+	// An empty answer set is still an answer, so only a missing one is NULL
+	dnsRecords, err := encodeJSONColumn(result.Records, result.Records == nil)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding dns records: %w", err)
+	}
+
+	attempts, err := encodeJSONColumn(result.Attempts, len(result.Attempts) == 0)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding attempts: %w", err)
+	}
+
+	redirectChain, err := encodeJSONColumn(result.RedirectChain, len(result.RedirectChain) == 0)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding redirect chain: %w", err)
+	}
+
+	var runTime sql.NullInt64
+	if result.RunTime > 0 {
+		runTime = sql.NullInt64{Int64: result.RunTime.Milliseconds(), Valid: true}
+	}
+
+	return []any{
+		dnsLookup, tcpConnect, tlsHandshake, ttfb, contentTransfer,
+		certSubject, certIssuer, certSANs, certNotAfter, certDaysRemaining,
+		nullString(result.FailureReason), nullString(result.Warning), dnsRecords,
+		nullString(result.GRPCStatus), attempts,
+		nullString(result.FinalURL), redirectChain, runTime,
+	}, nil
+}
+
+// resultDetailColumns is the comma separated resultDetailFields for queries
+func resultDetailColumns() string {
+	return strings.Join(resultDetailFields, ", ")
+}
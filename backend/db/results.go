@@ -4,12 +4,48 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/KerlynD/URL-Monitor/backend/models"
 	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
+// Columns selected for every result read, in the order scanResult expects
+var resultColumns = `id, status_code, response_time, is_up, error, timestamp, ` + resultDetailColumns()
+
+/*
+Function to scan a row selected with resultColumns into a MonitorResult
+*/
+func scanResult(row rowScanner) (models.MonitorResult, error) {
+	var result models.MonitorResult
+	var responseTimeMs int64
+	var details resultDetailRow
+
+	dest := []any{
+		&result.ID,
+		&result.StatusCode,
+		&responseTimeMs,
+		&result.IsUp,
+		&result.Error,
+		&result.Timestamp,
+	}
+
+	err := row.Scan(append(dest, details.dest()...)...)
+	if err != nil {
+		return models.MonitorResult{}, err
+	}
+
+	result.ResponseTime = time.Duration(responseTimeMs) * time.Millisecond
+
+	err = details.apply(&result)
+	if err != nil {
+		return models.MonitorResult{}, err
+	}
+
+	return result, nil
+}
+
 /*
 Function to save result into sqlite DB
 */
@@ -21,17 +57,27 @@ func SaveResult(monitorID string, result models.MonitorResult) error {
 	defer span.Finish()
 
 	query := `
-    INSERT INTO results (monitor_id, status_code, response_time, is_up, error, timestamp)
-    VALUES (?, ?, ?, ?, ?, ?)`
+    INSERT INTO results (monitor_id, status_code, response_time, is_up, error, timestamp,
+        ` + resultDetailColumns() + `)
+    VALUES (?, ?, ?, ?, ?, ?` + strings.Repeat(", ?", len(resultDetailFields)) + `)`
 
-	_, err := db.Exec(query,
+	details, err := resultDetailArgs(result)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return err
+	}
+
+	args := []any{
 		monitorID,
 		result.StatusCode,
 		result.ResponseTime.Milliseconds(),
 		result.IsUp,
 		result.Error,
-		result.Timestamp,
-	)
+		result.Timestamp.UTC(), // <- UTC so timestamps compare correctly as text
+	}
+
+	_, err = db.Exec(query, append(args, details...)...)
 
 	if err != nil {
 		span.SetTag("error", true)
@@ -51,24 +97,15 @@ func GetLatestResult(monitorID string) (models.MonitorResult, error) {
 	defer span.Finish()
 
 	query := `
-    SELECT status_code, response_time, is_up, error, timestamp
+    SELECT ` + resultColumns + `
     FROM results
     WHERE monitor_id = ?
-    ORDER BY timestamp DESC
+    ORDER BY timestamp DESC, id DESC
     LIMIT 1`
 
-	var result models.MonitorResult
-	var responseTimeMs int64
-
 	row := db.QueryRow(query, monitorID)
 
-	err := row.Scan(
-		&result.StatusCode,
-		&responseTimeMs,
-		&result.IsUp,
-		&result.Error,
-		&result.Timestamp,
-	)
+	result, err := scanResult(row)
 
 	if err == sql.ErrNoRows || err != nil {
 		span.SetTag("error", true)
@@ -76,8 +113,198 @@ func GetLatestResult(monitorID string) (models.MonitorResult, error) {
 		return models.MonitorResult{}, fmt.Errorf("error querying db for latest result: %w", err)
 	}
 
-	result.ResponseTime = time.Duration(responseTimeMs) * time.Millisecond
-
 	log.Printf("Latest result %s retrieved successfully", monitorID)
 	return result, nil
 }
+
+/*
+Function to get the DNS answer set a monitor last accepted: the newest result with
+an answer that wasn't failed as a change. Returns nil when there is none yet.
+*/
+func GetAcceptedRecords(monitorID string) ([]string, error) {
+	span := tracer.StartSpan("db.get_accepted_records",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT dns_records FROM results WHERE monitor_id = ? AND failure_reason != ? ORDER BY timestamp DESC LIMIT 1"),
+	)
+	defer span.Finish()
+
+	query := `
+    SELECT dns_records
+    FROM results
+    WHERE monitor_id = ? AND dns_records IS NOT NULL AND COALESCE(failure_reason, '') != ?
+    ORDER BY timestamp DESC, id DESC
+    LIMIT 1`
+
+	var column sql.NullString
+	err := db.QueryRow(query, monitorID, models.FailureRecordsChanged).Scan(&column)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for accepted records: %w", err)
+	}
+
+	records := []string{}
+	err = decodeJSONColumn(column, &records)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding dns records: %w", err)
+	}
+
+	return records, nil
+}
+
+/*
+Function to get the DNS answer sets of a monitor's last limit results that have
+one, newest first, each with the result's failure reason
+*/
+func GetRecentRecords(monitorID string, limit int) ([]models.MonitorResult, error) {
+	span := tracer.StartSpan("db.get_recent_records",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT dns_records, failure_reason FROM results WHERE monitor_id = ? ORDER BY timestamp DESC LIMIT ?"),
+	)
+	defer span.Finish()
+
+	query := `
+    SELECT dns_records, COALESCE(failure_reason, '')
+    FROM results
+    WHERE monitor_id = ? AND dns_records IS NOT NULL
+    ORDER BY timestamp DESC, id DESC
+    LIMIT ?`
+
+	rows, err := db.Query(query, monitorID, limit)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for recent records: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.MonitorResult
+
+	for rows.Next() {
+		var column sql.NullString
+		var result models.MonitorResult
+
+		err := rows.Scan(&column, &result.FailureReason)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning recent records: %w", err)
+		}
+
+		result.Records = []string{}
+		err = decodeJSONColumn(column, &result.Records)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding dns records: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through recent records: %w", err)
+	}
+
+	return results, nil
+}
+
+// ResultCursor marks the last row of a page; the next page starts strictly after it
+type ResultCursor struct {
+	Timestamp time.Time
+	ID        int64
+}
+
+// ResultFilter narrows a history query. Zero values mean "no filter".
+type ResultFilter struct {
+	From      time.Time
+	To        time.Time
+	IsUp      *bool
+	StatusMin int
+	StatusMax int
+	Limit     int
+	Cursor    *ResultCursor
+}
+
+/*
+Function to get a page of results for a monitor, newest first
+*/
+func GetResults(monitorID string, filter ResultFilter) ([]models.MonitorResult, error) {
+	/*
+		Results are ordered by (timestamp, id) descending so the cursor is stable even
+		when two checks share a timestamp. Filters are only added when set.
+	*/
+	span := tracer.StartSpan("db.get_results",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM results WHERE monitor_id = ? ORDER BY timestamp DESC, id DESC"),
+	)
+	defer span.Finish()
+
+	query := `SELECT ` + resultColumns + ` FROM results WHERE monitor_id = ?`
+	args := []any{monitorID}
+
+	if !filter.From.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query += ` AND timestamp <= ?`
+		args = append(args, filter.To.UTC())
+	}
+	if filter.IsUp != nil {
+		query += ` AND is_up = ?`
+		args = append(args, *filter.IsUp)
+	}
+	if filter.StatusMin > 0 {
+		query += ` AND status_code >= ?`
+		args = append(args, filter.StatusMin)
+	}
+	if filter.StatusMax > 0 {
+		query += ` AND status_code <= ?`
+		args = append(args, filter.StatusMax)
+	}
+	if filter.Cursor != nil {
+		cursorTime := filter.Cursor.Timestamp.UTC()
+		query += ` AND (timestamp < ? OR (timestamp = ? AND id < ?))`
+		args = append(args, cursorTime, cursorTime, filter.Cursor.ID)
+	}
+
+	query += ` ORDER BY timestamp DESC, id DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.MonitorResult{}
+
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning result: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through results: %w", err)
+	}
+
+	return results, nil
+}
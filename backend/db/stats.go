@@ -0,0 +1,206 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to compute uptime and latency statistics for a monitor between from and to
+*/
+func GetMonitorStats(monitorID string, from time.Time, to time.Time) (models.MonitorStats, error) {
+	/*
+		SQLite has no percentile functions, so this reads the window's results in time
+		order (oldest first) and does the maths in Go. Only the columns the stats
+		need are read, and only response times are kept, for the percentiles.
+	*/
+	span := tracer.StartSpan("db.get_monitor_stats",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT timestamp, is_up, response_time FROM results WHERE monitor_id = ? AND timestamp BETWEEN ? AND ?"),
+	)
+	defer span.Finish()
+
+	query := `
+    SELECT timestamp, is_up, response_time
+    FROM results
+    WHERE monitor_id = ? AND timestamp >= ? AND timestamp <= ?
+    ORDER BY timestamp ASC, id ASC`
+
+	rows, err := db.Query(query, monitorID, from.UTC(), to.UTC())
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorStats{}, fmt.Errorf("error querying db for stats: %w", err)
+	}
+	defer rows.Close()
+
+	var builder statsBuilder
+
+	for rows.Next() {
+		var timestamp time.Time
+		var isUp bool
+		var responseTimeMs int64
+
+		err := rows.Scan(&timestamp, &isUp, &responseTimeMs)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return models.MonitorStats{}, fmt.Errorf("error scanning result: %w", err)
+		}
+
+		builder.add(timestamp, isUp, time.Duration(responseTimeMs)*time.Millisecond)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.MonitorStats{}, fmt.Errorf("error iterating through results: %w", err)
+	}
+
+	stats := builder.finish(to)
+	stats.From = from
+	stats.To = to
+
+	return stats, nil
+}
+
+// statsBuilder summarises results fed to it oldest first
+type statsBuilder struct {
+	stats             models.MonitorStats
+	responseTimes     []time.Duration
+	totalResponseTime time.Duration
+	outageStart       *time.Time // <- When the current run of failures began, nil while up
+}
+
+func (b *statsBuilder) add(timestamp time.Time, isUp bool, responseTime time.Duration) {
+	b.stats.TotalChecks++
+	b.responseTimes = append(b.responseTimes, responseTime)
+	b.totalResponseTime += responseTime
+
+	if isUp {
+		b.closeOutage(timestamp)
+		return
+	}
+
+	b.stats.FailedChecks++
+	if b.outageStart == nil {
+		b.outageStart = &timestamp
+	}
+}
+
+// closeOutage records the outage that began at outageStart if it's the longest so far
+func (b *statsBuilder) closeOutage(end time.Time) {
+	if b.outageStart == nil {
+		return
+	}
+
+	if duration := end.Sub(*b.outageStart); duration > b.stats.LongestOutage {
+		b.stats.LongestOutage = duration
+		b.stats.LongestOutageStart = b.outageStart
+	}
+	b.outageStart = nil
+}
+
+// finish computes the stats; to closes an outage still open at the end
+func (b *statsBuilder) finish(to time.Time) models.MonitorStats {
+	stats := &b.stats
+
+	if stats.TotalChecks == 0 {
+		return *stats
+	}
+
+	// Still down at the end of the window
+	b.closeOutage(to)
+
+	sort.Slice(b.responseTimes, func(i, j int) bool { return b.responseTimes[i] < b.responseTimes[j] })
+
+	stats.UptimePercent = float64(stats.TotalChecks-stats.FailedChecks) / float64(stats.TotalChecks) * 100
+	stats.MeanResponseTime = b.totalResponseTime / time.Duration(len(b.responseTimes))
+	stats.P50ResponseTime = percentile(b.responseTimes, 50)
+	stats.P95ResponseTime = percentile(b.responseTimes, 95)
+	stats.P99ResponseTime = percentile(b.responseTimes, 99)
+
+	return *stats
+}
+
+/*
+Function to compute the uptime / p95 summary of every monitor the tenant sees
+between from and to, in one query. Monitors without checks in the window are left
+out of the map.
+*/
+func GetStatsSummaries(tenant Tenant, from time.Time, to time.Time) (map[string]models.StatsSummary, error) {
+	/*
+		Window functions count each monitor's checks and rank its response times, so
+		only the row at the 95th percentile (by nearest rank, as percentile does) of
+		each monitor comes back.
+	*/
+	span := tracer.StartSpan("db.get_stats_summaries",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT monitor_id, total, up, response_time FROM results GROUP BY monitor_id"),
+	)
+	defer span.Finish()
+
+	teamFilter, teamArgs := tenant.where("m.team_id")
+
+	query := `
+    SELECT monitor_id, total, up, response_time FROM (
+        SELECT r.monitor_id, r.response_time,
+            COUNT(*) OVER monitor_results AS total,
+            SUM(r.is_up) OVER monitor_results AS up,
+            ROW_NUMBER() OVER (PARTITION BY r.monitor_id ORDER BY r.response_time) AS position
+        FROM results r
+        JOIN monitors m ON m.id = r.monitor_id
+        WHERE r.timestamp >= ? AND r.timestamp <= ? AND ` + teamFilter + `
+        WINDOW monitor_results AS (PARTITION BY r.monitor_id)
+    )
+    WHERE position = (95 * total + 99) / 100`
+
+	rows, err := db.Query(query, append([]any{from.UTC(), to.UTC()}, teamArgs...)...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for stats summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := map[string]models.StatsSummary{}
+
+	for rows.Next() {
+		var monitorID string
+		var total, up, p95Ms int64
+
+		err := rows.Scan(&monitorID, &total, &up, &p95Ms)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning stats summary: %w", err)
+		}
+
+		summaries[monitorID] = models.StatsSummary{
+			Uptime24h:       float64(up) / float64(total) * 100,
+			P95ResponseTime: time.Duration(p95Ms) * time.Millisecond,
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through stats summaries: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// percentile uses the nearest-rank method on an ascending slice
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to save a new organization
+*/
+func SaveOrganization(org models.Organization) error {
+	_, err := db.Exec(`INSERT INTO organizations (id, name, created_at) VALUES (?, ?, ?)`,
+		org.ID, org.Name, org.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("error saving organization to db: %w", err)
+	}
+
+	log.Printf("Organization %s saved successfully", org.ID)
+	return nil
+}
+
+/*
+Function to get a single organization
+*/
+func GetOrganization(id string) (models.Organization, error) {
+	var org models.Organization
+	err := db.QueryRow(`SELECT id, name, created_at FROM organizations WHERE id = ?`, id).Scan(
+		&org.ID, &org.Name, &org.CreatedAt)
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("error querying db for organization: %w", err)
+	}
+	return org, nil
+}
+
+/*
+Function to list every organization, oldest first
+*/
+func GetOrganizations() ([]models.Organization, error) {
+	rows, err := db.Query(`SELECT id, name, created_at FROM organizations ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying db for organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []models.Organization{}
+
+	for rows.Next() {
+		var org models.Organization
+		err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning organization: %w", err)
+		}
+
+		orgs = append(orgs, org)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+/*
+Function to save a new team
+*/
+func SaveTeam(team models.Team) error {
+	span := tracer.StartSpan("db.save_team",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO teams"),
+	)
+	defer span.Finish()
+
+	_, err := db.Exec(`INSERT INTO teams (id, org_id, name, created_at) VALUES (?, ?, ?, ?)`,
+		team.ID, team.OrgID, team.Name, team.CreatedAt.UTC())
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving team to db: %w", err)
+	}
+
+	log.Printf("Team %s saved successfully", team.ID)
+	return nil
+}
+
+/*
+Function to get a single team the tenant can see
+*/
+func GetTeam(tenant Tenant, id string) (models.Team, error) {
+	condition, args := tenant.where("id")
+
+	var team models.Team
+	err := db.QueryRow(`SELECT id, org_id, name, created_at FROM teams WHERE id = ? AND `+condition,
+		append([]any{id}, args...)...).Scan(&team.ID, &team.OrgID, &team.Name, &team.CreatedAt)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("error querying db for team: %w", err)
+	}
+	return team, nil
+}
+
+/*
+Function to list the teams the tenant can see, by organization then name
+*/
+func GetTeams(tenant Tenant) ([]models.Team, error) {
+	span := tracer.StartSpan("db.get_teams",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM teams"),
+	)
+	defer span.Finish()
+
+	condition, args := tenant.where("id")
+
+	rows, err := db.Query(`SELECT id, org_id, name, created_at FROM teams WHERE `+condition+` ORDER BY org_id, name`, args...)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+
+	for rows.Next() {
+		var team models.Team
+		err := rows.Scan(&team.ID, &team.OrgID, &team.Name, &team.CreatedAt)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning team: %w", err)
+		}
+
+		teams = append(teams, team)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through teams: %w", err)
+	}
+
+	return teams, nil
+}
+
+/*
+Function to add a user to a team. Adding a member twice is not an error.
+*/
+func AddTeamMember(teamID string, userID string, addedAt time.Time) error {
+	_, err := db.Exec(`INSERT OR IGNORE INTO team_members (team_id, user_id, created_at) VALUES (?, ?, ?)`,
+		teamID, userID, addedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("error adding team member: %w", err)
+	}
+	return nil
+}
+
+/*
+Function to remove a user from a team
+*/
+func RemoveTeamMember(teamID string, userID string) error {
+	_, err := db.Exec(`DELETE FROM team_members WHERE team_id = ? AND user_id = ?`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("error removing team member: %w", err)
+	}
+	return nil
+}
+
+/*
+Function to get the IDs of the teams a user is a member of
+*/
+func GetUserTeamIDs(userID string) ([]string, error) {
+	rows, err := db.Query(`SELECT team_id FROM team_members WHERE user_id = ? ORDER BY team_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying db for team members: %w", err)
+	}
+	defer rows.Close()
+
+	teamIDs := []string{}
+
+	for rows.Next() {
+		var teamID string
+		err := rows.Scan(&teamID)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning team member: %w", err)
+		}
+
+		teamIDs = append(teamIDs, teamID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through team members: %w", err)
+	}
+
+	return teamIDs, nil
+}
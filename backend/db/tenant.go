@@ -0,0 +1,64 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Tenant limits a query to the rows owned by some teams. The zero value is
+// unscoped, for the worker and other internal callers that act on every team.
+type Tenant struct {
+	scoped  bool
+	teamIDs []string
+}
+
+// AllTeams is the unscoped tenant
+var AllTeams = Tenant{}
+
+// ForTeams is a tenant that sees only the given teams (none, if there are none)
+func ForTeams(teamIDs ...string) Tenant {
+	return Tenant{scoped: true, teamIDs: teamIDs}
+}
+
+// Includes reports whether the tenant may see rows owned by teamID
+func (t Tenant) Includes(teamID string) bool {
+	if !t.scoped {
+		return true
+	}
+	for _, id := range t.teamIDs {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamIDs is the teams a scoped tenant sees, and false for the unscoped one
+func (t Tenant) TeamIDs() ([]string, bool) {
+	return t.teamIDs, t.scoped
+}
+
+// where is the condition limiting column to the tenant's teams, with its args
+func (t Tenant) where(column string) (string, []any) {
+	if !t.scoped {
+		return "1 = 1", nil
+	}
+	if len(t.teamIDs) == 0 {
+		return "1 = 0", nil
+	}
+
+	args := make([]any, len(t.teamIDs))
+	for i, id := range t.teamIDs {
+		args[i] = id
+	}
+	return column + " IN (?" + strings.Repeat(", ?", len(t.teamIDs)-1) + ")", args
+}
+
+// teamOrDefault files rows saved without a team under the default one
+func teamOrDefault(teamID string) string {
+	if teamID == "" {
+		return models.DefaultTeam
+	}
+	return teamID
+}
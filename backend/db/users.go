@@ -0,0 +1,394 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Columns selected for every user read, in the order scanUser expects
+const userColumns = `id, email, role, created_at, updated_at`
+
+/*
+Function to scan a row selected with userColumns into a User
+*/
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+/*
+Function to save a new user along with their password hash
+*/
+func SaveUser(user models.User, passwordHash string) error {
+	span := tracer.StartSpan("db.save_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO users"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO users (id, email, password_hash, role, created_at, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Exec(query,
+		user.ID,
+		user.Email,
+		passwordHash,
+		user.Role,
+		user.CreatedAt.UTC(),
+		user.UpdatedAt.UTC(),
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving user to db: %w", err)
+	}
+
+	log.Printf("User %s saved successfully", user.ID)
+	return nil
+}
+
+/*
+Function to update a user's role, and their password hash when one is given
+*/
+func UpdateUser(user models.User, passwordHash string) error {
+	span := tracer.StartSpan("db.update_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("UPDATE users"),
+	)
+	defer span.Finish()
+
+	query := `UPDATE users SET role = ?, updated_at = ?, password_hash = COALESCE(?, password_hash) WHERE id = ?`
+
+	_, err := db.Exec(query, user.Role, user.UpdatedAt.UTC(), nullString(passwordHash), user.ID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error updating user: %w", err)
+	}
+
+	log.Printf("User %s updated successfully", user.ID)
+	return nil
+}
+
+/*
+Function to get a single user
+*/
+func GetUser(id string) (models.User, error) {
+	span := tracer.StartSpan("db.get_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM users WHERE id = ?"),
+	)
+	defer span.Finish()
+
+	user, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.User{}, fmt.Errorf("error querying db for user: %w", err)
+	}
+
+	return user, nil
+}
+
+/*
+Function to get a user by email along with their password hash, for logging in
+*/
+func GetUserByEmail(email string) (models.User, string, error) {
+	span := tracer.StartSpan("db.get_user_by_email",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM users WHERE email = ?"),
+	)
+	defer span.Finish()
+
+	var user models.User
+	var passwordHash string
+
+	err := db.QueryRow(`SELECT `+userColumns+`, password_hash FROM users WHERE email = ?`, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&passwordHash,
+	)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.User{}, "", fmt.Errorf("error querying db for user: %w", err)
+	}
+
+	return user, passwordHash, nil
+}
+
+/*
+Function to save a new user who logs in with single sign-on, and so has no password
+*/
+func SaveSSOUser(user models.User, issuer string, subject string) error {
+	span := tracer.StartSpan("db.save_sso_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("INSERT INTO users"),
+	)
+	defer span.Finish()
+
+	query := `
+    INSERT INTO users (id, email, password_hash, role, created_at, updated_at, sso_issuer, sso_subject)
+    VALUES (?, ?, '', ?, ?, ?, ?, ?)`
+
+	_, err := db.Exec(query,
+		user.ID,
+		user.Email,
+		user.Role,
+		user.CreatedAt.UTC(),
+		user.UpdatedAt.UTC(),
+		issuer,
+		subject,
+	)
+
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error saving user to db: %w", err)
+	}
+
+	log.Printf("User %s saved successfully", user.ID)
+	return nil
+}
+
+/*
+Function to get the user linked to a single sign-on identity along with their
+password hash, which is empty for users made by single sign-on
+*/
+func GetUserBySSO(issuer string, subject string) (models.User, string, error) {
+	span := tracer.StartSpan("db.get_user_by_sso",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM users WHERE sso_issuer = ? AND sso_subject = ?"),
+	)
+	defer span.Finish()
+
+	var user models.User
+	var passwordHash string
+
+	err := db.QueryRow(`SELECT `+userColumns+`, password_hash FROM users WHERE sso_issuer = ? AND sso_subject = ?`,
+		issuer, subject).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&passwordHash,
+	)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.User{}, "", fmt.Errorf("error querying db for user: %w", err)
+	}
+
+	return user, passwordHash, nil
+}
+
+/*
+Function to link an existing user to a single sign-on identity
+*/
+func LinkUserSSO(userID string, issuer string, subject string) error {
+	_, err := db.Exec(`UPDATE users SET sso_issuer = ?, sso_subject = ? WHERE id = ?`, issuer, subject, userID)
+	if err != nil {
+		return fmt.Errorf("error linking user to sso identity: %w", err)
+	}
+	return nil
+}
+
+/*
+Function to list every user, oldest first
+*/
+func GetUsers() ([]models.User, error) {
+	span := tracer.StartSpan("db.get_users",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM users"),
+	)
+	defer span.Finish()
+
+	rows, err := db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY created_at`)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error querying db for users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("error iterating through users: %w", err)
+	}
+
+	return users, nil
+}
+
+/*
+Function to count every user
+*/
+func CountUsers() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting users: %w", err)
+	}
+	return count, nil
+}
+
+/*
+Function to count the users with a role
+*/
+func CountUsersWithRole(role string) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE role = ?`, role).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting users: %w", err)
+	}
+	return count, nil
+}
+
+/*
+Function to delete a user along with their team memberships, logging them out everywhere
+*/
+func DeleteUser(id string) error {
+	span := tracer.StartSpan("db.delete_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("DELETE FROM users"),
+	)
+	defer span.Finish()
+
+	tx, err := db.Begin()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error starting delete transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM sessions WHERE user_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting sessions for user: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM team_members WHERE user_id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting team memberships for user: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return fmt.Errorf("error committing user delete: %w", err)
+	}
+
+	log.Printf("User %s deleted successfully", id)
+	return nil
+}
+
+/*
+Function to save a session for a user, keyed by the hash of its token
+*/
+func SaveSession(tokenHash string, userID string, createdAt time.Time, expiresAt time.Time) error {
+	_, err := db.Exec(`INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		tokenHash, userID, createdAt.UTC(), expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	return nil
+}
+
+/*
+Function to get the user a session belongs to, if the session hasn't expired.
+Returns sql.ErrNoRows for unknown and expired sessions.
+*/
+func GetSessionUser(tokenHash string, now time.Time) (models.User, error) {
+	span := tracer.StartSpan("db.get_session_user",
+		tracer.SpanType("sql"),
+		tracer.ResourceName("SELECT * FROM sessions JOIN users"),
+	)
+	defer span.Finish()
+
+	query := `
+    SELECT users.id, users.email, users.role, users.created_at, users.updated_at
+    FROM sessions JOIN users ON users.id = sessions.user_id
+    WHERE sessions.token_hash = ? AND sessions.expires_at > ?`
+
+	user, err := scanUser(db.QueryRow(query, tokenHash, now.UTC()))
+	if err == sql.ErrNoRows {
+		return models.User{}, err
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return models.User{}, fmt.Errorf("error querying db for session: %w", err)
+	}
+
+	return user, nil
+}
+
+/*
+Function to end a session
+*/
+func DeleteSession(tokenHash string) error {
+	_, err := db.Exec(`DELETE FROM sessions WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+/*
+Function to end every session of a user, e.g. after their password changed
+*/
+func DeleteUserSessions(userID string) error {
+	_, err := db.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting sessions for user: %w", err)
+	}
+	return nil
+}
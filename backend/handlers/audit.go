@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+/*
+Function to list audit log entries newest first, optionally filtered by ?actor=,
+?action=, ?target_type=, ?target_id=, ?team_id=, ?from=, ?to= and ?limit=
+*/
+func ListAudit(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_audit")
+	defer span.Finish()
+
+	filter, err := parseAuditFilter(request.URL.Query())
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Tenant = tenantOf(request)
+
+	entries, err := db.GetAuditEntries(filter)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to get audit log")
+		return
+	}
+
+	span.SetTag("audit.count", len(entries))
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(entries)
+}
+
+// parseAuditFilter turns audit log query parameters into a db.AuditFilter
+func parseAuditFilter(query url.Values) (db.AuditFilter, error) {
+	filter := db.AuditFilter{
+		Actor:      query.Get("actor"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		TeamID:     query.Get("team_id"),
+		Limit:      defaultAuditLimit,
+	}
+	var err error
+
+	if from := query.Get("from"); from != "" {
+		filter.Since, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, fmt.Errorf("from must be an RFC 3339 timestamp")
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		filter.Until, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, fmt.Errorf("to must be an RFC 3339 timestamp")
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxAuditLimit)
+		}
+	}
+
+	return filter, nil
+}
+
+// monitorChange names a change to a monitor for audit.Record
+func monitorChange(action string, monitor models.MonitorEntry) models.AuditEntry {
+	return models.AuditEntry{Action: action, TargetType: models.AuditMonitor, TargetID: monitor.ID, TeamID: monitor.TeamID}
+}
+
+// channelChange names a change to a notification channel for audit.Record
+func channelChange(action string, channel models.NotificationChannel) models.AuditEntry {
+	return models.AuditEntry{Action: action, TargetType: models.AuditChannel, TargetID: channel.ID, TeamID: channel.TeamID}
+}
+
+// keyChange names a change to an API key for audit.Record
+func keyChange(action string, key models.APIKey) models.AuditEntry {
+	return models.AuditEntry{Action: action, TargetType: models.AuditAPIKey, TargetID: key.ID, TeamID: key.TeamID}
+}
+
+// userChange names a change to a user for audit.Record. Users belong to no team.
+func userChange(action string, user models.User) models.AuditEntry {
+	return models.AuditEntry{Action: action, TargetType: models.AuditUser, TargetID: user.ID}
+}
+
+// teamChange names a change to a team, or its members, for audit.Record
+func teamChange(action string, team models.Team) models.AuditEntry {
+	return models.AuditEntry{Action: action, TargetType: models.AuditTeam, TargetID: team.ID, TeamID: team.ID}
+}
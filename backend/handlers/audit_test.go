@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listAudit returns the audit log entries matching query, as an admin sees them
+func listAudit(t *testing.T, query string) []models.AuditEntry {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	ListAudit(rr, adminRequest(http.MethodGet, "/audit"+query, nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var entries []models.AuditEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&entries))
+	return entries
+}
+
+func TestAudit_RecordsMonitorChanges(t *testing.T) {
+	setupTestDB(t)
+
+	editor := auth.Caller{
+		Scope: models.ScopeWrite,
+		User:  &models.User{ID: "user-1", Email: "ed@example.com", Role: models.RoleEditor},
+		Teams: []string{models.DefaultTeam},
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"url":  "https://www.example.com",
+		"auth": map[string]string{"type": "bearer", "token": "s3cret"},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, asCaller(httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)), editor))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	body, _ = json.Marshal(map[string]any{"check_interval": 120})
+	req := asCaller(httptest.NewRequest(http.MethodPatch, "/monitor/"+created.ID, bytes.NewBuffer(body)), editor)
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	entries := listAudit(t, "?target_id="+created.ID)
+	require.Len(t, entries, 2)
+
+	// Newest first
+	update := entries[0]
+	assert.Equal(t, "monitor.update", update.Action)
+	assert.Equal(t, "ed@example.com", update.Actor)
+	assert.Equal(t, models.ActorUser, update.ActorType)
+	assert.Equal(t, "user-1", update.ActorID)
+	assert.Equal(t, models.AuditMonitor, update.TargetType)
+	assert.Equal(t, models.DefaultTeam, update.TeamID)
+	assert.JSONEq(t, `0`, string(update.Diff["check_interval"].Before))
+	assert.JSONEq(t, `120`, string(update.Diff["check_interval"].After))
+	assert.NotContains(t, update.Diff, "url")
+
+	create := entries[1]
+	assert.Equal(t, "monitor.create", create.Action)
+	assert.Empty(t, create.Before)
+	assert.NotContains(t, string(create.After), "s3cret")
+
+	// Filters narrow the listing
+	assert.Len(t, listAudit(t, "?action=monitor.create"), 1)
+	assert.Len(t, listAudit(t, "?actor=someone@example.com"), 0)
+	assert.Len(t, listAudit(t, "?limit=1"), 1)
+}
+
+func TestAudit_RecordsUserChangesWithoutPasswords(t *testing.T) {
+	setupTestDB(t)
+
+	key := auth.Caller{Scope: models.ScopeAdmin, Key: &models.APIKey{ID: "key-1", Name: "ci"}}
+
+	body, _ := json.Marshal(map[string]any{"email": "new@example.com", "password": "correct horse", "role": models.RoleViewer})
+	rr := httptest.NewRecorder()
+	CreateUser(rr, asCaller(httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(body)), key))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var user models.User
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&user))
+
+	body, _ = json.Marshal(map[string]any{"password": "battery staple"})
+	req := asCaller(httptest.NewRequest(http.MethodPatch, "/users/"+user.ID, bytes.NewBuffer(body)), key)
+	req.SetPathValue("id", user.ID)
+	rr = httptest.NewRecorder()
+	UpdateUser(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	entries := listAudit(t, "?target_type=user")
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "user.update", entries[0].Action)
+	assert.Equal(t, "ci", entries[0].Actor)
+	assert.Equal(t, models.ActorAPIKey, entries[0].ActorType)
+	assert.JSONEq(t, `true`, string(entries[0].Diff["password_changed"].After))
+
+	raw, _ := json.Marshal(entries)
+	assert.NotContains(t, string(raw), "battery staple")
+	assert.NotContains(t, string(raw), "correct horse")
+}
+
+func TestAudit_InvalidFilter(t *testing.T) {
+	setupTestDB(t)
+
+	for _, query := range []string{"?from=yesterday", "?limit=0", "?limit=5000"} {
+		rr := httptest.NewRecorder()
+		ListAudit(rr, adminRequest(http.MethodGet, "/audit"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestAudit_IsAppendOnly(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	req := adminRequest(http.MethodDelete, "/monitor/test-monitor", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+	DeleteMonitor(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	entries := listAudit(t, "")
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.ActorSystem, entries[0].ActorType)
+	assert.Empty(t, entries[0].After)
+
+	_, err := db.GetDB().Exec(`UPDATE audit_log SET actor = 'someone else'`)
+	assert.Error(t, err)
+
+	_, err = db.GetDB().Exec(`DELETE FROM audit_log`)
+	assert.Error(t, err)
+
+	assert.Len(t, listAudit(t, ""), 1)
+}
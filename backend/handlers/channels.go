@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/KerlynD/URL-Monitor/backend/notify"
+	"github.com/google/uuid"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to create a notification channel that receives incident open/resolve events
+*/
+func CreateChannel(response http.ResponseWriter, request *http.Request) {
+	/*
+		This function parses the request body, validates the config against the
+		channel type, and saves the channel (enabled by default).
+	*/
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.create_channel")
+	defer span.Finish()
+
+	var req struct {
+		TeamID  string          `json:"team_id"`
+		Name    string          `json:"name"`
+		Type    string          `json:"type"`
+		Config  json.RawMessage `json:"config"`
+		Enabled *bool           `json:"enabled"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	span.SetTag("channel.type", req.Type)
+
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(response, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	err = notify.Validate(req.Type, req.Config)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	teamID, err := owningTeam(request, req.TeamID)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	channel := models.NotificationChannel{
+		ID:        uuid.New().String(),
+		TeamID:    teamID,
+		Name:      req.Name,
+		Type:      req.Type,
+		Config:    req.Config,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err = db.SaveChannel(channel)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to save channel to DB")
+		return
+	}
+
+	audit.Record(request.Context(), channelChange("channel.create", channel), nil, redactChannel(channel))
+
+	// Return 201
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(redactChannel(channel))
+}
+
+/*
+Function to list every notification channel, with secrets redacted
+*/
+func ListChannels(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_channels")
+	defer span.Finish()
+
+	channels, err := db.GetChannels(tenantOf(request), false)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch channels from db")
+		return
+	}
+
+	for i := range channels {
+		channels[i] = redactChannel(channels[i])
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(channels)
+}
+
+/*
+Function to get a single notification channel, with secrets redacted
+*/
+func GetChannel(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.get_channel")
+	defer span.Finish()
+
+	channel, err := db.GetChannel(tenantOf(request), request.PathValue("id"))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Channel not found")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(redactChannel(channel))
+}
+
+/*
+Function to update a channel's name, config or enabled flag. Secret config values
+sent back redacted keep their stored value.
+*/
+func UpdateChannel(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.update_channel")
+	defer span.Finish()
+
+	var req struct {
+		Name    *string         `json:"name"`
+		Config  json.RawMessage `json:"config"`
+		Enabled *bool           `json:"enabled"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	channel, err := db.GetChannel(tenantOf(request), request.PathValue("id"))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Channel not found")
+		return
+	}
+
+	before := redactChannel(channel)
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(response, http.StatusBadRequest, "name must not be empty")
+			return
+		}
+		channel.Name = *req.Name
+	}
+
+	if req.Config != nil {
+		config := notify.MergeSecrets(channel.Type, req.Config, channel.Config)
+
+		err = notify.Validate(channel.Type, config)
+		if err != nil {
+			writeError(response, http.StatusBadRequest, err.Error())
+			return
+		}
+		channel.Config = config
+	}
+
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+
+	channel.UpdatedAt = time.Now()
+
+	err = db.UpdateChannel(channel)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to update channel")
+		return
+	}
+
+	audit.Record(request.Context(), channelChange("channel.update", channel), before, redactChannel(channel))
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(redactChannel(channel))
+}
+
+/*
+Function to delete a notification channel
+*/
+func DeleteChannel(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.delete_channel")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	channel, err := db.GetChannel(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Channel not found")
+		return
+	}
+
+	err = db.DeleteChannel(id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to delete channel")
+		return
+	}
+
+	audit.Record(request.Context(), channelChange("channel.delete", channel), redactChannel(channel), nil)
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// redactChannel hides secret config values before a channel is returned
+func redactChannel(channel models.NotificationChannel) models.NotificationChannel {
+	channel.Config = notify.RedactConfig(channel.Type, channel.Config)
+	return channel
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestChannel creates a webhook channel through the handler and returns it
+func createTestChannel(t *testing.T) models.NotificationChannel {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"name":   "ops webhook",
+		"type":   "webhook",
+		"config": map[string]any{"url": "https://hooks.example.com/ops", "secret": "shh"},
+	})
+	req := adminRequest(http.MethodPost, "/channels", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	CreateChannel(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var channel models.NotificationChannel
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&channel))
+	return channel
+}
+
+func TestCreateChannel_RedactsSecret(t *testing.T) {
+	setupTestDB(t)
+
+	channel := createTestChannel(t)
+
+	assert.True(t, channel.Enabled)
+	assert.NotContains(t, string(channel.Config), "shh")
+
+	stored, err := db.GetChannel(db.AllTeams, channel.ID)
+	require.NoError(t, err)
+	assert.Contains(t, string(stored.Config), "shh")
+}
+
+func TestCreateChannel_InvalidConfig(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"name":   "broken",
+		"type":   "webhook",
+		"config": map[string]any{"url": "ftp://example.com"},
+	})
+	req := adminRequest(http.MethodPost, "/channels", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	CreateChannel(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUpdateChannel_KeepsRedactedSecret(t *testing.T) {
+	setupTestDB(t)
+	channel := createTestChannel(t)
+
+	// Client edits the URL and sends the redacted config straight back
+	var config map[string]any
+	json.Unmarshal(channel.Config, &config)
+	config["url"] = "https://hooks.example.com/new"
+
+	body, _ := json.Marshal(map[string]any{
+		"config":  config,
+		"enabled": false,
+	})
+	req := adminRequest(http.MethodPatch, "/channels/"+channel.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", channel.ID)
+	rr := httptest.NewRecorder()
+
+	UpdateChannel(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stored, err := db.GetChannel(db.AllTeams, channel.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Enabled)
+	assert.Contains(t, string(stored.Config), "hooks.example.com/new")
+	assert.Contains(t, string(stored.Config), "shh")
+}
+
+func TestDeleteChannel(t *testing.T) {
+	setupTestDB(t)
+	channel := createTestChannel(t)
+
+	req := adminRequest(http.MethodDelete, "/channels/"+channel.ID, nil)
+	req.SetPathValue("id", channel.ID)
+	rr := httptest.NewRecorder()
+
+	DeleteChannel(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	req = adminRequest(http.MethodGet, "/channels", nil)
+	rr = httptest.NewRecorder()
+
+	ListChannels(rr, req)
+
+	var channels []models.NotificationChannel
+	json.NewDecoder(rr.Body).Decode(&channels)
+	assert.Empty(t, channels)
+}
+
+func TestListChannelDeliveries_FiltersByStatus(t *testing.T) {
+	setupTestDB(t)
+	channel := createTestChannel(t)
+
+	for attempt, status := range []string{models.DeliveryFailed, models.DeliveryFailed, models.DeliverySucceeded} {
+		require.NoError(t, db.SaveDelivery(models.NotificationDelivery{
+			ChannelID:  channel.ID,
+			MonitorID:  "monitor1",
+			IncidentID: "incident1",
+			Event:      models.EventIncidentOpened,
+			Attempt:    attempt + 1,
+			Status:     status,
+			CreatedAt:  time.Now(),
+		}))
+	}
+
+	req := adminRequest(http.MethodGet, "/channels/"+channel.ID+"/deliveries?status=failed", nil)
+	req.SetPathValue("id", channel.ID)
+	rr := httptest.NewRecorder()
+
+	ListChannelDeliveries(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var deliveries []models.NotificationDelivery
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&deliveries))
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+
+	req = adminRequest(http.MethodGet, "/channels/missing/deliveries", nil)
+	req.SetPathValue("id", "missing")
+	rr = httptest.NewRecorder()
+
+	ListChannelDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+const defaultDeliveriesLimit = 100
+
+/*
+Function to list notification delivery attempts across all channels, optionally
+filtered by ?status=delivered|failed
+*/
+func ListDeliveries(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_deliveries")
+	defer span.Finish()
+
+	writeDeliveries(response, request, span, "")
+}
+
+/*
+Function to list the delivery attempts of a single channel, optionally filtered by ?status=
+*/
+func ListChannelDeliveries(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_channel_deliveries")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	_, err := db.GetChannel(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Channel not found")
+		return
+	}
+
+	writeDeliveries(response, request, span, id)
+}
+
+func writeDeliveries(response http.ResponseWriter, request *http.Request, span tracer.Span, channelID string) {
+	/*
+		This function validates the status and limit query parameters, then fetches and
+		returns the matching delivery attempts newest first.
+	*/
+	filter := db.DeliveryFilter{
+		ChannelID: channelID,
+		Status:    request.URL.Query().Get("status"),
+		Limit:     defaultDeliveriesLimit,
+		Tenant:    tenantOf(request),
+	}
+
+	if filter.Status != "" && filter.Status != models.DeliverySucceeded && filter.Status != models.DeliveryFailed {
+		writeError(response, http.StatusBadRequest, "status must be delivered or failed")
+		return
+	}
+
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			writeError(response, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		filter.Limit = value
+	}
+
+	deliveries, err := db.GetDeliveries(filter)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to get deliveries")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(deliveries)
+}
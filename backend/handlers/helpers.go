@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/KerlynD/URL-Monitor/backend/notify"
+)
+
+// writeError sends the {"error": message} body every handler uses for failures
+func writeError(response http.ResponseWriter, status int, message string) {
+	response.WriteHeader(status)
+	json.NewEncoder(response).Encode(map[string]string{
+		"error": message,
+	})
+}
+
+// validMonitorURL reports whether a URL can be monitored (http/https only)
+func validMonitorURL(rawURL string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	return err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https")
+}
+
+// validateCheckInterval checks a monitor's check_interval in seconds, where 0 means the default
+func validateCheckInterval(interval int) error {
+	if interval < 0 {
+		return fmt.Errorf("check_interval must not be negative")
+	}
+	return nil
+}
+
+// redactMonitor hides stored credentials before a monitor is sent to a client
+func redactMonitor(monitor models.MonitorEntry) models.MonitorEntry {
+	if monitor.Auth == nil {
+		return monitor
+	}
+
+	auth := *monitor.Auth
+	if auth.Password != "" {
+		auth.Password = notify.RedactedValue
+	}
+	if auth.Token != "" {
+		auth.Token = notify.RedactedValue
+	}
+
+	monitor.Auth = &auth
+	return monitor
+}
+
+// mergeAuth keeps the stored secret wherever an update sent back the redacted value
+func mergeAuth(updated *models.RequestAuth, stored *models.RequestAuth) {
+	if updated == nil || stored == nil {
+		return
+	}
+
+	if updated.Password == notify.RedactedValue {
+		updated.Password = stored.Password
+	}
+	if updated.Token == notify.RedactedValue {
+		updated.Token = stored.Token
+	}
+}
+
+// assignPingToken gives a heartbeat monitor the secret token its job pings with,
+// and defaults its URL to a name based on the monitor ID
+func assignPingToken(monitor *models.MonitorEntry) {
+	if monitor.Type != models.MonitorHeartbeat {
+		monitor.PingToken = ""
+		return
+	}
+
+	if monitor.URL == "" {
+		monitor.URL = models.MonitorHeartbeat + "://" + monitor.ID
+	}
+
+	if monitor.PingToken == "" {
+		token := make([]byte, 16)
+		rand.Read(token)
+		monitor.PingToken = hex.EncodeToString(token)
+	}
+}
+
+// tenantOf is what the caller of a request may see. A request without a caller
+// sees nothing; internal callers must say who they are with auth.WithCaller.
+func tenantOf(request *http.Request) db.Tenant {
+	caller, ok := auth.CallerFrom(request.Context())
+	if !ok {
+		return db.ForTeams()
+	}
+	return caller.Tenant()
+}
+
+/*
+Function to pick the team that owns something a request creates: the requested
+team if the caller belongs to it, else the caller's only team, else the default
+team for admins
+*/
+func owningTeam(request *http.Request, requested string) (string, error) {
+	tenant := tenantOf(request)
+
+	if requested == "" {
+		teamIDs, scoped := tenant.TeamIDs()
+		if !scoped {
+			return models.DefaultTeam, nil
+		}
+		if len(teamIDs) != 1 {
+			return "", fmt.Errorf("team_id is required when you are in %d teams", len(teamIDs))
+		}
+		return teamIDs[0], nil
+	}
+
+	_, err := db.GetTeam(tenant, requested)
+	if err != nil {
+		return "", fmt.Errorf("team %s not found", requested)
+	}
+	return requested, nil
+}
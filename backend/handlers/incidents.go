@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+const defaultIncidentsLimit = 100
+
+/*
+Function to list incidents across all monitors, optionally filtered by ?status=open|resolved
+*/
+func ListIncidents(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_incidents")
+	defer span.Finish()
+
+	writeIncidents(response, request, span, "")
+}
+
+/*
+Function to list the incidents of a single monitor, optionally filtered by ?status=
+*/
+func ListMonitorIncidents(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_monitor_incidents")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	_, err := db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
+	}
+
+	writeIncidents(response, request, span, id)
+}
+
+func writeIncidents(response http.ResponseWriter, request *http.Request, span tracer.Span, monitorID string) {
+	/*
+		This function validates the status and limit query parameters, then fetches and
+		returns the matching incidents newest first.
+	*/
+	filter := db.IncidentFilter{
+		MonitorID: monitorID,
+		Status:    request.URL.Query().Get("status"),
+		Limit:     defaultIncidentsLimit,
+		Tenant:    tenantOf(request),
+	}
+
+	if filter.Status != "" && filter.Status != models.IncidentOpen && filter.Status != models.IncidentResolved {
+		writeError(response, http.StatusBadRequest, "status must be open or resolved")
+		return
+	}
+
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			writeError(response, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		filter.Limit = value
+	}
+
+	incidents, err := db.GetIncidents(filter)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to get incidents")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(incidents)
+}
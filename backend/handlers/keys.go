@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to create an API key. The key itself is only in this response.
+*/
+func CreateAPIKey(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.create_api_key")
+	defer span.Finish()
+
+	var req struct {
+		TeamID string `json:"team_id"`
+		Name   string `json:"name"`
+		Scope  string `json:"scope"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(response, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	if !auth.ValidScope(req.Scope) {
+		writeError(response, http.StatusBadRequest, "scope must be read, write or admin")
+		return
+	}
+
+	// Admin keys reach every team, so they can't belong to one
+	var teamID string
+	if req.Scope == models.ScopeAdmin {
+		if req.TeamID != "" {
+			writeError(response, http.StatusBadRequest, "admin keys can't belong to a team")
+			return
+		}
+	} else {
+		teamID, err = owningTeam(request, req.TeamID)
+		if err != nil {
+			writeError(response, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	span.SetTag("api_key.scope", req.Scope)
+
+	key, secret, err := auth.CreateKey(req.Name, req.Scope, teamID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to save API key to DB")
+		return
+	}
+
+	audit.Record(request.Context(), keyChange("api_key.create", key), nil, key)
+
+	// Return 201
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(struct {
+		models.APIKey
+		Key string `json:"key"`
+	}{key, secret})
+}
+
+/*
+Function to list every API key, without the keys themselves
+*/
+func ListAPIKeys(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_api_keys")
+	defer span.Finish()
+
+	keys, err := db.GetAPIKeys(tenantOf(request))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch API keys from db")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(keys)
+}
+
+/*
+Function to revoke an API key, which stops working straight away
+*/
+func RevokeAPIKey(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.revoke_api_key")
+	defer span.Finish()
+
+	key, err := db.GetAPIKey(tenantOf(request), request.PathValue("id"))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	revoked := key
+	now := time.Now()
+	revoked.RevokedAt = &now
+
+	err = db.RevokeAPIKey(tenantOf(request), key.ID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(response, http.StatusNotFound, "API key not found")
+		return
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	audit.Record(request.Context(), keyChange("api_key.revoke", key), key, revoked)
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
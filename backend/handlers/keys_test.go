@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys_CreateListRevoke(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]string{"name": "grafana", "scope": "read"})
+	rr := httptest.NewRecorder()
+	CreateAPIKey(rr, adminRequest(http.MethodPost, "/keys", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var created struct {
+		models.APIKey
+		Key string `json:"key"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.NotEmpty(t, created.Key)
+	assert.Equal(t, models.ScopeRead, created.Scope)
+
+	rr = httptest.NewRecorder()
+	ListAPIKeys(rr, adminRequest(http.MethodGet, "/keys", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), created.Key, "keys are only shown when created")
+	assert.Contains(t, rr.Body.String(), created.Prefix)
+
+	req := adminRequest(http.MethodDelete, "/keys/"+created.ID, nil)
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	RevokeAPIKey(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	_, err := db.GetActiveAPIKey(auth.HashKey(created.Key))
+	assert.Error(t, err)
+
+	// Revoking twice finds nothing to revoke
+	rr = httptest.NewRecorder()
+	RevokeAPIKey(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestCreateAPIKey_Validation(t *testing.T) {
+	setupTestDB(t)
+
+	for _, fields := range []map[string]string{
+		{"name": "", "scope": "read"},
+		{"name": "ops", "scope": "root"},
+		{"name": "ops", "scope": "admin", "team_id": models.DefaultTeam}, // <- Admin keys reach every team
+	} {
+		body, _ := json.Marshal(fields)
+		rr := httptest.NewRecorder()
+		CreateAPIKey(rr, adminRequest(http.MethodPost, "/keys", bytes.NewBuffer(body)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	}
+}
@@ -1,18 +1,21 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"net/url"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/checks"
 	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/incidents"
 	"github.com/KerlynD/URL-Monitor/backend/metrics"
 	"github.com/KerlynD/URL-Monitor/backend/models"
 	"github.com/google/uuid"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 )
 
 /*
@@ -30,9 +33,30 @@ func CreateMonitor(response http.ResponseWriter, request *http.Request) {
 	defer span.Finish()
 
 	var req struct {
-		URL           string `json:"url"`
-		CheckInterval int    `json:"check_interval"`
-		Password      string `json:"password"`
+		TeamID            string                  `json:"team_id"`
+		Type              string                  `json:"type"`
+		URL               string                  `json:"url"`
+		CheckInterval     int                     `json:"check_interval"`
+		FailureThreshold  int                     `json:"failure_threshold"`
+		RecoveryThreshold int                     `json:"recovery_threshold"`
+		Assertions        []models.Assertion      `json:"assertions"`
+		Method            string                  `json:"method"`
+		Headers           map[string]string       `json:"headers"`
+		Body              string                  `json:"body"`
+		ContentType       string                  `json:"content_type"`
+		Auth              *models.RequestAuth     `json:"auth"`
+		CertWarningDays   int                     `json:"cert_warning_days"`
+		DNS               *models.DNSConfig       `json:"dns"`
+		GRPC              *models.GRPCConfig      `json:"grpc"`
+		Heartbeat         *models.HeartbeatConfig `json:"heartbeat"`
+		Retries           int                     `json:"retries"`
+		RetryDelay        int                     `json:"retry_delay"`
+		ConfirmFailures   bool                    `json:"confirm_failures"`
+		Timeout           int                     `json:"timeout"`
+		FollowRedirects   *bool                   `json:"follow_redirects"`
+		MaxRedirects      int                     `json:"max_redirects"`
+		IgnoreTLSErrors   bool                    `json:"ignore_tls_errors"`
+		MaxBodyBytes      int64                   `json:"max_body_bytes"`
 	}
 
 	err := json.NewDecoder(request.Body).Decode(&req)
@@ -47,29 +71,18 @@ func CreateMonitor(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	// Check password
-	expectedPassword := os.Getenv("ADMIN_PASSWORD")
-	if expectedPassword == "" {
-		expectedPassword = "urlmonitor2024" // Default password if not set
-	}
-	
-	if req.Password != expectedPassword {
-		span.SetTag("error", true)
-		response.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Invalid password",
-		})
-		return
-	}
-
 	span.SetTag("monitor.url", req.URL)
 	span.SetTag("monitor.check_interval", req.CheckInterval)
 
+	if req.Type == "" {
+		req.Type = models.MonitorHTTP
+	}
+
 	validationSpan := tracer.StartSpan("handler.url.validation", tracer.ChildOf(span.Context()))
-	parsedURL, err := url.Parse(req.URL)
+	validURL := req.Type != models.MonitorHTTP || validMonitorURL(req.URL)
 	validationSpan.Finish()
 
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+	if !validURL {
 		span.SetTag("error", true)
 		// Return 400
 		response.WriteHeader(http.StatusBadRequest)
@@ -79,14 +92,59 @@ func CreateMonitor(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	err = validateCheckInterval(req.CheckInterval)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	teamID, err := owningTeam(request, req.TeamID)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetTag("monitor.team", teamID)
+
 	id := uuid.New().String()
 
 	monitor := models.MonitorEntry{
-		ID:            id,
-		URL:           req.URL,
-		CheckInterval: req.CheckInterval,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:                id,
+		TeamID:            teamID,
+		Type:              req.Type,
+		URL:               req.URL,
+		CheckInterval:     req.CheckInterval,
+		FailureThreshold:  max(req.FailureThreshold, 1),
+		RecoveryThreshold: max(req.RecoveryThreshold, 1),
+		Assertions:        req.Assertions,
+		Method:            strings.ToUpper(req.Method),
+		Headers:           req.Headers,
+		Body:              req.Body,
+		ContentType:       req.ContentType,
+		Auth:              req.Auth,
+		CertWarningDays:   req.CertWarningDays,
+		DNS:               req.DNS,
+		GRPC:              req.GRPC,
+		Heartbeat:         req.Heartbeat,
+		Retries:           req.Retries,
+		RetryDelay:        req.RetryDelay,
+		ConfirmFailures:   req.ConfirmFailures,
+		Timeout:           req.Timeout,
+		FollowRedirects:   req.FollowRedirects,
+		MaxRedirects:      req.MaxRedirects,
+		IgnoreTLSErrors:   req.IgnoreTLSErrors,
+		MaxBodyBytes:      req.MaxBodyBytes,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	assignPingToken(&monitor)
+
+	err = checks.Validate(monitor)
+	if err != nil {
+		span.SetTag("error", true)
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	saveSpan := tracer.StartSpan("db.save_monitor", tracer.ChildOf(span.Context()))
@@ -106,13 +164,15 @@ func CreateMonitor(response http.ResponseWriter, request *http.Request) {
 	if metrics.Client != nil {
 		metrics.Client.Incr("monitors.created", nil, 1.0)
 
-		monitors, _ := db.GetAllMonitors()
+		monitors, _ := db.GetAllMonitors(db.AllTeams)
 		metrics.Client.Gauge("monitors.total", float64(len(monitors)), nil, 1.0)
 	}
 
+	audit.Record(request.Context(), monitorChange("monitor.create", monitor), nil, redactMonitor(monitor))
+
 	// Return 201
 	response.WriteHeader(http.StatusCreated)
-	json.NewEncoder(response).Encode(monitor)
+	json.NewEncoder(response).Encode(redactMonitor(monitor))
 }
 
 /*
@@ -127,7 +187,7 @@ func ListMonitors(response http.ResponseWriter, request *http.Request) {
 	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_monitors")
 	defer span.Finish()
 
-	monitors, err := db.GetAllMonitors()
+	monitors, err := db.GetAllMonitors(tenantOf(request))
 	if err != nil {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
@@ -142,16 +202,22 @@ func ListMonitors(response http.ResponseWriter, request *http.Request) {
 
 	var monitorResponses []models.MonitorWithStatus
 
+	summaries := statsSummaries(span, tenantOf(request))
+
 	for _, monitor := range monitors {
 		getLatestResultSpan := tracer.StartSpan("db.get_latest_result", tracer.ChildOf(span.Context()))
 		result, err := db.GetLatestResult(monitor.ID)
 		getLatestResultSpan.Finish()
 
 		status := models.MonitorWithStatus{
-			MonitorEntry: monitor,
+			MonitorEntry: redactMonitor(monitor),
 			LastResult:   nil, // Default
 		}
 
+		if summary, ok := summaries[monitor.ID]; ok {
+			status.Summary = &summary
+		}
+
 		if err == nil {
 			status.LastResult = &result
 		}
@@ -178,7 +244,7 @@ func GetMonitor(response http.ResponseWriter, request *http.Request) {
 
 	id := request.PathValue("id")
 
-	monitor, err := db.GetMonitor(id)
+	monitor, err := db.GetMonitor(tenantOf(request), id)
 	if err != nil {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
@@ -193,7 +259,8 @@ func GetMonitor(response http.ResponseWriter, request *http.Request) {
 	result, err := db.GetLatestResult(id)
 	getLatestResultSpan.Finish()
 
-	if err != nil {
+	// A monitor that hasn't been checked yet simply has no last result
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
 
@@ -201,11 +268,13 @@ func GetMonitor(response http.ResponseWriter, request *http.Request) {
 		json.NewEncoder(response).Encode(map[string]string{
 			"error": "Failed to get latest result",
 		})
+		return
 	}
 
 	status := models.MonitorWithStatus{
-		MonitorEntry: monitor,
+		MonitorEntry: redactMonitor(monitor),
 		LastResult:   nil,
+		Summary:      statsSummary(span, id),
 	}
 
 	if err == nil {
@@ -219,8 +288,9 @@ func GetMonitor(response http.ResponseWriter, request *http.Request) {
 func TriggerCheck(response http.ResponseWriter, request *http.Request) {
 	/*
 		This function extracts the ID from the request, tries to get the monitor,
-		performs an HTTP check with performCheck() (helper below) and saves result
-		to database
+		checks it once with the checker for its type (checks.RunOnce()), saves result
+		to database and updates the monitor's incident state. Retries are left to
+		scheduled checks so the caller isn't kept waiting through them.
 	*/
 	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.trigger_check")
 	defer span.Finish()
@@ -228,7 +298,7 @@ func TriggerCheck(response http.ResponseWriter, request *http.Request) {
 	id := request.PathValue("id")
 
 	getMonitorSpan := tracer.StartSpan("db.get_monitor", tracer.ChildOf(span.Context()))
-	monitor, err := db.GetMonitor(id)
+	monitor, err := db.GetMonitor(tenantOf(request), id)
 	getMonitorSpan.Finish()
 
 	if err != nil {
@@ -241,8 +311,14 @@ func TriggerCheck(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Heartbeat monitors have nothing to poll; their results come from pings
+	if monitor.Type == models.MonitorHeartbeat {
+		writeError(response, http.StatusBadRequest, "Heartbeat monitors are checked by their pings")
+		return
+	}
+
 	checkSpan := tracer.StartSpan("handler.perform_check", tracer.ChildOf(span.Context()))
-	result := PerformCheck(monitor.URL)
+	result := checks.RunOnce(monitor)
 	checkSpan.Finish()
 
 	err = db.SaveResult(id, result)
@@ -257,48 +333,325 @@ func TriggerCheck(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	_, err = incidents.Evaluate(monitor, result)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+	}
+
 	response.WriteHeader(http.StatusOK)
 	json.NewEncoder(response).Encode(result)
 }
 
-func PerformCheck(targetURL string) models.MonitorResult {
-	/*
-		This function creates an HTTP client (with timeout), makes a GET request,
-		checks duration and returns the result
-	*/
-	span := tracer.StartSpan("http.check", 
-		tracer.ResourceName("GET " + targetURL),
-		tracer.SpanType("http"),
-	)
+/*
+Function to update a monitor's URL or check interval. PUT replaces both fields,
+PATCH only changes the fields present in the body.
+*/
+func UpdateMonitor(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.update_monitor")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	var req struct {
+		Type              *string                 `json:"type"`
+		URL               *string                 `json:"url"`
+		CheckInterval     *int                    `json:"check_interval"`
+		FailureThreshold  *int                    `json:"failure_threshold"`
+		RecoveryThreshold *int                    `json:"recovery_threshold"`
+		Assertions        *[]models.Assertion     `json:"assertions"`
+		Method            *string                 `json:"method"`
+		Headers           *map[string]string      `json:"headers"`
+		Body              *string                 `json:"body"`
+		ContentType       *string                 `json:"content_type"`
+		Auth              json.RawMessage         `json:"auth"` // <- null removes the credentials
+		CertWarningDays   *int                    `json:"cert_warning_days"`
+		DNS               *models.DNSConfig       `json:"dns"`
+		GRPC              *models.GRPCConfig      `json:"grpc"`
+		Heartbeat         *models.HeartbeatConfig `json:"heartbeat"`
+		Retries           *int                    `json:"retries"`
+		RetryDelay        *int                    `json:"retry_delay"`
+		ConfirmFailures   *bool                   `json:"confirm_failures"`
+		Timeout           *int                    `json:"timeout"`
+		FollowRedirects   json.RawMessage         `json:"follow_redirects"` // <- null goes back to following them
+		MaxRedirects      *int                    `json:"max_redirects"`
+		IgnoreTLSErrors   *bool                   `json:"ignore_tls_errors"`
+		MaxBodyBytes      *int64                  `json:"max_body_bytes"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if request.Method == http.MethodPut && (req.URL == nil || req.CheckInterval == nil) {
+		writeError(response, http.StatusBadRequest, "PUT requires url and check_interval")
+		return
+	}
+
+	monitor, err := db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
+	}
+
+	before := redactMonitor(monitor)
+
+	if req.URL != nil {
+		monitor.URL = *req.URL
+	}
+
+	if req.CheckInterval != nil {
+		err = validateCheckInterval(*req.CheckInterval)
+		if err != nil {
+			writeError(response, http.StatusBadRequest, err.Error())
+			return
+		}
+		monitor.CheckInterval = *req.CheckInterval
+	}
+
+	if req.FailureThreshold != nil {
+		if *req.FailureThreshold < 1 {
+			writeError(response, http.StatusBadRequest, "failure_threshold must be at least 1")
+			return
+		}
+		monitor.FailureThreshold = *req.FailureThreshold
+	}
+
+	if req.RecoveryThreshold != nil {
+		if *req.RecoveryThreshold < 1 {
+			writeError(response, http.StatusBadRequest, "recovery_threshold must be at least 1")
+			return
+		}
+		monitor.RecoveryThreshold = *req.RecoveryThreshold
+	}
+
+	// PUT replaces the whole monitor, so leaving the check config out resets it
+	storedAuth := monitor.Auth
+	if request.Method == http.MethodPut {
+		monitor.Type = models.MonitorHTTP
+		monitor.Assertions = nil
+		monitor.Method = ""
+		monitor.Headers = nil
+		monitor.Body = ""
+		monitor.ContentType = ""
+		monitor.Auth = nil
+		monitor.ConfigError = "" // <- So credentials that couldn't be decrypted are dropped too
+		monitor.CertWarningDays = 0
+		monitor.DNS = nil
+		monitor.GRPC = nil
+		monitor.Heartbeat = nil
+		monitor.Retries = 0
+		monitor.RetryDelay = 0
+		monitor.ConfirmFailures = false
+		monitor.Timeout = 0
+		monitor.FollowRedirects = nil
+		monitor.MaxRedirects = 0
+		monitor.IgnoreTLSErrors = false
+		monitor.MaxBodyBytes = 0
+	}
+
+	if req.Type != nil {
+		monitor.Type = *req.Type
+	}
+	if req.Assertions != nil {
+		monitor.Assertions = *req.Assertions
+	}
+
+	if req.Method != nil {
+		monitor.Method = strings.ToUpper(*req.Method)
+	}
+	if req.Headers != nil {
+		monitor.Headers = *req.Headers
+	}
+	if req.Body != nil {
+		monitor.Body = *req.Body
+	}
+	if req.ContentType != nil {
+		monitor.ContentType = *req.ContentType
+	}
+	if req.CertWarningDays != nil {
+		monitor.CertWarningDays = *req.CertWarningDays
+	}
+	if req.DNS != nil {
+		monitor.DNS = req.DNS
+	}
+	if req.GRPC != nil {
+		monitor.GRPC = req.GRPC
+	}
+	if req.Heartbeat != nil {
+		monitor.Heartbeat = req.Heartbeat
+	}
+	if req.Retries != nil {
+		monitor.Retries = *req.Retries
+	}
+	if req.RetryDelay != nil {
+		monitor.RetryDelay = *req.RetryDelay
+	}
+	if req.ConfirmFailures != nil {
+		monitor.ConfirmFailures = *req.ConfirmFailures
+	}
+	if req.Timeout != nil {
+		monitor.Timeout = *req.Timeout
+	}
+	if req.MaxRedirects != nil {
+		monitor.MaxRedirects = *req.MaxRedirects
+	}
+	if req.IgnoreTLSErrors != nil {
+		monitor.IgnoreTLSErrors = *req.IgnoreTLSErrors
+	}
+	if req.MaxBodyBytes != nil {
+		monitor.MaxBodyBytes = *req.MaxBodyBytes
+	}
+
+	if len(req.FollowRedirects) > 0 {
+		monitor.FollowRedirects = nil
+		if string(req.FollowRedirects) != "null" {
+			err = json.Unmarshal(req.FollowRedirects, &monitor.FollowRedirects)
+			if err != nil {
+				writeError(response, http.StatusBadRequest, "Invalid follow_redirects format")
+				return
+			}
+		}
+	}
+
+	if len(req.Auth) > 0 {
+		monitor.Auth = nil
+		monitor.ConfigError = "" // <- New credentials replace ones that couldn't be decrypted
+		if string(req.Auth) != "null" {
+			err = json.Unmarshal(req.Auth, &monitor.Auth)
+			if err != nil {
+				writeError(response, http.StatusBadRequest, "Invalid auth format")
+				return
+			}
+			mergeAuth(monitor.Auth, storedAuth)
+		}
+	}
+
+	if monitor.Type == models.MonitorHTTP && !validMonitorURL(monitor.URL) {
+		writeError(response, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	assignPingToken(&monitor)
+
+	err = checks.Validate(monitor)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span.SetTag("monitor.url", monitor.URL)
+	span.SetTag("monitor.check_interval", monitor.CheckInterval)
+
+	monitor.UpdatedAt = time.Now()
+
+	err = db.UpdateMonitor(monitor)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to update monitor")
+		return
+	}
+
+	audit.Record(request.Context(), monitorChange("monitor.update", monitor), before, redactMonitor(monitor))
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(redactMonitor(monitor))
+}
+
+/*
+Function to delete a monitor and all of its stored results
+*/
+func DeleteMonitor(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.delete_monitor")
 	defer span.Finish()
 
-	client := httptrace.WrapClient(&http.Client{
-		Timeout: 10 * time.Second,
-	})
+	id := request.PathValue("id")
 
-	startTime := time.Now()
+	monitor, err := db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
+	}
 
-	resp, err := client.Get(targetURL)
+	err = db.DeleteMonitor(id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to delete monitor")
+		return
+	}
+
+	if metrics.Client != nil {
+		metrics.Client.Incr("monitors.deleted", nil, 1.0)
+	}
+
+	audit.Record(request.Context(), monitorChange("monitor.delete", monitor), redactMonitor(monitor), nil)
 
-	responseTime := time.Since(startTime)
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
+
+/*
+Function to stop the worker from checking a monitor until it is resumed
+*/
+func PauseMonitor(response http.ResponseWriter, request *http.Request) {
+	setMonitorPaused(response, request, true)
+}
 
-	result := models.MonitorResult{
-		Timestamp:    time.Now(),
-		ResponseTime: responseTime,
+/*
+Function to start checking a paused monitor again
+*/
+func ResumeMonitor(response http.ResponseWriter, request *http.Request) {
+	setMonitorPaused(response, request, false)
+}
+
+func setMonitorPaused(response http.ResponseWriter, request *http.Request, paused bool) {
+	/*
+		This function makes sure the monitor exists, then flips its paused flag.
+		The worker drops paused monitors on its next sync.
+	*/
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.set_monitor_paused")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+	span.SetTag("monitor.paused", paused)
+
+	monitor, err := db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
 	}
 
+	before := redactMonitor(monitor)
+
+	monitor.Paused = paused
+	monitor.UpdatedAt = time.Now()
+
+	err = db.SetMonitorPaused(id, paused, monitor.UpdatedAt)
 	if err != nil {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
-		result.IsUp = false
-		result.Error = err.Error()
-		result.StatusCode = 0
-	} else {
-		defer resp.Body.Close()
-		result.IsUp = resp.StatusCode >= 200 && resp.StatusCode < 300
-		result.StatusCode = resp.StatusCode
-		result.Error = ""
+		writeError(response, http.StatusInternalServerError, "Failed to update monitor")
+		return
 	}
 
-	return result
+	action := "monitor.resume"
+	if paused {
+		action = "monitor.pause"
+	}
+	audit.Record(request.Context(), monitorChange(action, monitor), before, redactMonitor(monitor))
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(redactMonitor(monitor))
 }
@@ -2,14 +2,17 @@ package handlers
 
 import (
     "bytes"
+    "database/sql"
     "encoding/json"
     "net/http"
     "net/http/httptest"
     "testing"
     "time"
 
+    "github.com/KerlynD/URL-Monitor/backend/checks"
     "github.com/KerlynD/URL-Monitor/backend/db"
     "github.com/KerlynD/URL-Monitor/backend/models"
+    "github.com/KerlynD/URL-Monitor/backend/secrets"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/require"
 )
@@ -17,6 +20,7 @@ import (
 // TestMain: Entry point for testing - setups and tears down
 func TestMain(m *testing.M) {
 	// Init DB for testing
+	secrets.Init("test passphrase", "")
 	db.InitDB(":memory:")
 	defer db.CloseDB()
 
@@ -35,6 +39,19 @@ func setupTestDB(t *testing.T) {
 }
 
 
+// saveTestMonitor stores a monitor with the given ID for handlers to act on
+func saveTestMonitor(t *testing.T, id string) {
+	t.Helper()
+	err := db.SaveMonitor(models.MonitorEntry{
+		ID:            id,
+		URL:           "https://www.example.com",
+		CheckInterval: 60,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	require.NoError(t, err)
+}
+
 // <----- MAIN TEST FUNCTIONS ----->
 
 // Function to test CreateMonitor handler with valid input
@@ -48,7 +65,7 @@ func TestCreateMonitor_Success(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
+	req := adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -76,7 +93,7 @@ func TestCreateMonitor_InvalidURL(t *testing.T) {
 
 	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
+	req := adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -93,7 +110,7 @@ func TestCreateMonitor_MalformedJSON(t *testing.T) {
 	// Create test data and request, perform request, and validate that the JSON is malformed
 	setupTestDB(t)
 
-	req := httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBufferString("invalid-json"))
+	req := adminRequest(http.MethodPost, "/monitor", bytes.NewBufferString("invalid-json"))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -106,7 +123,7 @@ func TestListMonitors_Empty(t *testing.T) {
 	// Create test data and request, perform request, and validate that the list is empty
 	setupTestDB(t)
 
-	req := httptest.NewRequest(http.MethodGet, "/monitor", nil)
+	req := adminRequest(http.MethodGet, "/monitor", nil)
 	rr := httptest.NewRecorder()
 
 	ListMonitors(rr, req)
@@ -132,7 +149,7 @@ func TestListMonitors_WithData(t *testing.T) {
 
 	db.SaveMonitor(testMonitor)
 
-	req := httptest.NewRequest(http.MethodGet, "/monitor", nil)
+	req := adminRequest(http.MethodGet, "/monitor", nil)
 	rr := httptest.NewRecorder()
 
 	ListMonitors(rr, req)
@@ -158,7 +175,7 @@ func TestGetMonitor_Success(t *testing.T) {
 	}
 	db.SaveMonitor(testMonitor)
 
-	req := httptest.NewRequest(http.MethodGet, "/monitor/test-monitor", nil)
+	req := adminRequest(http.MethodGet, "/monitor/test-monitor", nil)
 	req.SetPathValue("id", "test-monitor")
 	rr := httptest.NewRecorder()
 
@@ -176,7 +193,7 @@ func TestGetMonitor_NotFound(t *testing.T) {
 	// Create test data and request, perform request, and validate that the monitor is not found
 	setupTestDB(t)
 
-	req := httptest.NewRequest(http.MethodGet, "/monitor/not-real-monitor", nil)
+	req := adminRequest(http.MethodGet, "/monitor/not-real-monitor", nil)
 	req.SetPathValue("id", "not-real-monitor")
 	rr := httptest.NewRecorder()
 
@@ -198,7 +215,7 @@ func TestTriggerCheck_Success(t *testing.T) {
 	}
 	db.SaveMonitor(testMonitor)
 
-	req := httptest.NewRequest(http.MethodPost, "/monitor/test-monitor/check", nil)
+	req := adminRequest(http.MethodPost, "/monitor/test-monitor/check", nil)
 	req.SetPathValue("id", "test-monitor")
 	rr := httptest.NewRecorder()
 
@@ -213,38 +230,471 @@ func TestTriggerCheck_Success(t *testing.T) {
 }
 
 
-// <----- HELPER FUNCTIONS ----->
+func TestUpdateMonitor_Patch(t *testing.T) {
+	// PATCH only changes the fields that are sent
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
 
-// Test PerformCheck with valid URL
-func TestPerformCheck_ValidURL(t *testing.T) {
-    testURL := "https://www.datadoghq.com"
-    
-    result := PerformCheck(testURL)
-    
-    assert.True(t, result.IsUp, "Datadog website should be up")
-    assert.Equal(t, 200, result.StatusCode)
-    assert.Greater(t, result.ResponseTime, time.Duration(0))
-    assert.Empty(t, result.Error)
+	body, _ := json.Marshal(map[string]interface{}{
+		"check_interval": 120,
+	})
+	req := adminRequest(http.MethodPatch, "/monitor/test-monitor", bytes.NewBuffer(body))
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	UpdateMonitor(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	monitor, err := db.GetMonitor(db.AllTeams, "test-monitor")
+	require.NoError(t, err)
+	assert.Equal(t, 120, monitor.CheckInterval)
+	assert.Equal(t, "https://www.example.com", monitor.URL)
 }
 
-// Test PerformCheck with invalid URL
-func TestPerformCheck_InvalidURL(t *testing.T) {
-    testURL := "https://this-domain-definitely-does-not-exist-12345.com"
-    
-    result := PerformCheck(testURL)
-    
-    assert.False(t, result.IsUp, "Invalid domain should be down")
-    assert.NotEmpty(t, result.Error)
+func TestUpdateMonitor_PutRequiresAllFields(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"url": "https://www.example.org",
+	})
+	req := adminRequest(http.MethodPut, "/monitor/test-monitor", bytes.NewBuffer(body))
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	UpdateMonitor(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
-// Test PerformCheck with unreachable server
-func TestPerformCheck_UnreachableServer(t *testing.T) {
-    testURL := "http://localhost:9999"
-    
-    result := PerformCheck(testURL)
-    
-    assert.False(t, result.IsUp)
-    assert.NotEmpty(t, result.Error)
+func TestDeleteMonitor_RemovesResults(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+	require.NoError(t, db.SaveResult("test-monitor", models.MonitorResult{
+		StatusCode: 200,
+		IsUp:       true,
+		Timestamp:  time.Now(),
+	}))
+	require.NoError(t, db.SaveDelivery(models.NotificationDelivery{
+		ChannelID:  "channel-1",
+		MonitorID:  "test-monitor",
+		IncidentID: "incident-1",
+		Event:      models.EventIncidentOpened,
+		Attempt:    1,
+		Status:     models.DeliverySucceeded,
+		CreatedAt:  time.Now(),
+	}))
+
+	req := adminRequest(http.MethodDelete, "/monitor/test-monitor", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	DeleteMonitor(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	_, err := db.GetMonitor(db.AllTeams, "test-monitor")
+	assert.Error(t, err)
+	_, err = db.GetLatestResult("test-monitor")
+	assert.Error(t, err)
+
+	var deliveries int
+	require.NoError(t, db.GetDB().QueryRow(`SELECT COUNT(*) FROM notification_deliveries`).Scan(&deliveries))
+	assert.Zero(t, deliveries)
+}
+
+func TestPauseAndResumeMonitor(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	req := adminRequest(http.MethodPost, "/monitor/test-monitor/pause", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	PauseMonitor(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	monitor, err := db.GetMonitor(db.AllTeams, "test-monitor")
+	require.NoError(t, err)
+	assert.True(t, monitor.Paused)
+
+	req = adminRequest(http.MethodPost, "/monitor/test-monitor/resume", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr = httptest.NewRecorder()
+
+	ResumeMonitor(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	monitor, err = db.GetMonitor(db.AllTeams, "test-monitor")
+	require.NoError(t, err)
+	assert.False(t, monitor.Paused)
+}
+
+func TestPauseMonitor_NotFound(t *testing.T) {
+	setupTestDB(t)
+
+	req := adminRequest(http.MethodPost, "/monitor/missing/pause", nil)
+	req.SetPathValue("id", "missing")
+	rr := httptest.NewRecorder()
+
+	PauseMonitor(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestListIncidents_FiltersByStatus(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	started := time.Now().Add(-time.Hour)
+	require.NoError(t, db.CreateIncident(models.Incident{
+		ID: "old", MonitorID: "test-monitor", Status: models.IncidentOpen, StartedAt: started,
+	}))
+	require.NoError(t, db.ResolveIncident("old", started.Add(time.Minute)))
+	require.NoError(t, db.CreateIncident(models.Incident{
+		ID: "current", MonitorID: "test-monitor", Status: models.IncidentOpen, StartedAt: time.Now(),
+	}))
+
+	req := adminRequest(http.MethodGet, "/incidents?status=open", nil)
+	rr := httptest.NewRecorder()
+
+	ListIncidents(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var incidents []models.Incident
+	json.NewDecoder(rr.Body).Decode(&incidents)
+	require.Len(t, incidents, 1)
+	assert.Equal(t, "current", incidents[0].ID)
+
+	req = adminRequest(http.MethodGet, "/monitor/test-monitor/incidents", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr = httptest.NewRecorder()
+
+	ListMonitorIncidents(rr, req)
+
+	json.NewDecoder(rr.Body).Decode(&incidents)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, time.Minute, incidents[1].Duration)
+}
+
+func TestListIncidents_InvalidStatus(t *testing.T) {
+	setupTestDB(t)
+
+	req := adminRequest(http.MethodGet, "/incidents?status=broken", nil)
+	rr := httptest.NewRecorder()
+
+	ListIncidents(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// Test assertions are validated and stored with the monitor
+func TestCreateMonitor_Assertions(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"url":        "https://www.example.com",
+		"assertions": []map[string]any{{"type": "body_regex", "value": "("}},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body, _ = json.Marshal(map[string]any{
+		"url":        "https://www.example.com",
+		"assertions": []map[string]any{{"type": "body_contains", "value": "Example Domain"}},
+	})
+	rr = httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	stored, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Assertion{{Type: models.AssertBodyContains, Value: "Example Domain"}}, stored.Assertions)
+}
+
+// Test credentials are encrypted at rest, redacted in responses and kept across updates
+func TestMonitorCredentials_RedactedAndKept(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"url":    "https://www.example.com",
+		"method": "post",
+		"auth":   map[string]string{"type": "basic", "username": "probe", "password": "hunter2"},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "hunter2")
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, http.MethodPost, created.Method)
+
+	var stored string
+	require.NoError(t, db.GetDB().QueryRow(`SELECT auth FROM monitors WHERE id = ?`, created.ID).Scan(&stored))
+	assert.NotContains(t, stored, "hunter2")
+
+	rr = httptest.NewRecorder()
+	ListMonitors(rr, adminRequest(http.MethodGet, "/monitor", nil))
+	assert.NotContains(t, rr.Body.String(), "hunter2")
+	assert.Contains(t, rr.Body.String(), `"username":"probe"`)
+
+	// Sending the redacted value back keeps the stored password
+	body, _ = json.Marshal(map[string]any{
+		"auth": map[string]string{"type": "basic", "username": "probe2", "password": "********"},
+	})
+	req := adminRequest(http.MethodPatch, "/monitor/"+created.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	monitor, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.RequestAuth{Type: models.AuthBasic, Username: "probe2", Password: "hunter2"}, monitor.Auth)
+}
+
+// Test credentials that can't be decrypted fail only their own monitor
+func TestMonitorCredentials_Unreadable(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "healthy")
+
+	body, _ := json.Marshal(map[string]any{
+		"url":  "https://www.example.com",
+		"auth": map[string]string{"type": "bearer", "token": "s3cret"},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	// As if the secrets key changed since the credentials were stored
+	var sealed string
+	require.NoError(t, db.GetDB().QueryRow(`SELECT auth FROM monitors WHERE id = ?`, created.ID).Scan(&sealed))
+	require.NoError(t, secrets.Init("another passphrase", ""))
+	t.Cleanup(func() { secrets.Init("test passphrase", "") })
+
+	monitors, err := db.GetAllMonitors(db.AllTeams)
+	require.NoError(t, err)
+	assert.Len(t, monitors, 2)
+
+	rr = httptest.NewRecorder()
+	ListMonitors(rr, adminRequest(http.MethodGet, "/monitor", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"config_error"`)
+
+	monitor, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, monitor.ConfigError)
+	assert.Equal(t, models.FailureConfigError, checks.Run(monitor).FailureReason)
+
+	// Other changes keep the stored credentials for when the old key is back
+	body, _ = json.Marshal(map[string]any{"check_interval": 120})
+	req := adminRequest(http.MethodPatch, "/monitor/"+created.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var stored string
+	require.NoError(t, db.GetDB().QueryRow(`SELECT auth FROM monitors WHERE id = ?`, created.ID).Scan(&stored))
+	assert.Equal(t, sealed, stored)
+}
+
+// Test a PUT without auth drops credentials that can't be decrypted
+func TestMonitorCredentials_UnreadableDroppedByPut(t *testing.T) {
+	setupTestDB(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	body, _ := json.Marshal(map[string]any{
+		"url":  target.URL,
+		"auth": map[string]string{"type": "bearer", "token": "s3cret"},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	require.NoError(t, secrets.Init("another passphrase", ""))
+	t.Cleanup(func() { secrets.Init("test passphrase", "") })
+
+	body, _ = json.Marshal(map[string]any{"url": target.URL, "check_interval": 60})
+	req := adminRequest(http.MethodPut, "/monitor/"+created.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var stored sql.NullString
+	require.NoError(t, db.GetDB().QueryRow(`SELECT auth FROM monitors WHERE id = ?`, created.ID).Scan(&stored))
+	assert.False(t, stored.Valid)
+
+	monitor, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, monitor.ConfigError)
+
+	result := checks.Run(monitor)
+	assert.NotEqual(t, models.FailureConfigError, result.FailureReason)
+	assert.True(t, result.IsUp)
+}
+
+// Test tcp monitors are validated by their own checker and keep their type
+func TestCreateMonitor_TCPType(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"type": "tcp",
+		"url":  "https://www.example.com",
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body, _ = json.Marshal(map[string]any{
+		"type":       "tcp",
+		"url":        "tcp://mail.example.com:25",
+		"assertions": []map[string]any{{"type": "body_contains", "value": "220"}},
+	})
+	rr = httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	// PATCH keeps the type, so a tcp:// URL is still accepted
+	body, _ = json.Marshal(map[string]any{
+		"url": "tcp://mail.example.com:587",
+	})
+	req := adminRequest(http.MethodPatch, "/monitor/"+created.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	monitor, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MonitorTCP, monitor.Type)
+	assert.Equal(t, "tcp://mail.example.com:587", monitor.URL)
+
+	// Monitors created without a type are http
+	saveTestMonitor(t, "http-monitor")
+	monitor, err = db.GetMonitor(db.AllTeams, "http-monitor")
+	require.NoError(t, err)
+	assert.Equal(t, models.MonitorHTTP, monitor.Type)
+}
+
+// Test dns monitors keep their record config
+func TestCreateMonitor_DNSType(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"type": "dns",
+		"url":  "dns://example.com",
+		"dns":  map[string]any{"record_type": "MX", "expected": []string{"10 mail.example.com"}},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	stored, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MonitorDNS, stored.Type)
+	assert.Equal(t, &models.DNSConfig{RecordType: models.RecordMX, Expected: []string{"10 mail.example.com"}}, stored.DNS)
+}
+
+func TestCreateMonitor_RetrySettings(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"url":              "https://example.com",
+		"retries":          3,
+		"retry_delay":      10,
+		"confirm_failures": true,
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+
+	stored, err := db.GetMonitor(db.AllTeams, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Retries)
+	assert.Equal(t, 10, stored.RetryDelay)
+	assert.True(t, stored.ConfirmFailures)
+
+	body, _ = json.Marshal(map[string]any{"retries": 20})
+	req := adminRequest(http.MethodPatch, "/monitor/"+created.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", created.ID)
+	rr = httptest.NewRecorder()
+	UpdateMonitor(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "retries must be between 0 and 5")
+}
+
+func TestUpdateMonitor_RequestLimits(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "limits")
+
+	patch := func(fields map[string]any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(fields)
+		req := adminRequest(http.MethodPatch, "/monitor/limits", bytes.NewBuffer(body))
+		req.SetPathValue("id", "limits")
+		rr := httptest.NewRecorder()
+		UpdateMonitor(rr, req)
+		return rr
+	}
+
+	rr := patch(map[string]any{"timeout": 5, "follow_redirects": false, "max_body_bytes": 2048, "ignore_tls_errors": true})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	stored, err := db.GetMonitor(db.AllTeams, "limits")
+	require.NoError(t, err)
+	assert.Equal(t, 5, stored.Timeout)
+	require.NotNil(t, stored.FollowRedirects)
+	assert.False(t, *stored.FollowRedirects)
+	assert.Equal(t, int64(2048), stored.MaxBodyBytes)
+	assert.True(t, stored.IgnoreTLSErrors)
+
+	// null goes back to following redirects
+	rr = patch(map[string]any{"follow_redirects": nil})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stored, err = db.GetMonitor(db.AllTeams, "limits")
+	require.NoError(t, err)
+	assert.Nil(t, stored.FollowRedirects)
+	assert.Equal(t, 5, stored.Timeout)
+
+	rr = patch(map[string]any{"timeout": 600})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
 // <---- VALIDATION CASES ----->
@@ -292,6 +742,15 @@ func TestCreateMonitor_ValidationCases(t *testing.T) {
             expectedStatus: http.StatusBadRequest,
             expectedError:  "Invalid URL",
         },
+        {
+            name: "Negative check interval",
+            requestBody: map[string]interface{}{
+                "url":            "https://example.com",
+                "check_interval": -60,
+            },
+            expectedStatus: http.StatusBadRequest,
+            expectedError:  "check_interval must not be negative",
+        },
     }
     
     for _, tt := range tests {
@@ -299,7 +758,7 @@ func TestCreateMonitor_ValidationCases(t *testing.T) {
 			setupTestDB(t)
 			
             body, _ := json.Marshal(tt.requestBody)
-            req := httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
+            req := adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body))
             req.Header.Set("Content-Type", "application/json")
             rr := httptest.NewRecorder()
             
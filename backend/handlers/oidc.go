@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to start a single sign-on login by sending the browser to the identity provider
+*/
+func OIDCLogin(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.oidc_login")
+	defer span.Finish()
+
+	if auth.SSO == nil {
+		writeError(response, http.StatusNotFound, "Single sign-on is not configured")
+		return
+	}
+
+	authURL, state, err := auth.SSO.Begin()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to start single sign-on")
+		return
+	}
+
+	// The callback has to come back to the browser that started the login
+	http.SetCookie(response, &http.Cookie{
+		Name:     auth.OIDCStateCookie,
+		Value:    state,
+		Path:     "/login/oidc",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(response, request, authURL, http.StatusFound)
+}
+
+/*
+Function the identity provider sends the browser back to. The code is traded for
+an ID token, which logs the user in like a password login would.
+*/
+func OIDCCallback(response http.ResponseWriter, request *http.Request) {
+	span, ctx := tracer.StartSpanFromContext(request.Context(), "handler.oidc_callback")
+	defer span.Finish()
+
+	if auth.SSO == nil {
+		writeError(response, http.StatusNotFound, "Single sign-on is not configured")
+		return
+	}
+
+	query := request.URL.Query()
+
+	if providerError := query.Get("error"); providerError != "" {
+		writeError(response, http.StatusUnauthorized, "Identity provider refused the login: "+providerError)
+		return
+	}
+
+	cookie, err := request.Cookie(auth.OIDCStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != query.Get("state") {
+		writeError(response, http.StatusBadRequest, "Login state doesn't match, start the login again")
+		return
+	}
+
+	user, changes, err := auth.SSO.Finish(ctx, query.Get("state"), query.Get("code"))
+	if errors.Is(err, auth.ErrNoRole) {
+		writeError(response, http.StatusForbidden, "None of your groups has access")
+		return
+	}
+	if errors.Is(err, auth.ErrUnverifiedEmail) {
+		writeError(response, http.StatusForbidden, "Your identity provider hasn't verified your email, so it can't be linked to an existing account")
+		return
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusUnauthorized, "Single sign-on failed")
+		return
+	}
+
+	// Nobody is logged in yet, so these are recorded as made by the system
+	for _, change := range changes {
+		recordSSOChange(ctx, change)
+	}
+
+	token, expiresAt, err := auth.CreateSession(user.ID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to start session")
+		return
+	}
+
+	span.SetTag("user.id", user.ID)
+
+	http.SetCookie(response, &http.Cookie{
+		Name:     auth.OIDCStateCookie,
+		Value:    "",
+		Path:     "/login/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	setSessionCookie(response, request, token, expiresAt)
+
+	http.Redirect(response, request, auth.SSO.AfterLogin(), http.StatusFound)
+}
+
+// recordSSOChange puts a change a login made to its user in the audit log
+func recordSSOChange(ctx context.Context, change auth.SSOChange) {
+	var before any
+	if change.Before != nil {
+		before = *change.Before
+	}
+
+	var after any = change.After
+	if change.Issuer != "" {
+		after = struct {
+			models.User
+			SSOIssuer  string `json:"sso_issuer"`
+			SSOSubject string `json:"sso_subject"`
+		}{change.After, change.Issuer, change.Subject}
+	}
+
+	audit.Record(ctx, userChange(change.Action, change.After), before, after)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/incidents"
+	"github.com/KerlynD/URL-Monitor/backend/metrics"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Kinds of ping a heartbeat monitor's job can send
+const (
+	pingSuccess = "success"
+	pingStart   = "start"
+	pingFail    = "fail"
+)
+
+// Longest failure message kept from a /fail ping's body
+const maxPingMessage = 1024
+
+/*
+Function to record a successful run of a heartbeat monitor's job
+*/
+func Ping(response http.ResponseWriter, request *http.Request) {
+	recordPing(response, request, pingSuccess)
+}
+
+/*
+Function to mark a heartbeat monitor's job as started, so the next ping carries its run time
+*/
+func PingStart(response http.ResponseWriter, request *http.Request) {
+	recordPing(response, request, pingStart)
+}
+
+/*
+Function to record a failed run of a heartbeat monitor's job. The request body, if
+any, is kept as the failure message.
+*/
+func PingFail(response http.ResponseWriter, request *http.Request) {
+	recordPing(response, request, pingFail)
+}
+
+func recordPing(response http.ResponseWriter, request *http.Request, kind string) {
+	/*
+		This function looks the monitor up by the token in the path (the token is the
+		only credential a ping needs), then stores success and fail pings as results
+		and updates the monitor's incident state, just like a polled check. Pings to
+		a paused monitor are accepted but not recorded.
+	*/
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.ping")
+	defer span.Finish()
+
+	span.SetTag("ping.kind", kind)
+
+	monitor, err := db.GetMonitorByPingToken(request.PathValue("token"))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Unknown ping token")
+		return
+	}
+
+	span.SetTag("monitor.id", monitor.ID)
+
+	// The job keeps pinging while its monitor is paused; answering 200 keeps it from retrying
+	if monitor.Paused {
+		span.SetTag("ping.ignored", true)
+		response.WriteHeader(http.StatusOK)
+		json.NewEncoder(response).Encode(map[string]string{"status": "paused"})
+		return
+	}
+
+	if metrics.Client != nil {
+		metrics.Client.Incr("heartbeats.pings",
+			append(metrics.MonitorTags(monitor), "kind:"+kind), 1.0)
+	}
+
+	now := time.Now()
+
+	if kind == pingStart {
+		err = db.SetPingStarted(monitor.ID, &now)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			writeError(response, http.StatusInternalServerError, "Failed to record ping")
+			return
+		}
+
+		response.WriteHeader(http.StatusOK)
+		json.NewEncoder(response).Encode(map[string]string{"status": "started"})
+		return
+	}
+
+	result := models.MonitorResult{
+		Timestamp: now,
+		IsUp:      kind == pingSuccess,
+	}
+
+	// A run that announced its start reports how long it took
+	if monitor.PingStartedAt != nil {
+		result.RunTime = now.Sub(*monitor.PingStartedAt)
+
+		err = db.SetPingStarted(monitor.ID, nil)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+		}
+	}
+
+	if kind == pingFail {
+		result.Error = "job reported failure"
+
+		message, _ := io.ReadAll(io.LimitReader(request.Body, maxPingMessage))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			result.Error += ": " + text
+		}
+	}
+
+	err = db.SaveResult(monitor.ID, result)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to record ping")
+		return
+	}
+
+	_, err = incidents.Evaluate(monitor, result)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(result)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createHeartbeatMonitor creates a heartbeat monitor through the API and returns it
+func createHeartbeatMonitor(t *testing.T) models.MonitorEntry {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]any{
+		"type":      "heartbeat",
+		"url":       "heartbeat://nightly-backup",
+		"heartbeat": map[string]int{"period": 86400, "grace": 600},
+	})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, adminRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	return created
+}
+
+// sendPing calls the ping handler for a token, with an optional body
+func sendPing(t *testing.T, handler http.HandlerFunc, token string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := adminRequest(http.MethodPost, "/ping/"+token, strings.NewReader(body))
+	req.SetPathValue("token", token)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+func TestPing_StoresResultsAndIncidents(t *testing.T) {
+	setupTestDB(t)
+	monitor := createHeartbeatMonitor(t)
+	require.Len(t, monitor.PingToken, 32)
+
+	rr := sendPing(t, Ping, monitor.PingToken, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = sendPing(t, PingFail, monitor.PingToken, "disk full\n")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	results, err := db.GetResults(monitor.ID, db.ResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].IsUp)
+	assert.Equal(t, "job reported failure: disk full", results[0].Error)
+	assert.True(t, results[1].IsUp)
+
+	open, err := db.GetOpenIncident(monitor.ID)
+	require.NoError(t, err)
+	require.NotNil(t, open, "a failed run opens an incident like a failed check")
+
+	sendPing(t, Ping, monitor.PingToken, "")
+
+	open, err = db.GetOpenIncident(monitor.ID)
+	require.NoError(t, err)
+	assert.Nil(t, open)
+}
+
+func TestPing_StartRecordsRunTime(t *testing.T) {
+	setupTestDB(t)
+	monitor := createHeartbeatMonitor(t)
+
+	rr := sendPing(t, PingStart, monitor.PingToken, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stored, err := db.GetMonitor(db.AllTeams, monitor.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.PingStartedAt)
+
+	time.Sleep(20 * time.Millisecond)
+	rr = sendPing(t, Ping, monitor.PingToken, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var result models.MonitorResult
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+	assert.True(t, result.IsUp)
+	assert.GreaterOrEqual(t, result.RunTime, 20*time.Millisecond)
+	assert.Zero(t, result.ResponseTime, "a job's run time isn't a response time")
+
+	stored, err = db.GetMonitor(db.AllTeams, monitor.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.PingStartedAt, "the run is over")
+
+	// Only success and fail pings are results
+	results, err := db.GetResults(monitor.ID, db.ResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, result.RunTime.Truncate(time.Millisecond), results[0].RunTime)
+}
+
+func TestPing_IgnoredWhilePaused(t *testing.T) {
+	setupTestDB(t)
+	monitor := createHeartbeatMonitor(t)
+
+	monitor.Paused = true
+	require.NoError(t, db.UpdateMonitor(monitor))
+
+	for _, handler := range []http.HandlerFunc{PingStart, PingFail, Ping} {
+		rr := sendPing(t, handler, monitor.PingToken, "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"paused"`)
+	}
+
+	results, err := db.GetResults(monitor.ID, db.ResultFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	stored, err := db.GetMonitor(db.AllTeams, monitor.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.PingStartedAt)
+
+	open, err := db.GetOpenIncident(monitor.ID)
+	require.NoError(t, err)
+	assert.Nil(t, open)
+}
+
+func TestPing_UnknownToken(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "http-monitor")
+
+	rr := sendPing(t, Ping, "not-a-token", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Polled monitors have no token, so an empty one must not match them
+	rr = sendPing(t, Ping, "", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestTriggerCheck_RejectsHeartbeat(t *testing.T) {
+	setupTestDB(t)
+	monitor := createHeartbeatMonitor(t)
+
+	req := adminRequest(http.MethodPost, "/monitor/"+monitor.ID+"/check", nil)
+	req.SetPathValue("id", monitor.ID)
+	rr := httptest.NewRecorder()
+	TriggerCheck(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+const (
+	defaultResultsLimit = 50
+	maxResultsLimit     = 500
+)
+
+/*
+Function to list a monitor's check history, newest first, one page at a time
+*/
+func GetMonitorResults(response http.ResponseWriter, request *http.Request) {
+	/*
+		This function parses the from/to/limit/cursor/is_up/status_min/status_max query
+		parameters, makes sure the monitor exists, then fetches one more row than the
+		limit so it knows whether to hand back a cursor for the next page.
+	*/
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.get_monitor_results")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	filter, err := parseResultFilter(request.URL.Query())
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	results, err := db.GetResults(id, filter)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to get results")
+		return
+	}
+
+	page := models.ResultPage{Results: results}
+
+	if len(results) > limit {
+		page.Results = results[:limit]
+		last := page.Results[limit-1]
+		page.NextCursor = encodeResultCursor(db.ResultCursor{Timestamp: last.Timestamp, ID: last.ID})
+	}
+
+	span.SetTag("results.count", len(page.Results))
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(page)
+}
+
+// parseResultFilter turns history query parameters into a db.ResultFilter
+func parseResultFilter(query url.Values) (db.ResultFilter, error) {
+	filter := db.ResultFilter{Limit: defaultResultsLimit}
+	var err error
+
+	if from := query.Get("from"); from != "" {
+		filter.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, fmt.Errorf("from must be an RFC 3339 timestamp")
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		filter.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, fmt.Errorf("to must be an RFC 3339 timestamp")
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxResultsLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxResultsLimit)
+		}
+	}
+
+	if isUp := query.Get("is_up"); isUp != "" {
+		value, err := strconv.ParseBool(isUp)
+		if err != nil {
+			return filter, fmt.Errorf("is_up must be true or false")
+		}
+		filter.IsUp = &value
+	}
+
+	if statusMin := query.Get("status_min"); statusMin != "" {
+		filter.StatusMin, err = strconv.Atoi(statusMin)
+		if err != nil {
+			return filter, fmt.Errorf("status_min must be a number")
+		}
+	}
+
+	if statusMax := query.Get("status_max"); statusMax != "" {
+		filter.StatusMax, err = strconv.Atoi(statusMax)
+		if err != nil {
+			return filter, fmt.Errorf("status_max must be a number")
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		decoded, err := decodeResultCursor(cursor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor")
+		}
+		filter.Cursor = &decoded
+	}
+
+	return filter, nil
+}
+
+// encodeResultCursor packs a (timestamp, id) position into an opaque string
+func encodeResultCursor(cursor db.ResultCursor) string {
+	raw := cursor.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeResultCursor reverses encodeResultCursor
+func decodeResultCursor(encoded string) (db.ResultCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return db.ResultCursor{}, err
+	}
+
+	timestamp, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return db.ResultCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	cursor := db.ResultCursor{}
+
+	cursor.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return db.ResultCursor{}, err
+	}
+
+	cursor.ID, err = strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return db.ResultCursor{}, err
+	}
+
+	return cursor, nil
+}
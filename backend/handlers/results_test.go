@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveTestResults stores one result per minute going back from base, alternating up/down
+func saveTestResults(t *testing.T, monitorID string, base time.Time, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		isUp := i%2 == 0
+		statusCode := 200
+		if !isUp {
+			statusCode = 503
+		}
+
+		err := db.SaveResult(monitorID, models.MonitorResult{
+			StatusCode:   statusCode,
+			ResponseTime: 100 * time.Millisecond,
+			IsUp:         isUp,
+			Timestamp:    base.Add(-time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+}
+
+// getResultsPage calls GetMonitorResults with a raw query string
+func getResultsPage(t *testing.T, monitorID string, query string) (int, models.ResultPage) {
+	t.Helper()
+	req := adminRequest(http.MethodGet, "/monitor/"+monitorID+"/results?"+query, nil)
+	req.SetPathValue("id", monitorID)
+	rr := httptest.NewRecorder()
+
+	GetMonitorResults(rr, req)
+
+	var page models.ResultPage
+	json.NewDecoder(rr.Body).Decode(&page)
+	return rr.Code, page
+}
+
+func TestGetMonitorResults_Paginates(t *testing.T) {
+	// Walking the cursor returns every result exactly once, newest first
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+	base := time.Now()
+	saveTestResults(t, "test-monitor", base, 7)
+
+	var seen []models.MonitorResult
+	query := "limit=3"
+
+	for pages := 0; pages < 5; pages++ {
+		code, page := getResultsPage(t, "test-monitor", query)
+		require.Equal(t, http.StatusOK, code)
+		seen = append(seen, page.Results...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		query = "limit=3&cursor=" + page.NextCursor
+	}
+
+	require.Len(t, seen, 7)
+	for i := 1; i < len(seen); i++ {
+		assert.True(t, seen[i-1].Timestamp.After(seen[i].Timestamp))
+	}
+}
+
+func TestGetMonitorResults_Filters(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+	base := time.Now()
+	saveTestResults(t, "test-monitor", base, 10)
+
+	// Only failed checks
+	code, page := getResultsPage(t, "test-monitor", "is_up=false")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, page.Results, 5)
+	for _, result := range page.Results {
+		assert.False(t, result.IsUp)
+	}
+
+	// Status code range
+	_, page = getResultsPage(t, "test-monitor", "status_min=500&status_max=599")
+	assert.Len(t, page.Results, 5)
+
+	// Time range covering the three most recent checks
+	from := base.Add(-150 * time.Second).UTC().Format(time.RFC3339)
+	_, page = getResultsPage(t, "test-monitor", "from="+from)
+	assert.Len(t, page.Results, 3)
+}
+
+func TestGetMonitorResults_BadRequests(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	code, _ := getResultsPage(t, "test-monitor", "cursor=not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = getResultsPage(t, "test-monitor", "limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = getResultsPage(t, "missing", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestGetMonitorResults_IncludesTimingsAndCertificate(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "monitor1")
+
+	timings := &models.CheckTimings{
+		DNSLookup:       1500 * time.Microsecond,
+		TCPConnect:      2 * time.Millisecond,
+		TLSHandshake:    15 * time.Millisecond,
+		TimeToFirstByte: 80 * time.Millisecond,
+		ContentTransfer: 250 * time.Microsecond,
+	}
+	certificate := &models.CertificateInfo{
+		Subject:       "CN=www.example.com",
+		Issuer:        "CN=Example CA",
+		SANs:          []string{"www.example.com", "example.com"},
+		NotAfter:      time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		DaysRemaining: 9,
+	}
+	require.NoError(t, db.SaveResult("monitor1", models.MonitorResult{
+		StatusCode:  200,
+		IsUp:        true,
+		Timestamp:   time.Now(),
+		Timings:     timings,
+		Certificate: certificate,
+		Warning:     "certificate expires in 9 days",
+	}))
+
+	status, page := getResultsPage(t, "monitor1", "")
+
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, timings, page.Results[0].Timings)
+	assert.Equal(t, certificate, page.Results[0].Certificate)
+	assert.Equal(t, "certificate expires in 9 days", page.Results[0].Warning)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Windows accepted by GET /monitor/{id}/stats
+var statsWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+/*
+Function to report uptime, check counts, latency percentiles and the longest outage
+for a monitor over a window (24h, 7d, 30d or 90d)
+*/
+func GetMonitorStats(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.get_monitor_stats")
+	defer span.Finish()
+
+	id := request.PathValue("id")
+
+	window := request.URL.Query().Get("window")
+	if window == "" {
+		window = "24h"
+	}
+
+	windowDuration, ok := statsWindows[window]
+	if !ok {
+		writeError(response, http.StatusBadRequest, "window must be one of 24h, 7d, 30d, 90d")
+		return
+	}
+	span.SetTag("stats.window", window)
+
+	_, err := db.GetMonitor(tenantOf(request), id)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "Monitor not found")
+		return
+	}
+
+	now := time.Now()
+	stats, err := db.GetMonitorStats(id, now.Add(-windowDuration), now)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to compute stats")
+		return
+	}
+	stats.Window = window
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(stats)
+}
+
+/*
+Function to build the 24h uptime / p95 summary shown alongside a monitor, nil if
+there were no checks in the last day
+*/
+func statsSummary(parent tracer.Span, monitorID string) *models.StatsSummary {
+	summarySpan := tracer.StartSpan("db.get_stats_summary", tracer.ChildOf(parent.Context()))
+	defer summarySpan.Finish()
+
+	now := time.Now()
+	stats, err := db.GetMonitorStats(monitorID, now.Add(-24*time.Hour), now)
+	if err != nil || stats.TotalChecks == 0 {
+		return nil
+	}
+
+	return &models.StatsSummary{
+		Uptime24h:       stats.UptimePercent,
+		P95ResponseTime: stats.P95ResponseTime,
+	}
+}
+
+/*
+Function to build the 24h summaries of every monitor the tenant sees in one query,
+for listing monitors. Monitors with no checks in the last day have none.
+*/
+func statsSummaries(parent tracer.Span, tenant db.Tenant) map[string]models.StatsSummary {
+	summarySpan := tracer.StartSpan("db.get_stats_summaries", tracer.ChildOf(parent.Context()))
+	defer summarySpan.Finish()
+
+	now := time.Now()
+	summaries, err := db.GetStatsSummaries(tenant, now.Add(-24*time.Hour), now)
+	if err != nil {
+		summarySpan.SetTag("error", true)
+		summarySpan.SetTag("error.message", err.Error())
+		return nil
+	}
+
+	return summaries
+}
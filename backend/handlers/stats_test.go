@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMonitorStats_Success(t *testing.T) {
+	// 10 checks a minute apart: 3 failures in a row, then 1 more on its own
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	base := time.Now().Add(-time.Hour)
+	down := map[int]bool{2: true, 3: true, 4: true, 7: true}
+
+	for i := 0; i < 10; i++ {
+		err := db.SaveResult("test-monitor", models.MonitorResult{
+			StatusCode:   200,
+			ResponseTime: time.Duration(i+1) * 10 * time.Millisecond,
+			IsUp:         !down[i],
+			Timestamp:    base.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	req := adminRequest(http.MethodGet, "/monitor/test-monitor/stats?window=7d", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	GetMonitorStats(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var stats models.MonitorStats
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&stats))
+
+	assert.Equal(t, "7d", stats.Window)
+	assert.Equal(t, 10, stats.TotalChecks)
+	assert.Equal(t, 4, stats.FailedChecks)
+	assert.InDelta(t, 60.0, stats.UptimePercent, 0.001)
+	assert.Equal(t, 55*time.Millisecond, stats.MeanResponseTime)
+	assert.Equal(t, 50*time.Millisecond, stats.P50ResponseTime)
+	assert.Equal(t, 100*time.Millisecond, stats.P95ResponseTime)
+	assert.Equal(t, 100*time.Millisecond, stats.P99ResponseTime)
+	assert.Equal(t, 3*time.Minute, stats.LongestOutage)
+}
+
+func TestGetMonitorStats_InvalidWindow(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	req := adminRequest(http.MethodGet, "/monitor/test-monitor/stats?window=1y", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr := httptest.NewRecorder()
+
+	GetMonitorStats(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestListMonitors_IncludesSummary(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+	saveTestResults(t, "test-monitor", time.Now(), 4)
+
+	req := adminRequest(http.MethodGet, "/monitor", nil)
+	rr := httptest.NewRecorder()
+
+	ListMonitors(rr, req)
+
+	var monitors []models.MonitorWithStatus
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&monitors))
+	require.Len(t, monitors, 1)
+	require.NotNil(t, monitors[0].Summary)
+	assert.InDelta(t, 50.0, monitors[0].Summary.Uptime24h, 0.001)
+	assert.Equal(t, 100*time.Millisecond, monitors[0].Summary.P95ResponseTime)
+}
+
+func TestListMonitors_SummariesMatchStats(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "busy")
+	saveTestMonitor(t, "quiet")
+	saveTestMonitor(t, "unchecked")
+	saveTestResults(t, "quiet", time.Now(), 3)
+
+	// 20 checks with rising response times, every fifth one down
+	for i := 0; i < 20; i++ {
+		err := db.SaveResult("busy", models.MonitorResult{
+			StatusCode:   200,
+			ResponseTime: time.Duration(i+1) * 10 * time.Millisecond,
+			IsUp:         i%5 != 0,
+			Timestamp:    time.Now().Add(-time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	rr := httptest.NewRecorder()
+	ListMonitors(rr, adminRequest(http.MethodGet, "/monitor", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var monitors []models.MonitorWithStatus
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&monitors))
+	require.Len(t, monitors, 3)
+
+	summaries := map[string]*models.StatsSummary{}
+	for _, monitor := range monitors {
+		summaries[monitor.ID] = monitor.Summary
+	}
+
+	require.NotNil(t, summaries["busy"])
+	assert.InDelta(t, 80.0, summaries["busy"].Uptime24h, 0.001)
+	assert.Equal(t, 190*time.Millisecond, summaries["busy"].P95ResponseTime)
+
+	require.NotNil(t, summaries["quiet"])
+	assert.InDelta(t, 200.0/3, summaries["quiet"].Uptime24h, 0.001)
+	assert.Equal(t, 100*time.Millisecond, summaries["quiet"].P95ResponseTime)
+
+	assert.Nil(t, summaries["unchecked"])
+
+	// The same numbers as the full stats
+	stats, err := db.GetMonitorStats("busy", time.Now().Add(-24*time.Hour), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, stats.P95ResponseTime, summaries["busy"].P95ResponseTime)
+	assert.InDelta(t, stats.UptimePercent, summaries["busy"].Uptime24h, 0.001)
+}
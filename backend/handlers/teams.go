@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/google/uuid"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to create an organization to group teams under
+*/
+func CreateOrganization(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.create_organization")
+	defer span.Finish()
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(response, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	org := models.Organization{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		CreatedAt: time.Now(),
+	}
+
+	err = db.SaveOrganization(org)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to save organization to DB")
+		return
+	}
+
+	audit.Record(request.Context(), models.AuditEntry{
+		Action:     "organization.create",
+		TargetType: models.AuditOrganization,
+		TargetID:   org.ID,
+	}, nil, org)
+
+	// Return 201
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(org)
+}
+
+/*
+Function to list every organization
+*/
+func ListOrganizations(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_organizations")
+	defer span.Finish()
+
+	orgs, err := db.GetOrganizations()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch organizations from db")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(orgs)
+}
+
+/*
+Function to create a team in an organization. Team names are unique within
+their organization.
+*/
+func CreateTeam(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.create_team")
+	defer span.Finish()
+
+	var req struct {
+		OrgID string `json:"org_id"`
+		Name  string `json:"name"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(response, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	_, err = db.GetOrganization(req.OrgID)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, "org_id must be an existing organization")
+		return
+	}
+
+	teams, err := db.GetTeams(db.AllTeams)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch teams from db")
+		return
+	}
+
+	for _, team := range teams {
+		if team.OrgID == req.OrgID && team.Name == req.Name {
+			writeError(response, http.StatusConflict, "The organization already has a team with that name")
+			return
+		}
+	}
+
+	team := models.Team{
+		ID:        uuid.New().String(),
+		OrgID:     req.OrgID,
+		Name:      req.Name,
+		CreatedAt: time.Now(),
+	}
+
+	err = db.SaveTeam(team)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to save team to DB")
+		return
+	}
+
+	audit.Record(request.Context(), teamChange("team.create", team), nil, team)
+
+	// Return 201
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(team)
+}
+
+/*
+Function to list the teams the caller can see: every team for admins, otherwise
+the caller's own
+*/
+func ListTeams(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_teams")
+	defer span.Finish()
+
+	teams, err := db.GetTeams(tenantOf(request))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch teams from db")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(teams)
+}
+
+/*
+Function to add a user to a team, giving them its monitors and channels
+*/
+func AddTeamMember(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.add_team_member")
+	defer span.Finish()
+
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	team, err := db.GetTeam(tenantOf(request), request.PathValue("id"))
+	if err != nil {
+		writeError(response, http.StatusNotFound, "Team not found")
+		return
+	}
+
+	_, err = db.GetUser(req.UserID)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, "user_id must be an existing user")
+		return
+	}
+
+	err = db.AddTeamMember(team.ID, req.UserID, time.Now())
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to add team member")
+		return
+	}
+
+	audit.Record(request.Context(), teamChange("team.add_member", team), nil, map[string]string{"user_id": req.UserID})
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
+
+/*
+Function to remove a user from a team
+*/
+func RemoveTeamMember(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.remove_team_member")
+	defer span.Finish()
+
+	team, err := db.GetTeam(tenantOf(request), request.PathValue("id"))
+	if err != nil {
+		writeError(response, http.StatusNotFound, "Team not found")
+		return
+	}
+
+	userID := request.PathValue("user_id")
+
+	err = db.RemoveTeamMember(team.ID, userID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to remove team member")
+		return
+	}
+
+	audit.Record(request.Context(), teamChange("team.remove_member", team), map[string]string{"user_id": userID}, nil)
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// asCaller returns the request as the auth middleware would pass it on for caller
+func asCaller(request *http.Request, caller auth.Caller) *http.Request {
+	return request.WithContext(auth.WithCaller(request.Context(), caller))
+}
+
+// adminRequest is httptest.NewRequest as an admin would send it through the auth middleware
+func adminRequest(method string, target string, body io.Reader) *http.Request {
+	return asCaller(httptest.NewRequest(method, target, body), auth.Caller{Scope: models.ScopeAdmin})
+}
+
+// saveTestTeam stores a team in the default organization
+func saveTestTeam(t *testing.T, id string) {
+	t.Helper()
+	require.NoError(t, db.SaveTeam(models.Team{ID: id, OrgID: models.DefaultTeam, Name: id, CreatedAt: time.Now()}))
+}
+
+func TestTenants_OnlySeeTheirTeamsMonitors(t *testing.T) {
+	setupTestDB(t)
+	saveTestTeam(t, "payments")
+
+	saveTestMonitor(t, "theirs") // <- Default team
+	require.NoError(t, db.SaveMonitor(models.MonitorEntry{
+		ID:            "mine",
+		TeamID:        "payments",
+		URL:           "https://payments.internal",
+		CheckInterval: 60,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}))
+
+	editor := auth.Caller{Scope: models.ScopeWrite, Teams: []string{"payments"}}
+
+	rr := httptest.NewRecorder()
+	ListMonitors(rr, asCaller(httptest.NewRequest(http.MethodGet, "/monitor", nil), editor))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "payments.internal")
+	assert.NotContains(t, rr.Body.String(), `"theirs"`)
+
+	// Another team's monitor doesn't exist as far as the caller can tell
+	req := asCaller(httptest.NewRequest(http.MethodGet, "/monitor/theirs", nil), editor)
+	req.SetPathValue("id", "theirs")
+	rr = httptest.NewRecorder()
+	GetMonitor(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Admins see every team
+	admin := auth.Caller{Scope: models.ScopeAdmin}
+	rr = httptest.NewRecorder()
+	ListMonitors(rr, asCaller(httptest.NewRequest(http.MethodGet, "/monitor", nil), admin))
+	assert.Contains(t, rr.Body.String(), `"theirs"`)
+}
+
+func TestTenants_RequestsWithoutACallerSeeNothing(t *testing.T) {
+	setupTestDB(t)
+	saveTestMonitor(t, "test-monitor")
+
+	rr := httptest.NewRecorder()
+	ListMonitors(rr, httptest.NewRequest(http.MethodGet, "/monitor", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "test-monitor")
+
+	req := httptest.NewRequest(http.MethodDelete, "/monitor/test-monitor", nil)
+	req.SetPathValue("id", "test-monitor")
+	rr = httptest.NewRecorder()
+	DeleteMonitor(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestTenants_TeamKeysOnlySeeTheirTeam(t *testing.T) {
+	setupTestDB(t)
+	saveTestTeam(t, "payments")
+	saveTestMonitor(t, "theirs") // <- Default team
+
+	// Even with the admin scope a team's key stays in its team
+	key := auth.Caller{Scope: models.ScopeAdmin, Key: &models.APIKey{ID: "key", TeamID: "payments", Scope: models.ScopeAdmin}}
+
+	rr := httptest.NewRecorder()
+	ListMonitors(rr, asCaller(httptest.NewRequest(http.MethodGet, "/monitor", nil), key))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), `"theirs"`)
+}
+
+func TestTenants_NewMonitorsBelongToTheCallersTeam(t *testing.T) {
+	setupTestDB(t)
+	saveTestTeam(t, "payments")
+
+	editor := auth.Caller{Scope: models.ScopeWrite, Teams: []string{"payments"}}
+
+	body, _ := json.Marshal(map[string]any{"url": "https://payments.internal", "check_interval": 60})
+	rr := httptest.NewRecorder()
+	CreateMonitor(rr, asCaller(httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)), editor))
+
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var created models.MonitorEntry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, "payments", created.TeamID)
+
+	// A team the caller isn't in can't be picked
+	body, _ = json.Marshal(map[string]any{"url": "https://payments.internal", "check_interval": 60, "team_id": models.DefaultTeam})
+	rr = httptest.NewRecorder()
+	CreateMonitor(rr, asCaller(httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)), editor))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// With several teams the caller has to say which
+	saveTestTeam(t, "search")
+	editor.Teams = append(editor.Teams, "search")
+	body, _ = json.Marshal(map[string]any{"url": "https://search.internal", "check_interval": 60})
+	rr = httptest.NewRecorder()
+	CreateMonitor(rr, asCaller(httptest.NewRequest(http.MethodPost, "/monitor", bytes.NewBuffer(body)), editor))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestTeams_CreateAndAddMembers(t *testing.T) {
+	setupTestDB(t)
+
+	body, _ := json.Marshal(map[string]string{"name": "Engineering"})
+	rr := httptest.NewRecorder()
+	CreateOrganization(rr, adminRequest(http.MethodPost, "/orgs", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var org models.Organization
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&org))
+
+	body, _ = json.Marshal(map[string]string{"org_id": org.ID, "name": "Payments"})
+	rr = httptest.NewRecorder()
+	CreateTeam(rr, adminRequest(http.MethodPost, "/teams", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var team models.Team
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&team))
+
+	// Names are unique within an organization
+	rr = httptest.NewRecorder()
+	CreateTeam(rr, adminRequest(http.MethodPost, "/teams", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	user, err := auth.CreateUser("alice@example.com", "correct horse", models.RoleEditor)
+	require.NoError(t, err)
+
+	body, _ = json.Marshal(map[string]string{"user_id": user.ID})
+	req := adminRequest(http.MethodPost, "/teams/"+team.ID+"/members", bytes.NewBuffer(body))
+	req.SetPathValue("id", team.ID)
+	rr = httptest.NewRecorder()
+	AddTeamMember(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+
+	teamIDs, err := db.GetUserTeamIDs(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{team.ID}, teamIDs)
+
+	// Members only see their own teams
+	rr = httptest.NewRecorder()
+	ListTeams(rr, asCaller(httptest.NewRequest(http.MethodGet, "/teams", nil),
+		auth.Caller{Scope: models.ScopeWrite, User: &user, Teams: teamIDs}))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Payments")
+	assert.NotContains(t, rr.Body.String(), `"Default"`)
+
+	req = adminRequest(http.MethodDelete, "/teams/"+team.ID+"/members/"+user.ID, nil)
+	req.SetPathValue("id", team.ID)
+	req.SetPathValue("user_id", user.ID)
+	rr = httptest.NewRecorder()
+	RemoveTeamMember(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	teamIDs, err = db.GetUserTeamIDs(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, teamIDs)
+}
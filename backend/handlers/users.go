@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+/*
+Function to log a user in. The session token goes back in an HttpOnly cookie,
+which the auth middleware accepts in place of an API key.
+*/
+func Login(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.login")
+	defer span.Finish()
+
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	user, err := auth.Login(req.Email, req.Password)
+	if errors.Is(err, auth.ErrInvalidLogin) {
+		writeError(response, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+
+	token, expiresAt, err := auth.CreateSession(user.ID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to start session")
+		return
+	}
+
+	span.SetTag("user.id", user.ID)
+
+	setSessionCookie(response, request, token, expiresAt)
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(user)
+}
+
+/*
+Function to log out, ending the session in the cookie (if any) and clearing it
+*/
+func Logout(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.logout")
+	defer span.Finish()
+
+	cookie, err := request.Cookie(auth.SessionCookie)
+	if err == nil && cookie.Value != "" {
+		err = auth.EndSession(cookie.Value)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			writeError(response, http.StatusInternalServerError, "Failed to end session")
+			return
+		}
+	}
+
+	http.SetCookie(response, &http.Cookie{
+		Name:     auth.SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
+
+/*
+Function to get the logged in user and their teams, so the frontend knows who it
+is and what their role lets them do
+*/
+func Me(response http.ResponseWriter, request *http.Request) {
+	caller, ok := auth.CallerFrom(request.Context())
+	if !ok || caller.User == nil {
+		writeError(response, http.StatusNotFound, "Not logged in as a user")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(struct {
+		models.User
+		TeamIDs []string `json:"team_ids"`
+	}{*caller.User, caller.Teams})
+}
+
+/*
+Function to create a user with a role
+*/
+func CreateUser(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.create_user")
+	defer span.Finish()
+
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if !auth.ValidRole(req.Role) {
+		writeError(response, http.StatusBadRequest, "role must be viewer, editor or admin")
+		return
+	}
+
+	err = auth.ValidateCredentials(auth.NormalizeEmail(req.Email), req.Password)
+	if err != nil {
+		writeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, _, err = db.GetUserByEmail(auth.NormalizeEmail(req.Email))
+	if err == nil {
+		writeError(response, http.StatusConflict, "A user with that email already exists")
+		return
+	}
+
+	user, err := auth.CreateUser(req.Email, req.Password, req.Role)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to save user to DB")
+		return
+	}
+
+	audit.Record(request.Context(), userChange("user.create", user), nil, user)
+
+	// Return 201
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(user)
+}
+
+/*
+Function to list every user
+*/
+func ListUsers(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.list_users")
+	defer span.Finish()
+
+	users, err := db.GetUsers()
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch users from db")
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(users)
+}
+
+/*
+Function to change a user's role or reset their password. A new password logs
+the user out everywhere. The last admin can't be demoted.
+*/
+func UpdateUser(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.update_user")
+	defer span.Finish()
+
+	var req struct {
+		Role     *string `json:"role"`
+		Password *string `json:"password"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	user, err := db.GetUser(request.PathValue("id"))
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusNotFound, "User not found")
+		return
+	}
+
+	before := user
+
+	if req.Role != nil && *req.Role != user.Role {
+		if !auth.ValidRole(*req.Role) {
+			writeError(response, http.StatusBadRequest, "role must be viewer, editor or admin")
+			return
+		}
+
+		if !keepsAnAdmin(response, user) {
+			return
+		}
+		user.Role = *req.Role
+	}
+
+	var passwordHash string
+	if req.Password != nil {
+		err = auth.ValidateCredentials(user.Email, *req.Password)
+		if err != nil {
+			writeError(response, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		passwordHash, err = auth.HashPassword(*req.Password)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			writeError(response, http.StatusInternalServerError, "Failed to update user")
+			return
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+
+	err = db.UpdateUser(user, passwordHash)
+	if err == nil && passwordHash != "" {
+		err = db.DeleteUserSessions(user.ID)
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	// The log only says that the password changed, never what to
+	audit.Record(request.Context(), userChange("user.update", user), before, struct {
+		models.User
+		PasswordChanged bool `json:"password_changed,omitempty"`
+	}{user, passwordHash != ""})
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(user)
+}
+
+/*
+Function to delete a user, ending their sessions. The last admin can't be deleted.
+*/
+func DeleteUser(response http.ResponseWriter, request *http.Request) {
+	span, _ := tracer.StartSpanFromContext(request.Context(), "handler.delete_user")
+	defer span.Finish()
+
+	user, err := db.GetUser(request.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(response, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to fetch user from db")
+		return
+	}
+
+	if !keepsAnAdmin(response, user) {
+		return
+	}
+
+	err = db.DeleteUser(user.ID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		writeError(response, http.StatusInternalServerError, "Failed to delete user")
+		return
+	}
+
+	audit.Record(request.Context(), userChange("user.delete", user), user, nil)
+
+	// Return 204
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// keepsAnAdmin refuses (and reports false) when user is the only admin left
+func keepsAnAdmin(response http.ResponseWriter, user models.User) bool {
+	if user.Role != models.RoleAdmin {
+		return true
+	}
+
+	count, err := db.CountUsersWithRole(models.RoleAdmin)
+	if err != nil {
+		writeError(response, http.StatusInternalServerError, "Failed to count admins")
+		return false
+	}
+	if count <= 1 {
+		writeError(response, http.StatusConflict, "Can't remove the last admin")
+		return false
+	}
+
+	return true
+}
+
+// setSessionCookie hands the browser a new session's token
+func setSessionCookie(response http.ResponseWriter, request *http.Request, token string, expiresAt time.Time) {
+	http.SetCookie(response, &http.Cookie{
+		Name:     auth.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
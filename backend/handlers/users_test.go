@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// login posts credentials to Login and returns the response
+func login(email string, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	rr := httptest.NewRecorder()
+	Login(rr, adminRequest(http.MethodPost, "/login", bytes.NewBuffer(body)))
+	return rr
+}
+
+func TestLogin_SetsSessionCookie(t *testing.T) {
+	setupTestDB(t)
+
+	user, err := auth.CreateUser("alice@example.com", "correct horse", models.RoleEditor)
+	require.NoError(t, err)
+
+	rr := login("alice@example.com", "correct horse")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "password")
+
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, auth.SessionCookie, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	found, err := auth.SessionUser(cookies[0].Value)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	// Logging out ends the session and clears the cookie
+	req := adminRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(cookies[0])
+	rr = httptest.NewRecorder()
+	Logout(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, -1, rr.Result().Cookies()[0].MaxAge)
+
+	_, err = auth.SessionUser(cookies[0].Value)
+	assert.Error(t, err)
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	setupTestDB(t)
+
+	_, err := auth.CreateUser("alice@example.com", "correct horse", models.RoleEditor)
+	require.NoError(t, err)
+
+	rr := login("alice@example.com", "wrong horse")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Empty(t, rr.Result().Cookies())
+
+	rr = login("nobody@example.com", "correct horse")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestMe(t *testing.T) {
+	setupTestDB(t)
+
+	user, err := auth.CreateUser("alice@example.com", "correct horse", models.RoleViewer)
+	require.NoError(t, err)
+
+	req := adminRequest(http.MethodGet, "/me", nil)
+	req = req.WithContext(auth.WithCaller(req.Context(), auth.Caller{Scope: models.ScopeRead, User: &user}))
+	rr := httptest.NewRecorder()
+	Me(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "alice@example.com")
+
+	// API keys aren't users
+	rr = httptest.NewRecorder()
+	Me(rr, adminRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestUsers_CreateUpdateDelete(t *testing.T) {
+	setupTestDB(t)
+
+	admin, err := auth.CreateFirstAdmin("admin@example.com", "correct horse")
+	require.NoError(t, err)
+
+	body, _ := json.Marshal(map[string]string{"email": "bob@example.com", "password": "battery staple", "role": "viewer"})
+	rr := httptest.NewRecorder()
+	CreateUser(rr, adminRequest(http.MethodPost, "/users", bytes.NewBuffer(body)))
+
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var bob models.User
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&bob))
+
+	// The same email can't be used twice
+	rr = httptest.NewRecorder()
+	CreateUser(rr, adminRequest(http.MethodPost, "/users", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	rr = httptest.NewRecorder()
+	ListUsers(rr, adminRequest(http.MethodGet, "/users", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "bob@example.com")
+
+	// Promote bob and reset his password, which logs him out
+	session, _, err := auth.CreateSession(bob.ID)
+	require.NoError(t, err)
+
+	body, _ = json.Marshal(map[string]string{"role": "editor", "password": "new password"})
+	req := adminRequest(http.MethodPatch, "/users/"+bob.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", bob.ID)
+	rr = httptest.NewRecorder()
+	UpdateUser(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"role":"editor"`)
+
+	_, err = auth.SessionUser(session)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusOK, login("bob@example.com", "new password").Code)
+
+	// The last admin can't be demoted or deleted
+	body, _ = json.Marshal(map[string]string{"role": "viewer"})
+	req = adminRequest(http.MethodPatch, "/users/"+admin.ID, bytes.NewBuffer(body))
+	req.SetPathValue("id", admin.ID)
+	rr = httptest.NewRecorder()
+	UpdateUser(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req = adminRequest(http.MethodDelete, "/users/"+admin.ID, nil)
+	req.SetPathValue("id", admin.ID)
+	rr = httptest.NewRecorder()
+	DeleteUser(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req = adminRequest(http.MethodDelete, "/users/"+bob.ID, nil)
+	req.SetPathValue("id", bob.ID)
+	rr = httptest.NewRecorder()
+	DeleteUser(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, login("bob@example.com", "new password").Code)
+}
@@ -0,0 +1,166 @@
+package incidents
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/metrics"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/KerlynD/URL-Monitor/backend/notify"
+	"github.com/google/uuid"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Serialises evaluations so the worker and a manual check can't both open an incident
+var mu sync.Mutex
+
+/*
+Function to update a monitor's incident state after a result has been saved. Returns
+the event when an incident opened or resolved (after handing it to the notification
+channels), nil when nothing changed.
+*/
+func Evaluate(monitor models.MonitorEntry, result models.MonitorResult) (*models.IncidentEvent, error) {
+	event, err := evaluate(monitor, result)
+	if err != nil || event == nil {
+		return event, err
+	}
+
+	notify.Dispatch(*event)
+	return event, nil
+}
+
+func evaluate(monitor models.MonitorEntry, result models.MonitorResult) (*models.IncidentEvent, error) {
+	/*
+		State is derived from the stored results rather than kept in memory, so it
+		survives restarts. An incident opens once the last failure_threshold results are
+		all down, and resolves once the last recovery_threshold results are all up.
+	*/
+	span := tracer.StartSpan("incidents.evaluate",
+		tracer.Tag("monitor.id", monitor.ID),
+		tracer.Tag("check.isUp", result.IsUp),
+	)
+	defer span.Finish()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	open, err := db.GetOpenIncident(monitor.ID)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return nil, err
+	}
+
+	if open == nil && !result.IsUp {
+		return openIncident(monitor, result)
+	}
+
+	if open != nil && result.IsUp {
+		return resolveIncident(monitor, *open, result)
+	}
+
+	return nil, nil
+}
+
+func openIncident(monitor models.MonitorEntry, result models.MonitorResult) (*models.IncidentEvent, error) {
+	recent, ok, err := lastResultsMatch(monitor.ID, threshold(monitor.FailureThreshold), false)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// The outage began with the oldest failure in the run
+	incident := models.Incident{
+		ID:        uuid.New().String(),
+		MonitorID: monitor.ID,
+		Status:    models.IncidentOpen,
+		Cause:     failureCause(result),
+		StartedAt: recent[len(recent)-1].Timestamp,
+	}
+
+	err = db.CreateIncident(incident)
+	if err != nil {
+		return nil, err
+	}
+
+	if metrics.Client != nil {
+		metrics.Client.Incr("incidents.opened", metrics.MonitorTags(monitor), 1.0)
+	}
+	log.Printf("Monitor %s is down, opened incident %s: %s", monitor.ID, incident.ID, incident.Cause)
+
+	return &models.IncidentEvent{
+		Type:     models.EventIncidentOpened,
+		Monitor:  monitor,
+		Incident: incident,
+		Result:   result,
+	}, nil
+}
+
+func resolveIncident(monitor models.MonitorEntry, incident models.Incident, result models.MonitorResult) (*models.IncidentEvent, error) {
+	recent, ok, err := lastResultsMatch(monitor.ID, threshold(monitor.RecoveryThreshold), true)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// The outage ended with the oldest success in the run
+	resolvedAt := recent[len(recent)-1].Timestamp
+
+	err = db.ResolveIncident(incident.ID, resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	incident.Status = models.IncidentResolved
+	incident.ResolvedAt = &resolvedAt
+	incident.Duration = resolvedAt.Sub(incident.StartedAt)
+
+	if metrics.Client != nil {
+		metrics.Client.Incr("incidents.resolved", metrics.MonitorTags(monitor), 1.0)
+		metrics.Client.Timing("incidents.duration", incident.Duration, metrics.MonitorTags(monitor), 1.0)
+	}
+	log.Printf("Monitor %s recovered, resolved incident %s after %v", monitor.ID, incident.ID, incident.Duration)
+
+	return &models.IncidentEvent{
+		Type:     models.EventIncidentResolved,
+		Monitor:  monitor,
+		Incident: incident,
+		Result:   result,
+	}, nil
+}
+
+// lastResultsMatch reports whether the newest count results all have the given up state
+func lastResultsMatch(monitorID string, count int, isUp bool) ([]models.MonitorResult, bool, error) {
+	recent, err := db.GetResults(monitorID, db.ResultFilter{Limit: count})
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading recent results: %w", err)
+	}
+
+	if len(recent) < count {
+		return recent, false, nil
+	}
+
+	for _, result := range recent {
+		if result.IsUp != isUp {
+			return recent, false, nil
+		}
+	}
+
+	return recent, true, nil
+}
+
+// threshold treats an unset threshold as a single check
+func threshold(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// failureCause is a short human-readable reason for a failed result
+func failureCause(result models.MonitorResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	return fmt.Sprintf("unexpected status code %d", result.StatusCode)
+}
@@ -0,0 +1,109 @@
+package incidents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+	m.Run()
+}
+
+// setupTestDB initializes a clean database for each test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db.CloseDB()
+	err := db.InitDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.CloseDB()
+	})
+}
+
+// recordCheck saves a result a minute after the previous one and evaluates it
+func recordCheck(t *testing.T, monitor models.MonitorEntry, at time.Time, isUp bool) *models.IncidentEvent {
+	t.Helper()
+	result := models.MonitorResult{StatusCode: 200, IsUp: isUp, Timestamp: at}
+	if !isUp {
+		result.StatusCode = 0
+		result.Error = "connection refused"
+	}
+
+	require.NoError(t, db.SaveResult(monitor.ID, result))
+
+	event, err := Evaluate(monitor, result)
+	require.NoError(t, err)
+	return event
+}
+
+func TestEvaluate_OpensAndResolvesWithThresholds(t *testing.T) {
+	setupTestDB(t)
+
+	monitor := models.MonitorEntry{
+		ID:                "monitor1",
+		URL:               "https://www.example.com",
+		FailureThreshold:  3,
+		RecoveryThreshold: 2,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	require.NoError(t, db.SaveMonitor(monitor))
+
+	base := time.Now().Add(-time.Hour)
+	at := func(minute int) time.Time { return base.Add(time.Duration(minute) * time.Minute) }
+
+	// Two failures aren't enough to open
+	assert.Nil(t, recordCheck(t, monitor, at(0), false))
+	assert.Nil(t, recordCheck(t, monitor, at(1), false))
+
+	// A success resets the run
+	assert.Nil(t, recordCheck(t, monitor, at(2), true))
+	assert.Nil(t, recordCheck(t, monitor, at(3), false))
+	assert.Nil(t, recordCheck(t, monitor, at(4), false))
+
+	opened := recordCheck(t, monitor, at(5), false)
+	require.NotNil(t, opened)
+	assert.Equal(t, models.EventIncidentOpened, opened.Type)
+	assert.Equal(t, "connection refused", opened.Incident.Cause)
+	assert.WithinDuration(t, at(3), opened.Incident.StartedAt, time.Millisecond)
+
+	// Further failures don't open a second incident
+	assert.Nil(t, recordCheck(t, monitor, at(6), false))
+
+	// One success isn't enough to resolve
+	assert.Nil(t, recordCheck(t, monitor, at(7), true))
+
+	resolved := recordCheck(t, monitor, at(8), true)
+	require.NotNil(t, resolved)
+	assert.Equal(t, models.EventIncidentResolved, resolved.Type)
+	assert.Equal(t, opened.Incident.ID, resolved.Incident.ID)
+	assert.Equal(t, 4*time.Minute, resolved.Incident.Duration)
+
+	incidents, err := db.GetIncidents(db.IncidentFilter{MonitorID: "monitor1"})
+	require.NoError(t, err)
+	require.Len(t, incidents, 1)
+	assert.Equal(t, models.IncidentResolved, incidents[0].Status)
+}
+
+func TestEvaluate_DefaultThresholdIsOneCheck(t *testing.T) {
+	setupTestDB(t)
+
+	monitor := models.MonitorEntry{ID: "monitor1", URL: "https://www.example.com"}
+	require.NoError(t, db.SaveMonitor(monitor))
+
+	now := time.Now()
+	assert.NotNil(t, recordCheck(t, monitor, now, false))
+
+	open, err := db.GetOpenIncident("monitor1")
+	require.NoError(t, err)
+	require.NotNil(t, open)
+
+	assert.NotNil(t, recordCheck(t, monitor, now.Add(time.Minute), true))
+}
@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"encoding/json"
 	"io"
 	"log"
 	"os"
@@ -42,6 +43,24 @@ func InitLogger(logFilePath string) error {
 	return nil
 }
 
+/*
+Function to log a structured event as one JSON object, so log pipelines can pick
+events such as audit entries out of the plain log lines
+*/
+func Event(name string, fields any) {
+	line, err := json.Marshal(map[string]any{"event": name, "fields": fields})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", name, err)
+		return
+	}
+
+	if Logger == nil {
+		log.Println(string(line))
+		return
+	}
+	Logger.Println(string(line))
+}
+
 func Close() {
 	if logFile != nil {
 		log.Println("Closing log file")
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"bufio"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/KerlynD/URL-Monitor/backend/audit"
+	"github.com/KerlynD/URL-Monitor/backend/auth"
 	"github.com/KerlynD/URL-Monitor/backend/db"
 	"github.com/KerlynD/URL-Monitor/backend/logging"
 	"github.com/KerlynD/URL-Monitor/backend/metrics"
+	"github.com/KerlynD/URL-Monitor/backend/notify"
 	"github.com/KerlynD/URL-Monitor/backend/routes"
+	"github.com/KerlynD/URL-Monitor/backend/secrets"
 	"github.com/KerlynD/URL-Monitor/backend/worker"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -21,16 +30,25 @@ func main() {
 		Main entry to the backend:
 			1. Detect if running in Docker
 			2. Init Logger
-			3. Init DB
+			3. Init Secrets, DB, the first API key and single sign-on
 			4. Init Tracer
 			5. Init Metrics
-			6. Start Monitor Checker
+			6. Start Monitor Checker and Digest
 			7. Setup Routes
 			8. Configure HTTP
 			9. Start Server in goroutine
 			10. Shutdown on interupt
 	*/
 
+	// `backend create-admin` sets up the first admin user instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		err := createAdmin(os.Args[2:])
+		if err != nil {
+			log.Fatalf("Failed to create admin: %v", err)
+		}
+		return
+	}
+
 	// Detect Docker Container
 	datadogHost := getDatadogHost()
 
@@ -42,6 +60,12 @@ func main() {
 	}
 	defer logging.Close()
 
+	// Init the key that encrypts monitor credentials (kept next to the DB unless SECRET_KEY is set)
+	err = secrets.Init(os.Getenv("SECRET_KEY"), "db/secret.key")
+	if err != nil {
+		log.Fatalf("Failed to init secrets: %v", err)
+	}
+
 	// Init DB
 	dbPath := "db/monitor.db"
 	err = db.InitDB(dbPath)
@@ -55,6 +79,23 @@ func main() {
 		}
 	}()
 
+	// Store ADMIN_API_KEY as the first admin API key on a fresh install
+	err = auth.Bootstrap(os.Getenv("ADMIN_API_KEY"))
+	if err != nil {
+		log.Fatalf("Failed to init API keys: %v", err)
+	}
+
+	// Audit entries always go to the DB; AUDIT_LOG_EVENTS=true also logs them as JSON events
+	audit.LogEvents, _ = strconv.ParseBool(os.Getenv("AUDIT_LOG_EVENTS"))
+
+	// Single sign-on is optional; without it users log in with passwords
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		auth.SSO, err = newOIDCProvider(issuer)
+		if err != nil {
+			log.Printf("Single sign-on disabled: %v", err)
+		}
+	}
+
 	// Initialize tracer
 	tracer.Start(
 		tracer.WithService("url-monitor"),
@@ -76,9 +117,15 @@ func main() {
 		defer metrics.CloseMetrics()
 	}
 
-	// Start Monitor Checker
-	checkInterval := 30 * time.Second
-	worker.StartMonitorChecker(checkInterval)
+	// Start Monitor Checker (monitors without a check_interval fall back to this)
+	defaultCheckInterval := 30 * time.Second
+	worker.StartMonitorChecker(defaultCheckInterval,
+		getEnvInt("CHECK_CONCURRENCY", 10),
+		getEnvInt("CHECK_PER_HOST_LIMIT", 2),
+	)
+
+	// Start the daily digest for email channels that asked for one
+	notify.StartDigestScheduler()
 
 	// Setup Routes
 	handler := routes.SetupServer()
@@ -105,6 +152,9 @@ func main() {
 	<-quit
 
 	log.Println("Server shutting down")
+
+	// Let alerts that are already on their way finish sending, without further retries
+	notify.Shutdown()
 }
 
 // Helper function to determine the Datadog Agent Address
@@ -126,6 +176,34 @@ func getEnv() string {
 	return "dev"
 }
 
+// Helper function to read a positive integer setting from the environment
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// Helper function to set up single sign-on from the OIDC_* environment variables
+func newOIDCProvider(issuer string) (*auth.OIDCProvider, error) {
+	groupRoles, err := auth.ParseGroupRoles(os.Getenv("OIDC_GROUP_ROLES"))
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		AfterLogin:   os.Getenv("OIDC_AFTER_LOGIN_URL"),
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		GroupRoles:   groupRoles,
+		DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+	})
+}
+
 // Helper function to get the log file path
 func getLogPath() string {
 	if _, err := os.Stat("/.dockerenv"); err == nil {
@@ -134,3 +212,43 @@ func getLogPath() string {
 	// On host: use parent directory's logs folder
 	return "../logs/url-monitor.log"
 }
+
+/*
+Function for the create-admin command, which creates the first admin user so
+someone can log in on a fresh install. The password is read from stdin when
+-password isn't given, to keep it out of shell history.
+*/
+func createAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email the admin logs in with")
+	password := flags.String("password", "", "admin password (read from stdin if empty)")
+	dbPath := flags.String("db", "db/monitor.db", "path to the database")
+	flags.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("error reading password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	err := db.InitDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	user, err := auth.CreateFirstAdmin(*email, *password)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created admin user %s (%s)", user.Email, user.ID)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/KerlynD/URL-Monitor/backend/models"
 )
 
 var (
@@ -39,3 +40,9 @@ func CloseMetrics() {
 		Client.Close()
 	}
 }
+
+// MonitorTags are the tags every metric about a monitor carries: its URL and
+// the team that owns it
+func MonitorTags(monitor models.MonitorEntry) []string {
+	return []string{"url:" + monitor.URL, "team:" + monitor.TeamID}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// AuthMiddleware requires an API key or a logged in user with enough access on
+// every route except pings and logging in
+func AuthMiddleware(next http.Handler) http.Handler {
+	/*
+		This middleware takes the caller from the Authorization: Bearer API key, or
+		else the session cookie set at login, and checks the key's scope (or the
+		scope of the user's role) covers the route before passing the request on
+		with the caller in its context. Heartbeat pings authenticate with their own
+		token, so they skip it.
+	*/
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := requiredScope(r)
+		if required == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, status, message := authenticate(r)
+		if status != 0 {
+			writeAuthError(w, status, message)
+			return
+		}
+
+		if !auth.Allows(caller.Scope, required) {
+			if caller.User != nil {
+				writeAuthError(w, http.StatusForbidden, "The "+caller.User.Role+" role can't do this")
+				return
+			}
+			writeAuthError(w, http.StatusForbidden, "API key needs the "+required+" scope")
+			return
+		}
+
+		if caller.Key != nil {
+			err := db.TouchAPIKey(caller.Key.ID, time.Now())
+			if err != nil {
+				log.Printf("Error recording use of API key %s: %v", caller.Key.ID, err)
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithCaller(r.Context(), caller)))
+	})
+}
+
+// authenticate works out who made a request, or the status and message to refuse it with
+func authenticate(r *http.Request) (auth.Caller, int, string) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		secret, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(secret) == "" {
+			return auth.Caller{}, http.StatusUnauthorized, "Missing API key"
+		}
+
+		key, err := db.GetActiveAPIKey(auth.HashKey(strings.TrimSpace(secret)))
+		if err != nil {
+			return auth.Caller{}, http.StatusUnauthorized, "Invalid API key"
+		}
+
+		caller := auth.Caller{Scope: key.Scope, Key: &key}
+		if key.TeamID != "" {
+			caller.Teams = []string{key.TeamID}
+		}
+		return caller, 0, ""
+	}
+
+	cookie, err := r.Cookie(auth.SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return auth.Caller{}, http.StatusUnauthorized, "Missing API key or session"
+	}
+
+	user, err := auth.SessionUser(cookie.Value)
+	if err != nil {
+		return auth.Caller{}, http.StatusUnauthorized, "Session expired, log in again"
+	}
+
+	teams, err := db.GetUserTeamIDs(user.ID)
+	if err != nil {
+		log.Printf("Error loading teams of user %s: %v", user.ID, err)
+		return auth.Caller{}, http.StatusInternalServerError, "Failed to load user's teams"
+	}
+
+	return auth.Caller{Scope: auth.RoleScope(user.Role), User: &user, Teams: teams}, 0, ""
+}
+
+// requiredScope is the scope a request needs, empty for routes without auth
+func requiredScope(r *http.Request) string {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/ping/"):
+		return ""
+	case r.URL.Path == "/login" || r.URL.Path == "/logout" || strings.HasPrefix(r.URL.Path, "/login/"):
+		// Logging in, with a password or single sign-on, and out
+		return ""
+	case r.URL.Path == "/keys" || strings.HasPrefix(r.URL.Path, "/keys/"):
+		return models.ScopeAdmin
+	case r.URL.Path == "/users" || strings.HasPrefix(r.URL.Path, "/users/"):
+		return models.ScopeAdmin
+	case r.URL.Path == "/orgs" || strings.HasPrefix(r.URL.Path, "/orgs/"):
+		return models.ScopeAdmin
+	case r.URL.Path == "/audit":
+		// The log covers users and organizations, which belong to no team
+		return models.ScopeAdmin
+	case (r.URL.Path == "/teams" || strings.HasPrefix(r.URL.Path, "/teams/")) && r.Method != http.MethodGet:
+		// Anyone may list the teams they are in, only admins change them
+		return models.ScopeAdmin
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return models.ScopeRead
+	}
+	return models.ScopeWrite
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
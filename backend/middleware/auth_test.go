@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+	m.Run()
+}
+
+// setupTestDB initializes a clean database for each test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db.CloseDB()
+	err := db.InitDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.CloseDB()
+	})
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	setupTestDB(t)
+
+	readKey, readSecret, err := auth.CreateKey("dashboard", models.ScopeRead, models.DefaultTeam)
+	require.NoError(t, err)
+	_, writeSecret, err := auth.CreateKey("ci", models.ScopeWrite, models.DefaultTeam)
+	require.NoError(t, err)
+	revoked, revokedSecret, err := auth.CreateKey("old", models.ScopeAdmin, models.DefaultTeam)
+	require.NoError(t, err)
+	require.NoError(t, db.RevokeAPIKey(db.AllTeams, revoked.ID, time.Now()))
+
+	var seen auth.Caller
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = auth.CallerFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{"no key", http.MethodGet, "/monitor", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/monitor", "um_nope", http.StatusUnauthorized},
+		{"revoked key", http.MethodGet, "/monitor", revokedSecret, http.StatusUnauthorized},
+		{"read can list", http.MethodGet, "/monitor", readSecret, http.StatusOK},
+		{"read can't run a check", http.MethodPost, "/monitor/abc/check", readSecret, http.StatusForbidden},
+		{"write can run a check", http.MethodPost, "/monitor/abc/check", writeSecret, http.StatusOK},
+		{"read can't create", http.MethodPost, "/monitor", readSecret, http.StatusForbidden},
+		{"read can't delete", http.MethodDelete, "/monitor/abc", readSecret, http.StatusForbidden},
+		{"write can create", http.MethodPost, "/monitor", writeSecret, http.StatusOK},
+		{"write can't manage keys", http.MethodGet, "/keys", writeSecret, http.StatusForbidden},
+		{"pings need no key", http.MethodPost, "/ping/token", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code, rr.Body.String())
+		})
+	}
+
+	// The handler sees which key made the request, and its use is recorded
+	req := httptest.NewRequest(http.MethodGet, "/monitor", nil)
+	req.Header.Set("Authorization", "Bearer "+readSecret)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, seen.Key)
+	assert.Equal(t, readKey.ID, seen.Key.ID)
+
+	keys, err := db.GetAPIKeys(db.AllTeams)
+	require.NoError(t, err)
+	for _, key := range keys {
+		if key.ID == readKey.ID {
+			assert.NotNil(t, key.LastUsedAt)
+		}
+	}
+}
+
+func TestAuthMiddleware_Sessions(t *testing.T) {
+	setupTestDB(t)
+
+	viewer, err := auth.CreateUser("viewer@example.com", "viewer-password", models.RoleViewer)
+	require.NoError(t, err)
+	editor, err := auth.CreateUser("editor@example.com", "editor-password", models.RoleEditor)
+	require.NoError(t, err)
+	admin, err := auth.CreateUser("admin@example.com", "admin-password", models.RoleAdmin)
+	require.NoError(t, err)
+
+	viewerSession, _, err := auth.CreateSession(viewer.ID)
+	require.NoError(t, err)
+	editorSession, _, err := auth.CreateSession(editor.ID)
+	require.NoError(t, err)
+	adminSession, _, err := auth.CreateSession(admin.ID)
+	require.NoError(t, err)
+
+	// An expired session is refused
+	require.NoError(t, db.SaveSession(auth.HashKey("expired"), admin.ID, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+
+	var seen auth.Caller
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = auth.CallerFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		session string
+		status  int
+	}{
+		{"unknown session", http.MethodGet, "/monitor", "nope", http.StatusUnauthorized},
+		{"expired session", http.MethodGet, "/monitor", "expired", http.StatusUnauthorized},
+		{"viewer can list", http.MethodGet, "/monitor", viewerSession, http.StatusOK},
+		{"viewer can't create", http.MethodPost, "/monitor", viewerSession, http.StatusForbidden},
+		{"editor can create", http.MethodPost, "/monitor", editorSession, http.StatusOK},
+		{"editor can't manage users", http.MethodGet, "/users", editorSession, http.StatusForbidden},
+		{"admin can manage users", http.MethodGet, "/users", adminSession, http.StatusOK},
+		{"editor can't read the audit log", http.MethodGet, "/audit", editorSession, http.StatusForbidden},
+		{"admin can manage keys", http.MethodPost, "/keys", adminSession, http.StatusOK},
+		{"login needs no session", http.MethodPost, "/login", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.session != "" {
+				req.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: tt.session})
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code, rr.Body.String())
+		})
+	}
+
+	// The handler sees which user made the request, and their teams
+	require.NoError(t, db.AddTeamMember(models.DefaultTeam, editor.ID, time.Now()))
+
+	req := httptest.NewRequest(http.MethodGet, "/monitor", nil)
+	req.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: editorSession})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, seen.User)
+	assert.Equal(t, editor.ID, seen.User.ID)
+	assert.Equal(t, models.ScopeWrite, seen.Scope)
+	assert.Equal(t, []string{models.DefaultTeam}, seen.Teams)
+}
@@ -33,12 +33,16 @@ func CORSMiddleware(next http.Handler) http.Handler {
 				if origin == "" {
 					w.Header().Set("Access-Control-Allow-Origin", "*")
 				}
+				// Only named origins may send the session cookie, never a wildcard
+				if allowedOrigin != "*" {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
 				break
 			}
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// What an API key may do. Each scope includes the ones before it.
+const (
+	ScopeRead  = "read"  // <- GET requests, and running a check
+	ScopeWrite = "write" // <- Creating, changing and deleting monitors and channels
+	ScopeAdmin = "admin" // <- Managing API keys, users and teams, across every team
+)
+
+// APIKey is a stored API key. Only a hash of the key itself is kept; the
+// key is shown once, when it is created.
+type APIKey struct {
+	ID         string     `json:"id"`
+	TeamID     string     `json:"team_id"` // <- read and write keys only reach this team's monitors; admin keys have none
+	Name       string     `json:"name"`
+	Scope      string     `json:"scope"`
+	Prefix     string     `json:"prefix"` // <- Start of the key, to tell keys apart
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
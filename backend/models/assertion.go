@@ -0,0 +1,25 @@
+package models
+
+import "encoding/json"
+
+// Kinds of response assertion
+const (
+	AssertStatus          = "status"            // <- Codes and/or Min..Max
+	AssertBodyContains    = "body_contains"     // <- Value
+	AssertBodyNotContains = "body_not_contains" // <- Value
+	AssertBodyRegex       = "body_regex"        // <- Value is the pattern
+	AssertJSONPath        = "json_path"         // <- Path compared to Expected
+	AssertHeader          = "header"            // <- Header equals Value
+)
+
+// Assertion is one rule a check response must satisfy. Which fields are used depends on Type.
+type Assertion struct {
+	Type     string          `json:"type"`
+	Codes    []int           `json:"codes,omitempty"`
+	Min      int             `json:"min,omitempty"`
+	Max      int             `json:"max,omitempty"`
+	Value    string          `json:"value,omitempty"`
+	Path     string          `json:"path,omitempty"`
+	Expected json.RawMessage `json:"expected,omitempty"`
+	Header   string          `json:"header,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Who made a change
+const (
+	ActorUser   = "user"
+	ActorAPIKey = "api_key"
+	ActorSystem = "system" // <- Changes made without a caller, e.g. from the command line
+)
+
+// Kinds of things a change was made to
+const (
+	AuditMonitor      = "monitor"
+	AuditChannel      = "channel"
+	AuditAPIKey       = "api_key"
+	AuditUser         = "user"
+	AuditOrganization = "organization"
+	AuditTeam         = "team"
+)
+
+// AuditEntry records one change made through the API. Before and After are
+// snapshots of the target, with secrets left out; Before is empty for things
+// that were created and After for things that were deleted.
+type AuditEntry struct {
+	ID         int64                  `json:"id"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Actor      string                 `json:"actor"` // <- User email or API key name
+	ActorType  string                 `json:"actor_type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"` // <- e.g. monitor.update
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	TeamID     string                 `json:"team_id,omitempty"`
+	Before     json.RawMessage        `json:"before,omitempty"`
+	After      json.RawMessage        `json:"after,omitempty"`
+	Diff       map[string]AuditChange `json:"diff,omitempty"` // <- Top level fields that changed
+}
+
+// AuditChange is the old and new value of one field
+type AuditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Why a check failed, when the error alone doesn't say
+const (
+	FailureCertExpired      = "certificate_expired"
+	FailureHostnameMismatch = "hostname_mismatch"
+	FailureIncompleteChain  = "incomplete_chain"
+	FailureUntrustedCert    = "untrusted_certificate"
+)
+
+// CertificateInfo describes the leaf certificate an https monitor presented
+type CertificateInfo struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"` // <- As of the check
+}
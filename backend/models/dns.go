@@ -0,0 +1,26 @@
+package models
+
+// Record types a dns monitor can query
+const (
+	RecordA     = "A"
+	RecordAAAA  = "AAAA"
+	RecordCNAME = "CNAME"
+	RecordMX    = "MX"
+	RecordTXT   = "TXT"
+	RecordNS    = "NS"
+)
+
+// Failure reasons of dns checks
+const (
+	FailureRecordsMismatch = "dns_records_mismatch"
+	FailureRecordsChanged  = "dns_records_changed"
+)
+
+// DNSConfig is what a dns monitor queries and what it expects back. The name
+// queried is the monitor's URL, written as dns://example.com.
+type DNSConfig struct {
+	RecordType string   `json:"record_type"`
+	Resolver   string   `json:"resolver,omitempty"` // <- host[:port], defaults to the system resolver
+	Expected   []string `json:"expected,omitempty"` // <- The answer set must be exactly these, in any order
+	Contains   string   `json:"contains,omitempty"` // <- The answer set must include this value
+}
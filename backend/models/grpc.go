@@ -0,0 +1,8 @@
+package models
+
+// GRPCConfig is how a grpc monitor calls grpc.health.v1.Health/Check. The target
+// is the monitor's URL, written as grpc://host:port.
+type GRPCConfig struct {
+	Service string `json:"service,omitempty"` // <- Empty asks about the server as a whole
+	TLS     bool   `json:"tls,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Failure reason of a heartbeat monitor whose job didn't check in on time
+const FailurePingOverdue = "ping_overdue"
+
+// HeartbeatConfig is how often a heartbeat monitor's job is expected to ping
+type HeartbeatConfig struct {
+	Period int `json:"period"`          // <- Seconds between pings
+	Grace  int `json:"grace,omitempty"` // <- Extra seconds allowed before the ping counts as missed
+}
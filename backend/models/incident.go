@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+type Incident struct {
+	ID         string        `json:"id"`
+	MonitorID  string        `json:"monitor_id"`
+	Status     string        `json:"status"`
+	Cause      string        `json:"cause"`
+	StartedAt  time.Time     `json:"started_at"`
+	ResolvedAt *time.Time    `json:"resolved_at"`
+	Duration   time.Duration `json:"duration"` // <- Until resolved, or until now while open
+}
+
+const (
+	EventIncidentOpened   = "incident.opened"
+	EventIncidentResolved = "incident.resolved"
+)
+
+// IncidentEvent is emitted when a monitor's incident opens or resolves
+type IncidentEvent struct {
+	Type     string        `json:"event"`
+	Monitor  MonitorEntry  `json:"monitor"`
+	Incident Incident      `json:"incident"`
+	Result   MonitorResult `json:"result"` // <- The check that caused the transition
+}
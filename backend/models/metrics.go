@@ -5,23 +5,106 @@ import (
 
 )
 
+// Kinds of monitor, each checked by its own checks.Checker
+const (
+	MonitorHTTP      = "http"
+	MonitorTCP       = "tcp"
+	MonitorDNS       = "dns"
+	MonitorGRPC      = "grpc"
+	MonitorHeartbeat = "heartbeat"
+)
+
 type MonitorEntry struct {
 	ID string `json:"id"`
-	URL string `json:"url"`
+	TeamID string `json:"team_id"` // <- Team that owns the monitor
+	Type string `json:"type"` // <- Defaults to http
+	URL string `json:"url"` // <- tcp monitors use tcp://host:port, dns monitors dns://name, grpc monitors grpc://host:port
 	CheckInterval int `json:"check_interval"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	LastCheckAt *time.Time `json:"last_check_at"`
+	NextCheckAt *time.Time `json:"next_check_at"`
+	Paused bool `json:"paused"`
+	FailureThreshold int `json:"failure_threshold"`
+	RecoveryThreshold int `json:"recovery_threshold"`
+	Assertions []Assertion `json:"assertions,omitempty"` // <- Checked in order, replace the default 2xx rule
+	Method string `json:"method,omitempty"` // <- Defaults to GET
+	Headers map[string]string `json:"headers,omitempty"`
+	Body string `json:"body,omitempty"` // <- tcp monitors send it as the payload
+	ContentType string `json:"content_type,omitempty"`
+	Auth *RequestAuth `json:"auth,omitempty"`
+	CertWarningDays int `json:"cert_warning_days,omitempty"` // <- Warn when the cert expires sooner, 0 means 14
+	DNS *DNSConfig `json:"dns,omitempty"` // <- dns monitors only
+	GRPC *GRPCConfig `json:"grpc,omitempty"` // <- grpc monitors only
+	Heartbeat *HeartbeatConfig `json:"heartbeat,omitempty"` // <- heartbeat monitors only
+	PingToken string `json:"ping_token,omitempty"` // <- heartbeat monitors are pinged at /ping/{ping_token}
+	PingStartedAt *time.Time `json:"ping_started_at,omitempty"` // <- Set by /start until the next ping
+	Retries int `json:"retries,omitempty"` // <- Extra attempts before a failure counts as down
+	RetryDelay int `json:"retry_delay,omitempty"` // <- Seconds between attempts
+	ConfirmFailures bool `json:"confirm_failures,omitempty"` // <- Re-check a failure on a fresh connection before it counts
+	Timeout int `json:"timeout,omitempty"` // <- Seconds per attempt, 0 means the type's default
+	FollowRedirects *bool `json:"follow_redirects,omitempty"` // <- Unset follows them
+	MaxRedirects int `json:"max_redirects,omitempty"` // <- 0 means 10
+	IgnoreTLSErrors bool `json:"ignore_tls_errors,omitempty"` // <- Certificate problems become a warning
+	MaxBodyBytes int64 `json:"max_body_bytes,omitempty"` // <- Fail when the body is larger, 0 reads up to 1 MiB
+	ConfigError string `json:"config_error,omitempty"` // <- Set when its stored config can't be read, which fails its checks
 }
 
 type MonitorResult struct {
+	ID int64 `json:"id,omitempty"`
 	StatusCode int `json:"status_code"`
 	ResponseTime time.Duration `json:"response_time"`
 	IsUp bool `json:"is_up"`
 	Error string `json:"error"`
 	Timestamp time.Time `json:"timestamp"`
+	Timings *CheckTimings `json:"timings,omitempty"` // <- Unset for results saved before timings existed
+	Certificate *CertificateInfo `json:"certificate,omitempty"` // <- https only
+	FailureReason string `json:"failure_reason,omitempty"`
+	Warning string `json:"warning,omitempty"` // <- Up, but needs attention (e.g. cert expiring soon)
+	Records []string `json:"records,omitempty"` // <- dns only, the sorted answer set
+	GRPCStatus string `json:"grpc_status,omitempty"` // <- grpc only, e.g. SERVING or NOT_SERVING
+	Attempts []CheckAttempt `json:"attempts,omitempty"` // <- Every try, when the check was retried or confirmed
+	FinalURL string `json:"final_url,omitempty"` // <- http only, where the redirects ended
+	RedirectChain []RedirectHop `json:"redirect_chain,omitempty"` // <- http only, in the order they were followed
+	RunTime time.Duration `json:"run_time,omitempty"` // <- heartbeat only, from the job's /start ping to its finishing ping
+}
+
+// CheckTimings splits a check into its network phases
+type CheckTimings struct {
+	DNSLookup time.Duration `json:"dns_lookup"`
+	TCPConnect time.Duration `json:"tcp_connect"`
+	TLSHandshake time.Duration `json:"tls_handshake"`
+	TimeToFirstByte time.Duration `json:"time_to_first_byte"` // <- From the request being sent
+	ContentTransfer time.Duration `json:"content_transfer"`
 }
 
 type MonitorWithStatus struct {
 	MonitorEntry
 	LastResult *MonitorResult `json:"last_result"`
-}
\ No newline at end of file
+	Summary *StatsSummary `json:"summary,omitempty"`
+}
+
+type MonitorStats struct {
+	Window string `json:"window"`
+	From time.Time `json:"from"`
+	To time.Time `json:"to"`
+	UptimePercent float64 `json:"uptime_percent"`
+	TotalChecks int `json:"total_checks"`
+	FailedChecks int `json:"failed_checks"`
+	MeanResponseTime time.Duration `json:"mean_response_time"`
+	P50ResponseTime time.Duration `json:"p50_response_time"`
+	P95ResponseTime time.Duration `json:"p95_response_time"`
+	P99ResponseTime time.Duration `json:"p99_response_time"`
+	LongestOutage time.Duration `json:"longest_outage"`
+	LongestOutageStart *time.Time `json:"longest_outage_start,omitempty"`
+}
+
+type StatsSummary struct {
+	Uptime24h float64 `json:"uptime_24h"`
+	P95ResponseTime time.Duration `json:"p95_response_time"`
+}
+
+type ResultPage struct {
+	Results []MonitorResult `json:"results"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type NotificationChannel struct {
+	ID        string          `json:"id"`
+	TeamID    string          `json:"team_id"` // <- Only alerts for this team's monitors
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Config    json.RawMessage `json:"config"` // <- Shape depends on Type
+	Enabled   bool            `json:"enabled"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Outcomes of a single delivery attempt
+const (
+	DeliverySucceeded = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationDelivery records one attempt to send an incident event to a channel
+type NotificationDelivery struct {
+	ID         int64     `json:"id"`
+	ChannelID  string    `json:"channel_id"`
+	MonitorID  string    `json:"monitor_id"`
+	IncidentID string    `json:"incident_id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
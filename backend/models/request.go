@@ -0,0 +1,31 @@
+package models
+
+// Credential kinds a monitor can send with its check request
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// RequestAuth is the credential sent with every check. It is encrypted at rest
+// and redacted from API responses.
+type RequestAuth struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"` // <- basic
+	Password string `json:"password,omitempty"` // <- basic
+	Token    string `json:"token,omitempty"`    // <- bearer
+}
+
+// Why an http check failed on its limits
+const (
+	FailureTooManyRedirects = "too_many_redirects"
+	FailureBodyTooLarge     = "body_too_large"
+)
+
+// Why a check failed before it was sent, when the monitor's stored credentials can't be decrypted
+const FailureConfigError = "config_error"
+
+// RedirectHop is one redirect an http check followed
+type RedirectHop struct {
+	URL        string `json:"url"` // <- The URL that answered with the redirect
+	StatusCode int    `json:"status_code"`
+}
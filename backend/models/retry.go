@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CheckAttempt is one try within a check that was retried or confirmed
+type CheckAttempt struct {
+	Timestamp    time.Time     `json:"timestamp"`
+	IsUp         bool          `json:"is_up"`
+	StatusCode   int           `json:"status_code,omitempty"`
+	ResponseTime time.Duration `json:"response_time"`
+	Error        string        `json:"error,omitempty"`
+	Confirmation bool          `json:"confirmation,omitempty"` // <- The independent re-check of a failure
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Team everything made before teams existed belongs to, in the org of the same ID
+const DefaultTeam = "default"
+
+// Organization groups the teams of one department or company
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team owns monitors, notification channels and API keys. Users only see what
+// belongs to the teams they are members of, unless they are admins.
+type Team struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// What a user may do. Each role has the access of the API key scope it maps to:
+// viewer reads, editor also changes monitors and channels, admin also manages
+// users and API keys.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// User is a person who logs in. The password is stored as a bcrypt hash and
+// never returned.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
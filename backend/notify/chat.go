@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Discord rejects messages longer than this many characters
+const discordMaxContent = 2000
+
+// chatConfig is shared by the Slack and Discord incoming-webhook notifiers
+type chatConfig struct {
+	URL              string `json:"url"`
+	Username         string `json:"username"`
+	OpenedTemplate   string `json:"opened_template"`
+	ResolvedTemplate string `json:"resolved_template"`
+}
+
+// ChatNotifier posts a templated message to a Slack or Discord incoming webhook
+type ChatNotifier struct {
+	config    chatConfig
+	templates messageTemplates
+	payload   func(config chatConfig, message string) any // <- Builds the service's body shape
+	client    *http.Client
+}
+
+func newSlackNotifier(raw json.RawMessage) (Notifier, error) {
+	return newChatNotifier(raw, slackPayload)
+}
+
+func newDiscordNotifier(raw json.RawMessage) (Notifier, error) {
+	return newChatNotifier(raw, discordPayload)
+}
+
+func newChatNotifier(raw json.RawMessage, payload func(chatConfig, string) any) (Notifier, error) {
+	var config chatConfig
+	err := json.Unmarshal(raw, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat webhook config: %w", err)
+	}
+
+	err = validateEndpoint(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := parseMessageTemplates(config.OpenedTemplate, config.ResolvedTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatNotifier{
+		config:    config,
+		templates: templates,
+		payload:   payload,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *ChatNotifier) Notify(ctx context.Context, event models.IncidentEvent) error {
+	message, err := c.templates.render(event)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(c.payload(c.config, message))
+	if err != nil {
+		return fmt.Errorf("error encoding chat payload: %w", err)
+	}
+
+	return postJSON(ctx, c.client, c.config.URL, body, nil)
+}
+
+// slackPayload is the Slack incoming-webhook body
+func slackPayload(config chatConfig, message string) any {
+	payload := map[string]string{"text": message}
+	if config.Username != "" {
+		payload["username"] = config.Username
+	}
+	return payload
+}
+
+// discordPayload is the Discord webhook body
+func discordPayload(config chatConfig, message string) any {
+	// Cut on a character boundary, so a multi-byte character isn't split
+	if utf8.RuneCountInString(message) > discordMaxContent {
+		message = string([]rune(message)[:discordMaxContent-3]) + "..."
+	}
+
+	payload := map[string]string{"content": message}
+	if config.Username != "" {
+		payload["username"] = config.Username
+	}
+	return payload
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifier_CustomTemplate(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	config, _ := json.Marshal(map[string]any{
+		"url":               server.URL,
+		"resolved_template": "{{.URL}} recovered after {{.Duration}} with {{.StatusCode}}",
+	})
+	notifier, err := New(models.NotificationChannel{Type: "slack", Config: config})
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), testEvent()))
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal((<-received).body, &payload))
+	assert.Equal(t, "https://www.example.com recovered after 5m0s with 200", payload["text"])
+}
+
+func TestDiscordNotifier_DefaultTemplate(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusNoContent)
+
+	config, _ := json.Marshal(map[string]any{"url": server.URL, "username": "url-monitor"})
+	notifier, err := New(models.NotificationChannel{Type: "discord", Config: config})
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Type = models.EventIncidentOpened
+	event.Result = models.MonitorResult{Error: "connection refused"}
+
+	require.NoError(t, notifier.Notify(context.Background(), event))
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal((<-received).body, &payload))
+	assert.Equal(t, "url-monitor", payload["username"])
+	assert.True(t, strings.HasPrefix(payload["content"], ":red_circle: https://www.example.com is DOWN"))
+	assert.Contains(t, payload["content"], "connection refused")
+}
+
+func TestDiscordPayload_TruncatesByCharacter(t *testing.T) {
+	message := strings.Repeat("é", discordMaxContent+100)
+
+	content := discordPayload(chatConfig{}, message).(map[string]string)["content"]
+
+	assert.True(t, utf8.ValidString(content))
+	assert.Equal(t, discordMaxContent, utf8.RuneCountInString(content))
+	assert.True(t, strings.HasSuffix(content, "é..."))
+
+	// Messages at the limit are left alone, however many bytes they take
+	message = strings.Repeat("é", discordMaxContent)
+	assert.Equal(t, message, discordPayload(chatConfig{}, message).(map[string]string)["content"])
+}
+
+func TestChatNotifier_RejectsBadTemplates(t *testing.T) {
+	// Syntax errors and unknown fields are both caught when the channel is saved
+	assert.Error(t, Validate("slack", json.RawMessage(`{"url": "https://hooks.slack.com/x", "opened_template": "{{.URL"}`)))
+	assert.Error(t, Validate("discord", json.RawMessage(`{"url": "https://discord.com/x", "opened_template": "{{.Nope}}"}`)))
+}
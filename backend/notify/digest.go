@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Window every digest covers
+const digestWindow = 24 * time.Hour
+
+// How often the digest scheduler checks whether a channel is due
+const digestPollInterval = time.Minute
+
+// DigestNotifier is implemented by channels that can send the daily digest
+type DigestNotifier interface {
+	WantsDigest(now time.Time) bool
+	SendDigest(ctx context.Context, digest Digest) error
+}
+
+// Digest summarises every monitor over the last 24 hours
+type Digest struct {
+	From     time.Time
+	To       time.Time
+	Monitors []DigestMonitor
+}
+
+// DigestMonitor is one monitor's line in the digest
+type DigestMonitor struct {
+	Monitor   models.MonitorEntry
+	Stats     models.MonitorStats
+	Incidents []models.Incident
+}
+
+/*
+Function to build the digest of the tenant's monitors for the 24 hours leading up to now
+*/
+func BuildDigest(tenant db.Tenant, now time.Time) (Digest, error) {
+	span := tracer.StartSpan("notify.build_digest")
+	defer span.Finish()
+
+	digest := Digest{From: now.Add(-digestWindow), To: now}
+
+	monitors, err := db.GetAllMonitors(tenant)
+	if err != nil {
+		span.SetTag("error", true)
+		span.SetTag("error.message", err.Error())
+		return Digest{}, err
+	}
+
+	for _, monitor := range monitors {
+		stats, err := db.GetMonitorStats(monitor.ID, digest.From, digest.To)
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return Digest{}, err
+		}
+
+		incidents, err := db.GetIncidents(db.IncidentFilter{MonitorID: monitor.ID, ActiveSince: digest.From})
+		if err != nil {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			return Digest{}, err
+		}
+
+		digest.Monitors = append(digest.Monitors, DigestMonitor{
+			Monitor:   monitor,
+			Stats:     stats,
+			Incidents: incidents,
+		})
+	}
+
+	return digest, nil
+}
+
+// Subject is the mail subject for the digest
+func (d Digest) Subject() string {
+	return "[url-monitor] Daily digest for " + d.To.UTC().Format("Mon, 02 Jan 2006")
+}
+
+// Body renders the digest as plain text
+func (d Digest) Body() string {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Uptime from %s to %s\n\n", d.From.UTC().Format(time.RFC1123), d.To.UTC().Format(time.RFC1123))
+
+	if len(d.Monitors) == 0 {
+		body.WriteString("No monitors are configured.\n")
+		return body.String()
+	}
+
+	for _, line := range d.Monitors {
+		if line.Stats.TotalChecks == 0 {
+			fmt.Fprintf(&body, "%s\n  No checks in the last 24h\n", line.Monitor.URL)
+		} else {
+			fmt.Fprintf(&body, "%s\n  Uptime: %.2f%% (%d of %d checks failed)\n",
+				line.Monitor.URL,
+				line.Stats.UptimePercent,
+				line.Stats.FailedChecks,
+				line.Stats.TotalChecks,
+			)
+		}
+
+		for _, incident := range line.Incidents {
+			if incident.ResolvedAt == nil {
+				fmt.Fprintf(&body, "  - Ongoing since %s: %s\n", incident.StartedAt.UTC().Format(time.RFC1123), incident.Cause)
+				continue
+			}
+			fmt.Fprintf(&body, "  - %s, down for %v: %s\n",
+				incident.StartedAt.UTC().Format(time.RFC1123),
+				incident.Duration.Round(time.Second),
+				incident.Cause,
+			)
+		}
+
+		body.WriteString("\n")
+	}
+
+	return body.String()
+}
+
+/*
+Function to start the background loop that sends the daily digest to every channel
+that asked for one
+*/
+func StartDigestScheduler() {
+	go func() {
+		sent := map[string]string{}
+
+		ticker := time.NewTicker(digestPollInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			sendDigests(now, sent)
+		}
+	}()
+}
+
+/*
+Function to send the digest to each channel that is due and hasn't had today's yet
+*/
+func sendDigests(now time.Time, sent map[string]string) {
+	/*
+		This function records the UTC date each channel last received a digest in,
+		so a channel is sent at most one digest per day even though the scheduler
+		polls many times during its digest hour.
+	*/
+	channels, err := db.GetChannels(db.AllTeams, true)
+	if err != nil {
+		log.Printf("Error loading notification channels: %v", err)
+		return
+	}
+
+	today := now.UTC().Format(time.DateOnly)
+
+	var due []models.NotificationChannel
+	var notifiers []DigestNotifier
+
+	for _, channel := range channels {
+		notifier, err := New(channel)
+		if err != nil {
+			continue
+		}
+
+		digester, ok := notifier.(DigestNotifier)
+		if !ok || !digester.WantsDigest(now) || sent[channel.ID] == today {
+			continue
+		}
+
+		due = append(due, channel)
+		notifiers = append(notifiers, digester)
+	}
+
+	if len(due) == 0 {
+		return
+	}
+
+	// Each channel only hears about its own team's monitors
+	digests := map[string]Digest{}
+
+	for i, channel := range due {
+		digest, ok := digests[channel.TeamID]
+		if !ok {
+			digest, err = BuildDigest(db.ForTeams(channel.TeamID), now)
+			if err != nil {
+				log.Printf("Error building daily digest for team %s: %v", channel.TeamID, err)
+				continue
+			}
+			digests[channel.TeamID] = digest
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := notifiers[i].SendDigest(ctx, digest)
+		cancel()
+
+		if err != nil {
+			log.Printf("Error sending daily digest to channel %s: %v", channel.ID, err)
+			continue
+		}
+
+		sent[channel.ID] = today
+		log.Printf("Sent daily digest to channel %s", channel.ID)
+	}
+}
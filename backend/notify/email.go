@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+type emailConfig struct {
+	Host       string   `json:"host"`
+	Port       int      `json:"port"`
+	StartTLS   bool     `json:"starttls"`
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	From       string   `json:"from"`
+	To         []string `json:"to"`
+	Digest     bool     `json:"digest"`      // <- Also send the daily digest
+	DigestHour int      `json:"digest_hour"` // <- Hour of day (UTC) the digest goes out
+}
+
+// EmailNotifier sends down/recovery mails (and optionally the daily digest) through an SMTP relay
+type EmailNotifier struct {
+	config emailConfig
+}
+
+func newEmailNotifier(raw json.RawMessage) (Notifier, error) {
+	config := emailConfig{Port: 587}
+	err := json.Unmarshal(raw, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email config: %w", err)
+	}
+
+	if config.Host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	if config.Port < 1 || config.Port > 65535 {
+		return nil, fmt.Errorf("port must be between 1 and 65535")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("from and at least one to address are required")
+	}
+	if config.DigestHour < 0 || config.DigestHour > 23 {
+		return nil, fmt.Errorf("digest_hour must be between 0 and 23")
+	}
+
+	return &EmailNotifier{config: config}, nil
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, event models.IncidentEvent) error {
+	subject := "[url-monitor] DOWN: " + event.Monitor.URL
+	if event.Type == models.EventIncidentResolved {
+		subject = "[url-monitor] RECOVERED: " + event.Monitor.URL
+	}
+
+	return e.send(ctx, subject, incidentEmailBody(event))
+}
+
+/*
+Function to send the daily digest, if this channel asked for it
+*/
+func (e *EmailNotifier) SendDigest(ctx context.Context, digest Digest) error {
+	return e.send(ctx, digest.Subject(), digest.Body())
+}
+
+// WantsDigest reports whether the digest is due from this channel at the given time
+func (e *EmailNotifier) WantsDigest(now time.Time) bool {
+	return e.config.Digest && now.UTC().Hour() == e.config.DigestHour
+}
+
+func (e *EmailNotifier) send(ctx context.Context, subject string, body string) error {
+	/*
+		This function dials the relay, upgrades with STARTTLS when configured,
+		authenticates if a username is set, and sends one plain-text message to
+		every recipient.
+	*/
+	address := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("error connecting to smtp relay: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if e.config.StartTLS {
+		err = client.StartTLS(&tls.Config{ServerName: e.config.Host})
+		if err != nil {
+			return fmt.Errorf("error starting tls: %w", err)
+		}
+	}
+
+	if e.config.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host))
+		if err != nil {
+			return fmt.Errorf("error authenticating with smtp relay: %w", err)
+		}
+	}
+
+	err = client.Mail(e.config.From)
+	if err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+
+	for _, recipient := range e.config.To {
+		err = client.Rcpt(recipient)
+		if err != nil {
+			return fmt.Errorf("error adding recipient %s: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message: %w", err)
+	}
+
+	_, err = writer.Write(buildMessage(e.config.From, e.config.To, subject, body))
+	if err != nil {
+		return fmt.Errorf("error writing message: %w", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage assembles the headers and CRLF-terminated body of a plain-text mail
+func buildMessage(from string, to []string, subject string, body string) []byte {
+	var message strings.Builder
+
+	message.WriteString("From: " + from + "\r\n")
+	message.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	message.WriteString("Subject: " + subject + "\r\n")
+	message.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return []byte(message.String())
+}
+
+// incidentEmailBody describes a single incident transition
+func incidentEmailBody(event models.IncidentEvent) string {
+	var body strings.Builder
+
+	if event.Type == models.EventIncidentResolved {
+		fmt.Fprintf(&body, "%s is back up after %v.\n\n", event.Monitor.URL, event.Incident.Duration.Round(time.Second))
+	} else {
+		fmt.Fprintf(&body, "%s is down.\n\n", event.Monitor.URL)
+	}
+
+	fmt.Fprintf(&body, "Cause:         %s\n", event.Incident.Cause)
+	fmt.Fprintf(&body, "Started:       %s\n", event.Incident.StartedAt.UTC().Format(time.RFC1123))
+	if event.Incident.ResolvedAt != nil {
+		fmt.Fprintf(&body, "Resolved:      %s\n", event.Incident.ResolvedAt.UTC().Format(time.RFC1123))
+	}
+	fmt.Fprintf(&body, "Status code:   %d\n", event.Result.StatusCode)
+	fmt.Fprintf(&body, "Response time: %v\n", event.Result.ResponseTime)
+	if event.Result.Error != "" {
+		fmt.Fprintf(&body, "Error:         %s\n", event.Result.Error)
+	}
+	fmt.Fprintf(&body, "\nMonitor ID: %s\nIncident ID: %s\n", event.Monitor.ID, event.Incident.ID)
+
+	return body.String()
+}
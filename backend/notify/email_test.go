@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturedMail is one message a fake SMTP server accepted
+type capturedMail struct {
+	auth       bool
+	from       string
+	recipients []string
+	data       string
+}
+
+// newSMTPServer starts a minimal SMTP relay on localhost that records every message
+func newSMTPServer(t *testing.T) (string, int, <-chan capturedMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan capturedMail, 10)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+
+	address := listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), address.Port, received
+}
+
+func serveSMTP(conn net.Conn, received chan<- capturedMail) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var mail capturedMail
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH"):
+			mail.auth = true
+			reply("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			mail.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			mail.recipients = append(mail.recipients, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			mail.data = data.String()
+			received <- mail
+			mail = capturedMail{}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func newTestEmailNotifier(t *testing.T, host string, port int, extra map[string]any) *EmailNotifier {
+	t.Helper()
+
+	fields := map[string]any{
+		"host":     host,
+		"port":     port,
+		"username": "alerts",
+		"password": "hunter2",
+		"from":     "monitor@example.com",
+		"to":       []string{"oncall@example.com", "team@example.com"},
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+
+	config, _ := json.Marshal(fields)
+	notifier, err := New(models.NotificationChannel{Type: "email", Config: config})
+	require.NoError(t, err)
+
+	return notifier.(*EmailNotifier)
+}
+
+func TestEmailNotifier_SendsIncidentMail(t *testing.T) {
+	host, port, received := newSMTPServer(t)
+	notifier := newTestEmailNotifier(t, host, port, nil)
+
+	event := testEvent()
+	event.Type = models.EventIncidentOpened
+	event.Incident.ResolvedAt = nil
+	event.Incident.Cause = "HTTP 503"
+
+	require.NoError(t, notifier.Notify(context.Background(), event))
+
+	mail := <-received
+	assert.True(t, mail.auth)
+	assert.Equal(t, "monitor@example.com", mail.from)
+	assert.Equal(t, []string{"oncall@example.com", "team@example.com"}, mail.recipients)
+	assert.Contains(t, mail.data, "Subject: [url-monitor] DOWN: https://www.example.com\r\n")
+	assert.Contains(t, mail.data, "Cause:         HTTP 503")
+}
+
+func TestEmailNotifier_Validation(t *testing.T) {
+	assert.Error(t, Validate("email", json.RawMessage(`{"from": "a@example.com", "to": ["b@example.com"]}`)))
+	assert.Error(t, Validate("email", json.RawMessage(`{"host": "smtp.example.com", "from": "a@example.com"}`)))
+	assert.Error(t, Validate("email", json.RawMessage(`{"host": "smtp.example.com", "from": "a@example.com", "to": ["b@example.com"], "digest_hour": 24}`)))
+	assert.NoError(t, Validate("email", json.RawMessage(`{"host": "smtp.example.com", "from": "a@example.com", "to": ["b@example.com"]}`)))
+
+	redacted := RedactConfig("email", json.RawMessage(`{"host": "smtp.example.com", "password": "hunter2"}`))
+	assert.NotContains(t, string(redacted), "hunter2")
+}
+
+func TestSendDigests_OncePerDay(t *testing.T) {
+	setupTestDB(t)
+	host, port, received := newSMTPServer(t)
+
+	now := time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC)
+
+	// One monitor with a failed check and an incident inside the window
+	monitor := models.MonitorEntry{ID: "monitor1", URL: "https://www.example.com", CheckInterval: 60, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.SaveMonitor(monitor))
+	require.NoError(t, db.SaveResult(monitor.ID, models.MonitorResult{StatusCode: 200, IsUp: true, Timestamp: now.Add(-2 * time.Hour)}))
+	require.NoError(t, db.SaveResult(monitor.ID, models.MonitorResult{StatusCode: 503, IsUp: false, Timestamp: now.Add(-time.Hour)}))
+	require.NoError(t, db.CreateIncident(models.Incident{ID: "incident1", MonitorID: monitor.ID, Status: models.IncidentOpen, Cause: "HTTP 503", StartedAt: now.Add(-time.Hour)}))
+
+	config, _ := json.Marshal(map[string]any{
+		"host": host, "port": port, "from": "monitor@example.com", "to": []string{"oncall@example.com"},
+		"digest": true, "digest_hour": 8,
+	})
+	require.NoError(t, db.SaveChannel(models.NotificationChannel{
+		ID: "channel1", Name: "mail", Type: "email", Config: config, Enabled: true, CreatedAt: now, UpdatedAt: now,
+	}))
+
+	sent := map[string]string{}
+	sendDigests(now.Add(-time.Hour), sent) // <- Not the digest hour yet
+	sendDigests(now, sent)
+	sendDigests(now.Add(10*time.Minute), sent) // <- Already sent today
+
+	mail := <-received
+	assert.Contains(t, mail.data, "Subject: [url-monitor] Daily digest for Sat, 01 Jun 2024")
+	assert.Contains(t, mail.data, "Uptime: 50.00% (1 of 2 checks failed)")
+	assert.Contains(t, mail.data, "Ongoing since")
+	assert.Len(t, received, 0)
+}
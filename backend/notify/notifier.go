@@ -0,0 +1,277 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/metrics"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Notifier delivers incident events to one destination
+type Notifier interface {
+	Notify(ctx context.Context, event models.IncidentEvent) error
+}
+
+// channelType describes how to build a notifier of one type from its stored config
+type channelType struct {
+	build   func(config json.RawMessage) (Notifier, error)
+	secrets []string // <- Top-level config fields hidden from API responses
+}
+
+// Every channel type the API accepts
+var channelTypes = map[string]channelType{
+	"webhook":   {build: newWebhookNotifier, secrets: []string{"secret", "headers"}},
+	"slack":     {build: newSlackNotifier, secrets: []string{"url"}},
+	"discord":   {build: newDiscordNotifier, secrets: []string{"url"}},
+	"pagerduty": {build: newPagerDutyNotifier, secrets: []string{"routing_key"}},
+	"email":     {build: newEmailNotifier, secrets: []string{"password"}},
+}
+
+// RedactedValue replaces secret config values in API responses
+const RedactedValue = "********"
+
+// How long a single delivery attempt may take
+const sendTimeout = 30 * time.Second
+
+// Attempts made before a delivery is given up on
+const maxAttempts = 5
+
+// Wait before the first retry, doubled for every retry after it
+var retryBackoff = 2 * time.Second
+
+// Tracks in-flight deliveries so callers can wait for them
+var pending sync.WaitGroup
+
+// Closed by Shutdown so deliveries stop backing off and give up
+var (
+	stopping     = make(chan struct{})
+	stoppingOnce sync.Once
+)
+
+// permanentError marks a delivery failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// permanent wraps err so send doesn't retry it
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+/*
+Function to build the notifier for a stored channel
+*/
+func New(channel models.NotificationChannel) (Notifier, error) {
+	kind, ok := channelTypes[channel.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown channel type %q", channel.Type)
+	}
+	return kind.build(channel.Config)
+}
+
+/*
+Function to check that a config is valid for a channel type before it is saved
+*/
+func Validate(channelType string, config json.RawMessage) error {
+	_, err := New(models.NotificationChannel{Type: channelType, Config: config})
+	return err
+}
+
+/*
+Function to replace secret config fields with RedactedValue for API responses
+*/
+func RedactConfig(channelType string, config json.RawMessage) json.RawMessage {
+	fields, err := decodeConfigFields(config)
+	if err != nil {
+		return config
+	}
+
+	for _, secret := range channelTypes[channelType].secrets {
+		if _, ok := fields[secret]; ok {
+			fields[secret] = json.RawMessage(`"` + RedactedValue + `"`)
+		}
+	}
+
+	redacted, _ := json.Marshal(fields)
+	return redacted
+}
+
+/*
+Function to carry stored secrets over into an updated config wherever the client
+sent back RedactedValue instead of a new secret
+*/
+func MergeSecrets(channelType string, updated json.RawMessage, stored json.RawMessage) json.RawMessage {
+	updatedFields, err := decodeConfigFields(updated)
+	if err != nil {
+		return updated
+	}
+
+	storedFields, err := decodeConfigFields(stored)
+	if err != nil {
+		return updated
+	}
+
+	for _, secret := range channelTypes[channelType].secrets {
+		var value string
+		if json.Unmarshal(updatedFields[secret], &value) == nil && value == RedactedValue {
+			updatedFields[secret] = storedFields[secret]
+		}
+	}
+
+	merged, _ := json.Marshal(updatedFields)
+	return merged
+}
+
+/*
+Function to send an incident event to every enabled channel of the monitor's team
+in the background
+*/
+func Dispatch(event models.IncidentEvent) {
+	channels, err := db.GetChannels(db.ForTeams(event.Monitor.TeamID), true)
+	if err != nil {
+		log.Printf("Error loading notification channels: %v", err)
+		return
+	}
+
+	for _, channel := range channels {
+		notifier, err := New(channel)
+		if err != nil {
+			log.Printf("Skipping notification channel %s: %v", channel.ID, err)
+			continue
+		}
+
+		pending.Add(1)
+		go func() {
+			defer pending.Done()
+			send(channel, notifier, event)
+		}()
+	}
+}
+
+/*
+Function to block until every dispatched notification has been sent or has failed
+*/
+func Wait() {
+	pending.Wait()
+}
+
+/*
+Function to stop retrying failed deliveries and wait for in-flight attempts to finish
+*/
+func Shutdown() {
+	stoppingOnce.Do(func() { close(stopping) })
+	pending.Wait()
+}
+
+func send(channel models.NotificationChannel, notifier Notifier, event models.IncidentEvent) {
+	/*
+		This function makes up to maxAttempts attempts with exponential backoff
+		between them, recording every attempt in the deliveries table. Permanent
+		errors (e.g. the endpoint rejected the payload) stop retrying straight away.
+	*/
+	span := tracer.StartSpan("notify.send",
+		tracer.ResourceName(channel.Type),
+		tracer.Tag("channel.id", channel.ID),
+		tracer.Tag("monitor.id", event.Monitor.ID),
+		tracer.Tag("event", event.Type),
+	)
+	defer span.Finish()
+
+	tags := []string{"channel_type:" + channel.Type, "event:" + event.Type}
+	backoff := retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(tracer.ContextWithSpan(context.Background(), span), sendTimeout)
+		err := notifier.Notify(ctx, event)
+		cancel()
+
+		recordDelivery(channel, event, attempt, err)
+
+		if err == nil {
+			if metrics.Client != nil {
+				metrics.Client.Incr("notifications.sent", tags, 1.0)
+			}
+			log.Printf("Sent %s for monitor %s to channel %s", event.Type, event.Monitor.ID, channel.ID)
+			return
+		}
+
+		log.Printf("Error sending %s to channel %s (attempt %d): %v", event.Type, channel.ID, attempt, err)
+
+		var permanentErr *permanentError
+		if attempt == maxAttempts || errors.As(err, &permanentErr) || !waitToRetry(backoff) {
+			span.SetTag("error", true)
+			span.SetTag("error.message", err.Error())
+			span.SetTag("attempts", attempt)
+
+			if metrics.Client != nil {
+				metrics.Client.Incr("notifications.failed", tags, 1.0)
+			}
+			return
+		}
+
+		if metrics.Client != nil {
+			metrics.Client.Incr("notifications.retried", tags, 1.0)
+		}
+		backoff *= 2
+	}
+}
+
+// waitToRetry sleeps for the backoff and reports false if shutdown started meanwhile
+func waitToRetry(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stopping:
+		return false
+	}
+}
+
+// recordDelivery persists the outcome of one attempt so failures show up in the API
+func recordDelivery(channel models.NotificationChannel, event models.IncidentEvent, attempt int, err error) {
+	delivery := models.NotificationDelivery{
+		ChannelID:  channel.ID,
+		MonitorID:  event.Monitor.ID,
+		IncidentID: event.Incident.ID,
+		Event:      event.Type,
+		Attempt:    attempt,
+		Status:     models.DeliverySucceeded,
+		CreatedAt:  time.Now(),
+	}
+
+	if err != nil {
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+	}
+
+	saveErr := db.SaveDelivery(delivery)
+	if saveErr != nil {
+		log.Printf("Error recording delivery to channel %s: %v", channel.ID, saveErr)
+	}
+}
+
+// decodeConfigFields splits a JSON object config into its top-level fields
+func decodeConfigFields(config json.RawMessage) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(config, &fields)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	return fields, nil
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// PagerDuty's Events API v2 endpoint, used unless the channel points elsewhere
+const defaultEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Severities accepted by the Events API
+var eventSeverities = map[string]bool{"critical": true, "error": true, "warning": true, "info": true}
+
+type pagerDutyConfig struct {
+	RoutingKey string `json:"routing_key"`
+	URL        string `json:"url"`      // <- Any Events-API-compatible endpoint
+	Severity   string `json:"severity"` // <- Defaults to critical
+}
+
+// eventsPayload is the Events API v2 request body
+type eventsPayload struct {
+	RoutingKey  string         `json:"routing_key"`
+	EventAction string         `json:"event_action"`
+	DedupKey    string         `json:"dedup_key"`
+	Payload     *eventsDetails `json:"payload,omitempty"` // <- Only sent with trigger
+}
+
+type eventsDetails struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     time.Time      `json:"timestamp"`
+	Component     string         `json:"component"`
+	CustomDetails map[string]any `json:"custom_details"`
+}
+
+// PagerDutyNotifier triggers and resolves alerts through an Events API v2 endpoint
+type PagerDutyNotifier struct {
+	config pagerDutyConfig
+	client *http.Client
+}
+
+func newPagerDutyNotifier(raw json.RawMessage) (Notifier, error) {
+	config := pagerDutyConfig{URL: defaultEventsURL, Severity: "critical"}
+	err := json.Unmarshal(raw, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagerduty config: %w", err)
+	}
+
+	if config.RoutingKey == "" {
+		return nil, fmt.Errorf("routing_key is required")
+	}
+
+	err = validateEndpoint(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !eventSeverities[config.Severity] {
+		return nil, fmt.Errorf("severity must be critical, error, warning or info")
+	}
+
+	return &PagerDutyNotifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *PagerDutyNotifier) Notify(ctx context.Context, event models.IncidentEvent) error {
+	payload := eventsPayload{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    DedupKey(event.Incident),
+	}
+
+	if event.Type == models.EventIncidentOpened {
+		payload.EventAction = "trigger"
+		payload.Payload = &eventsDetails{
+			Summary:   fmt.Sprintf("%s is down: %s", event.Monitor.URL, event.Incident.Cause),
+			Source:    event.Monitor.URL,
+			Severity:  p.config.Severity,
+			Timestamp: event.Incident.StartedAt,
+			Component: event.Monitor.ID,
+			CustomDetails: map[string]any{
+				"monitor_id":    event.Monitor.ID,
+				"incident_id":   event.Incident.ID,
+				"status_code":   event.Result.StatusCode,
+				"response_time": event.Result.ResponseTime.String(),
+				"error":         event.Result.Error,
+			},
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding events payload: %w", err)
+	}
+
+	return postJSON(ctx, p.client, p.config.URL, body, nil)
+}
+
+/*
+Function to build the dedup key for an incident. It is the same for the trigger
+and the resolve, so repeat deliveries of either collapse into one alert, and it
+differs between incidents, so a resolve retried late for one incident can't
+close the page of the monitor's next one.
+*/
+func DedupKey(incident models.Incident) string {
+	return "url-monitor-" + incident.MonitorID + "-" + incident.ID
+}
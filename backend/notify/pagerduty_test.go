@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDutyNotifier_TriggerAndResolveShareDedupKey(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusAccepted)
+
+	config, _ := json.Marshal(map[string]any{"routing_key": "R0UT1NG", "url": server.URL, "severity": "error"})
+	notifier, err := New(models.NotificationChannel{Type: "pagerduty", Config: config})
+	require.NoError(t, err)
+
+	opened := testEvent()
+	opened.Type = models.EventIncidentOpened
+	opened.Incident.Cause = "HTTP 503"
+	require.NoError(t, notifier.Notify(context.Background(), opened))
+	require.NoError(t, notifier.Notify(context.Background(), testEvent()))
+
+	var trigger, resolve eventsPayload
+	require.NoError(t, json.Unmarshal((<-received).body, &trigger))
+	require.NoError(t, json.Unmarshal((<-received).body, &resolve))
+
+	assert.Equal(t, "trigger", trigger.EventAction)
+	assert.Equal(t, "R0UT1NG", trigger.RoutingKey)
+	assert.Equal(t, "url-monitor-monitor1-incident1", trigger.DedupKey)
+	require.NotNil(t, trigger.Payload)
+	assert.Equal(t, "https://www.example.com is down: HTTP 503", trigger.Payload.Summary)
+	assert.Equal(t, "error", trigger.Payload.Severity)
+
+	assert.Equal(t, "resolve", resolve.EventAction)
+	assert.Equal(t, trigger.DedupKey, resolve.DedupKey)
+	assert.Nil(t, resolve.Payload)
+
+}
+
+func TestPagerDutyNotifier_LateResolveLeavesTheNextIncidentOpen(t *testing.T) {
+	setupTestDB(t)
+
+	original := retryBackoff
+	retryBackoff = 20 * time.Millisecond
+	t.Cleanup(func() { retryBackoff = original })
+
+	// Tracks open alerts by dedup key, and refuses resolves until the next incident has triggered
+	var mu sync.Mutex
+	open := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload eventsPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if payload.EventAction == "trigger" {
+			open[payload.DedupKey] = true
+		} else if len(open) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		} else {
+			delete(open, payload.DedupKey)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	saveTestChannel(t, "pager", "pagerduty", map[string]any{"routing_key": "k", "url": server.URL})
+
+	first := testEvent()
+	first.Type = models.EventIncidentOpened
+	Dispatch(first)
+	Wait()
+
+	// Incident 1's resolve fails and is retried after incident 2 has triggered
+	Dispatch(testEvent())
+
+	second := testEvent()
+	second.Type = models.EventIncidentOpened
+	second.Incident.ID = "incident2"
+	Dispatch(second)
+	Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]bool{"url-monitor-monitor1-incident2": true}, open)
+}
+
+func TestPagerDutyNotifier_Validation(t *testing.T) {
+	assert.Error(t, Validate("pagerduty", json.RawMessage(`{}`)))
+	assert.Error(t, Validate("pagerduty", json.RawMessage(`{"routing_key": "k", "severity": "sev1"}`)))
+	assert.NoError(t, Validate("pagerduty", json.RawMessage(`{"routing_key": "k"}`)))
+}
+
+// saveTestChannel stores an enabled channel with the given config
+func saveTestChannel(t *testing.T, id string, channelType string, config map[string]any) {
+	t.Helper()
+	raw, _ := json.Marshal(config)
+	require.NoError(t, db.SaveChannel(models.NotificationChannel{
+		ID:        id,
+		Name:      id,
+		Type:      channelType,
+		Config:    raw,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}))
+}
+
+func TestDispatch_RetriesWithBackoff(t *testing.T) {
+	setupTestDB(t)
+
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = original })
+
+	// Fails twice with a retryable status, then accepts the event
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	saveTestChannel(t, "pager", "pagerduty", map[string]any{"routing_key": "k", "url": server.URL})
+
+	Dispatch(testEvent())
+	Wait()
+
+	deliveries, err := db.GetDeliveries(db.DeliveryFilter{ChannelID: "pager"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+
+	// Newest first
+	assert.Equal(t, models.DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempt)
+	assert.Equal(t, models.DeliveryFailed, deliveries[2].Status)
+	assert.Contains(t, deliveries[2].Error, "503")
+	assert.Equal(t, "incident1", deliveries[2].IncidentID)
+}
+
+func TestDispatch_DoesNotRetryPermanentErrors(t *testing.T) {
+	setupTestDB(t)
+
+	original := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = original })
+
+	server, received := newWebhookServer(t, http.StatusBadRequest)
+	saveTestChannel(t, "pager", "pagerduty", map[string]any{"routing_key": "k", "url": server.URL})
+
+	Dispatch(testEvent())
+	Wait()
+
+	assert.Len(t, received, 1)
+
+	deliveries, err := db.GetDeliveries(db.DeliveryFilter{ChannelID: "pager", Status: models.DeliveryFailed})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
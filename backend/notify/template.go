@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Messages used when a chat channel doesn't set its own templates
+const (
+	DefaultOpenedTemplate   = `:red_circle: {{.URL}} is DOWN{{if .Error}}: {{.Error}}{{else}} (HTTP {{.StatusCode}}){{end}}`
+	DefaultResolvedTemplate = `:large_green_circle: {{.URL}} is back UP after {{.Duration}} (HTTP {{.StatusCode}} in {{.ResponseTime}})`
+)
+
+// MessageData is what message templates can reference, e.g. {{.URL}} or {{.Duration}}
+type MessageData struct {
+	Event        string
+	MonitorID    string
+	URL          string
+	StatusCode   int
+	Error        string
+	ResponseTime time.Duration
+	Duration     time.Duration // <- How long the monitor has been (or was) down
+	StartedAt    time.Time
+	ResolvedAt   *time.Time
+}
+
+// messageTemplates holds the parsed opened/resolved templates for one channel
+type messageTemplates struct {
+	opened   *template.Template
+	resolved *template.Template
+}
+
+/*
+Function to parse a channel's templates, falling back to the defaults for empty ones
+*/
+func parseMessageTemplates(opened string, resolved string) (messageTemplates, error) {
+	if opened == "" {
+		opened = DefaultOpenedTemplate
+	}
+	if resolved == "" {
+		resolved = DefaultResolvedTemplate
+	}
+
+	openedTemplate, err := template.New("opened").Option("missingkey=error").Parse(opened)
+	if err != nil {
+		return messageTemplates{}, fmt.Errorf("invalid opened template: %w", err)
+	}
+
+	resolvedTemplate, err := template.New("resolved").Option("missingkey=error").Parse(resolved)
+	if err != nil {
+		return messageTemplates{}, fmt.Errorf("invalid resolved template: %w", err)
+	}
+
+	templates := messageTemplates{opened: openedTemplate, resolved: resolvedTemplate}
+
+	// Render against sample data so references to unknown fields fail at save time
+	_, err = templates.render(models.IncidentEvent{Type: models.EventIncidentOpened})
+	if err == nil {
+		_, err = templates.render(models.IncidentEvent{Type: models.EventIncidentResolved})
+	}
+	if err != nil {
+		return messageTemplates{}, err
+	}
+
+	return templates, nil
+}
+
+/*
+Function to render the message for an event with the matching template
+*/
+func (m messageTemplates) render(event models.IncidentEvent) (string, error) {
+	tmpl := m.opened
+	if event.Type == models.EventIncidentResolved {
+		tmpl = m.resolved
+	}
+
+	var message bytes.Buffer
+	err := tmpl.Execute(&message, newMessageData(event))
+	if err != nil {
+		return "", fmt.Errorf("error rendering %s template: %w", tmpl.Name(), err)
+	}
+
+	return message.String(), nil
+}
+
+func newMessageData(event models.IncidentEvent) MessageData {
+	return MessageData{
+		Event:        event.Type,
+		MonitorID:    event.Monitor.ID,
+		URL:          event.Monitor.URL,
+		StatusCode:   event.Result.StatusCode,
+		Error:        event.Result.Error,
+		ResponseTime: event.Result.ResponseTime,
+		Duration:     event.Incident.Duration.Round(time.Second),
+		StartedAt:    event.Incident.StartedAt,
+		ResolvedAt:   event.Incident.ResolvedAt,
+	}
+}
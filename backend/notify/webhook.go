@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// Header carrying the hex HMAC-SHA256 of the request body when a secret is set
+const SignatureHeader = "X-URL-Monitor-Signature"
+
+type webhookConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Secret  string            `json:"secret"`
+}
+
+// webhookMonitor is the part of a monitor a webhook is told about. Credentials,
+// headers and ping tokens stay out, since the endpoint belongs to someone else.
+type webhookMonitor struct {
+	ID     string `json:"id"`
+	TeamID string `json:"team_id"`
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+}
+
+// webhookPayload is the JSON body POSTed to a generic webhook
+type webhookPayload struct {
+	Event    string               `json:"event"`
+	Monitor  webhookMonitor       `json:"monitor"`
+	Incident models.Incident      `json:"incident"`
+	Result   models.MonitorResult `json:"result"`
+	Duration time.Duration        `json:"duration"`
+	SentAt   time.Time            `json:"sent_at"`
+}
+
+// WebhookNotifier POSTs incident events as JSON to a configurable URL
+type WebhookNotifier struct {
+	config webhookConfig
+	client *http.Client
+}
+
+func newWebhookNotifier(raw json.RawMessage) (Notifier, error) {
+	var config webhookConfig
+	err := json.Unmarshal(raw, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+
+	err = validateEndpoint(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookNotifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, event models.IncidentEvent) error {
+	body, err := json.Marshal(webhookPayload{
+		Event: event.Type,
+		Monitor: webhookMonitor{
+			ID:     event.Monitor.ID,
+			TeamID: event.Monitor.TeamID,
+			Type:   event.Monitor.Type,
+			URL:    event.Monitor.URL,
+		},
+		Incident: event.Incident,
+		Result:   event.Result,
+		Duration: event.Incident.Duration,
+		SentAt:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	headers := map[string]string{}
+	for name, value := range w.config.Headers {
+		headers[name] = value
+	}
+
+	if w.config.Secret != "" {
+		headers[SignatureHeader] = "sha256=" + Sign(w.config.Secret, body)
+	}
+	headers["X-URL-Monitor-Event"] = event.Type
+
+	return postJSON(ctx, w.client, w.config.URL, body, headers)
+}
+
+/*
+Function to compute the hex HMAC-SHA256 of a body, as sent in SignatureHeader
+*/
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateEndpoint requires an absolute http(s) URL
+func validateEndpoint(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	return nil
+}
+
+// postJSON sends a JSON body and treats any non-2xx response as an error. Client
+// errors other than 408 and 429 are permanent: sending the same body again won't help.
+func postJSON(ctx context.Context, client *http.Client, targetURL string, body []byte, headers map[string]string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error building request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		err = fmt.Errorf("endpoint returned %d: %s", response.StatusCode, bytes.TrimSpace(detail))
+
+		if response.StatusCode >= 400 && response.StatusCode < 500 &&
+			response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests {
+			return permanent(err)
+		}
+		return err
+	}
+
+	return nil
+}
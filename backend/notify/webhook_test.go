@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+	m.Run()
+}
+
+// setupTestDB initializes a clean database for each test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db.CloseDB()
+	err := db.InitDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.CloseDB()
+	})
+}
+
+// capturedRequest is what a fake webhook endpoint received
+type capturedRequest struct {
+	headers http.Header
+	body    []byte
+}
+
+// newWebhookServer records every request and answers with status
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, chan capturedRequest) {
+	t.Helper()
+	received := make(chan capturedRequest, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- capturedRequest{headers: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+}
+
+// testEvent is a resolved incident for a monitor that was down for five minutes
+func testEvent() models.IncidentEvent {
+	started := time.Now().Add(-5 * time.Minute)
+	resolved := time.Now()
+
+	return models.IncidentEvent{
+		Type:    models.EventIncidentResolved,
+		Monitor: models.MonitorEntry{ID: "monitor1", TeamID: models.DefaultTeam, URL: "https://www.example.com"},
+		Incident: models.Incident{
+			ID:         "incident1",
+			MonitorID:  "monitor1",
+			Status:     models.IncidentResolved,
+			StartedAt:  started,
+			ResolvedAt: &resolved,
+			Duration:   5 * time.Minute,
+		},
+		Result: models.MonitorResult{StatusCode: 200, IsUp: true, Timestamp: resolved},
+	}
+}
+
+func TestWebhookNotifier_SignsPayload(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	config, _ := json.Marshal(map[string]any{
+		"url":     server.URL,
+		"headers": map[string]string{"X-Team": "platform"},
+		"secret":  "shh",
+	})
+	notifier, err := New(models.NotificationChannel{Type: "webhook", Config: config})
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), testEvent()))
+
+	request := <-received
+	assert.Equal(t, "platform", request.headers.Get("X-Team"))
+	assert.Equal(t, models.EventIncidentResolved, request.headers.Get("X-URL-Monitor-Event"))
+	assert.Equal(t, "sha256="+Sign("shh", request.body), request.headers.Get(SignatureHeader))
+
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(request.body, &payload))
+	assert.Equal(t, "incident.resolved", payload.Event)
+	assert.Equal(t, "https://www.example.com", payload.Monitor.URL)
+	assert.Equal(t, 200, payload.Result.StatusCode)
+	assert.Equal(t, 5*time.Minute, payload.Duration)
+}
+
+func TestWebhookNotifier_LeavesOutMonitorSecrets(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	notifier, err := New(models.NotificationChannel{
+		Type:   "webhook",
+		Config: json.RawMessage(`{"url": "` + server.URL + `"}`),
+	})
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Monitor.Auth = &models.RequestAuth{Type: models.AuthBearer, Token: "bearer-secret"}
+	event.Monitor.Headers = map[string]string{"X-Api-Key": "header-secret"}
+	event.Monitor.PingToken = "ping-secret"
+
+	require.NoError(t, notifier.Notify(context.Background(), event))
+
+	request := <-received
+	for _, secret := range []string{"bearer-secret", "header-secret", "ping-secret"} {
+		assert.NotContains(t, string(request.body), secret)
+	}
+	assert.Contains(t, string(request.body), "https://www.example.com")
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server, _ := newWebhookServer(t, http.StatusInternalServerError)
+
+	notifier, err := New(models.NotificationChannel{
+		Type:   "webhook",
+		Config: json.RawMessage(`{"url": "` + server.URL + `"}`),
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, notifier.Notify(context.Background(), testEvent()))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("webhook", json.RawMessage(`{"url": "https://hooks.example.com/x"}`)))
+	assert.Error(t, Validate("webhook", json.RawMessage(`{"url": "not a url"}`)))
+	assert.Error(t, Validate("carrier-pigeon", json.RawMessage(`{}`)))
+}
+
+func TestRedactAndMergeSecrets(t *testing.T) {
+	stored := json.RawMessage(`{"url": "https://hooks.example.com/x", "secret": "shh"}`)
+
+	redacted := RedactConfig("webhook", stored)
+	assert.NotContains(t, string(redacted), "shh")
+	assert.Contains(t, string(redacted), "hooks.example.com")
+
+	// Sending the redacted placeholder back keeps the stored secret
+	merged := MergeSecrets("webhook", redacted, stored)
+	var config webhookConfig
+	require.NoError(t, json.Unmarshal(merged, &config))
+	assert.Equal(t, "shh", config.Secret)
+}
+
+func TestDispatch_SendsToEnabledChannels(t *testing.T) {
+	setupTestDB(t)
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	for id, enabled := range map[string]bool{"channel-on": true, "channel-off": false} {
+		require.NoError(t, db.SaveChannel(models.NotificationChannel{
+			ID:        id,
+			Name:      "hook",
+			Type:      "webhook",
+			Config:    json.RawMessage(`{"url": "` + server.URL + `"}`),
+			Enabled:   enabled,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	Dispatch(testEvent())
+	Wait()
+
+	assert.Len(t, received, 1)
+}
+
+func TestDispatch_OnlyToTheMonitorsTeam(t *testing.T) {
+	setupTestDB(t)
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	require.NoError(t, db.SaveTeam(models.Team{ID: "other", OrgID: models.DefaultTeam, Name: "Other", CreatedAt: time.Now()}))
+
+	for id, teamID := range map[string]string{"channel-mine": models.DefaultTeam, "channel-theirs": "other"} {
+		require.NoError(t, db.SaveChannel(models.NotificationChannel{
+			ID:        id,
+			TeamID:    teamID,
+			Name:      "hook",
+			Type:      "webhook",
+			Config:    json.RawMessage(`{"url": "` + server.URL + `"}`),
+			Enabled:   true,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	Dispatch(testEvent())
+	Wait()
+
+	assert.Len(t, received, 1)
+}
@@ -17,7 +17,53 @@ func RegisterRoutes() *http.ServeMux {
 	mux.HandleFunc("POST /monitor", handlers.CreateMonitor)
 	mux.HandleFunc("GET /monitor", handlers.ListMonitors)
 	mux.HandleFunc("GET /monitor/{id}", handlers.GetMonitor)
+	mux.HandleFunc("PUT /monitor/{id}", handlers.UpdateMonitor)
+	mux.HandleFunc("PATCH /monitor/{id}", handlers.UpdateMonitor)
+	mux.HandleFunc("DELETE /monitor/{id}", handlers.DeleteMonitor)
 	mux.HandleFunc("POST /monitor/{id}/check", handlers.TriggerCheck)
+	mux.HandleFunc("GET /monitor/{id}/results", handlers.GetMonitorResults)
+	mux.HandleFunc("GET /monitor/{id}/stats", handlers.GetMonitorStats)
+	mux.HandleFunc("GET /monitor/{id}/incidents", handlers.ListMonitorIncidents)
+	mux.HandleFunc("GET /incidents", handlers.ListIncidents)
+
+	mux.HandleFunc("POST /channels", handlers.CreateChannel)
+	mux.HandleFunc("GET /channels", handlers.ListChannels)
+	mux.HandleFunc("GET /channels/{id}", handlers.GetChannel)
+	mux.HandleFunc("PUT /channels/{id}", handlers.UpdateChannel)
+	mux.HandleFunc("PATCH /channels/{id}", handlers.UpdateChannel)
+	mux.HandleFunc("DELETE /channels/{id}", handlers.DeleteChannel)
+	mux.HandleFunc("GET /channels/{id}/deliveries", handlers.ListChannelDeliveries)
+	mux.HandleFunc("GET /deliveries", handlers.ListDeliveries)
+	mux.HandleFunc("POST /monitor/{id}/pause", handlers.PauseMonitor)
+	mux.HandleFunc("POST /monitor/{id}/resume", handlers.ResumeMonitor)
+
+	mux.HandleFunc("POST /keys", handlers.CreateAPIKey)
+	mux.HandleFunc("GET /keys", handlers.ListAPIKeys)
+	mux.HandleFunc("DELETE /keys/{id}", handlers.RevokeAPIKey)
+
+	mux.HandleFunc("POST /login", handlers.Login)
+	mux.HandleFunc("POST /logout", handlers.Logout)
+	mux.HandleFunc("GET /login/oidc", handlers.OIDCLogin)
+	mux.HandleFunc("GET /login/oidc/callback", handlers.OIDCCallback)
+	mux.HandleFunc("GET /me", handlers.Me)
+	mux.HandleFunc("POST /users", handlers.CreateUser)
+	mux.HandleFunc("GET /users", handlers.ListUsers)
+	mux.HandleFunc("PATCH /users/{id}", handlers.UpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", handlers.DeleteUser)
+
+	mux.HandleFunc("POST /orgs", handlers.CreateOrganization)
+	mux.HandleFunc("GET /orgs", handlers.ListOrganizations)
+	mux.HandleFunc("POST /teams", handlers.CreateTeam)
+	mux.HandleFunc("GET /teams", handlers.ListTeams)
+	mux.HandleFunc("POST /teams/{id}/members", handlers.AddTeamMember)
+	mux.HandleFunc("DELETE /teams/{id}/members/{user_id}", handlers.RemoveTeamMember)
+
+	mux.HandleFunc("GET /audit", handlers.ListAudit)
+
+	// Heartbeat monitors' jobs check in here, authenticated by the token alone (no API key)
+	mux.HandleFunc("POST /ping/{token}", handlers.Ping)
+	mux.HandleFunc("POST /ping/{token}/start", handlers.PingStart)
+	mux.HandleFunc("POST /ping/{token}/fail", handlers.PingFail)
 
 	return mux
 }
@@ -33,6 +79,8 @@ func SetupServer() http.Handler {
 	// Wrap handler with Datadog tracing
 	handler = httptrace.WrapHandler(handler, "url-monitor", "/")
 
+	// Every route but pings and logging in needs an API key or a logged in user with enough access
+	handler = middleware.AuthMiddleware(handler)
 	handler = middleware.JSONMiddleware(handler)
 	handler = middleware.CORSMiddleware(handler)
 	handler = middleware.MetricsMiddleware(handler)
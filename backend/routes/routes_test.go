@@ -0,0 +1,216 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/auth"
+	"github.com/KerlynD/URL-Monitor/backend/auth/oidctest"
+	"github.com/KerlynD/URL-Monitor/backend/db"
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	db.InitDB(":memory:")
+	defer db.CloseDB()
+	m.Run()
+}
+
+// setupTestDB initializes a clean database for each test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db.CloseDB()
+	err := db.InitDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.CloseDB()
+	})
+}
+
+// setupSSO points single sign-on at a mock identity provider for the test
+func setupSSO(t *testing.T) *oidctest.Provider {
+	t.Helper()
+
+	mock := oidctest.NewProvider(t, "url-monitor", "client-secret")
+
+	provider, err := auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:       mock.Issuer(),
+		ClientID:     "url-monitor",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://api.example.com/login/oidc/callback",
+		AfterLogin:   "http://dashboard.example.com/",
+		GroupRoles:   map[string]string{"sre": models.RoleEditor},
+	})
+	require.NoError(t, err)
+
+	auth.SSO = provider
+	t.Cleanup(func() {
+		auth.SSO = nil
+	})
+
+	return mock
+}
+
+// ssoLogin walks a browser through single sign-on and returns the callback's response
+func ssoLogin(t *testing.T, server http.Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	// Starting the login sends the browser to the provider
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/login/oidc", nil))
+	require.Equal(t, http.StatusFound, rr.Code, rr.Body.String())
+
+	stateCookie := rr.Result().Cookies()[0]
+	require.Equal(t, auth.OIDCStateCookie, stateCookie.Name)
+
+	// The provider logs the user in and sends the browser back with a code
+	browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := browser.Get(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusFound, response.StatusCode)
+
+	callback, err := url.Parse(response.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/login/oidc/callback", callback.Path)
+
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(stateCookie)
+	rr = httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestOIDCLogin_FullFlow(t *testing.T) {
+	setupTestDB(t)
+	mock := setupSSO(t)
+	mock.Claims = map[string]any{"email": "Alice@Example.com", "groups": []string{"sre"}}
+
+	server := SetupServer()
+
+	rr := ssoLogin(t, server)
+	require.Equal(t, http.StatusFound, rr.Code, rr.Body.String())
+	assert.Equal(t, "http://dashboard.example.com/", rr.Header().Get("Location"))
+
+	var session *http.Cookie
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == auth.SessionCookie {
+			session = cookie
+		}
+	}
+	require.NotNil(t, session)
+
+	// The session works like a password login's, with the role the groups map to
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(session)
+	rr = httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"email":"alice@example.com"`)
+	assert.Contains(t, rr.Body.String(), `"role":"editor"`)
+
+	// Logging in again finds the same user
+	ssoLogin(t, server)
+	users, err := db.GetUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestOIDCLogin_AuditsCreatedUsers(t *testing.T) {
+	setupTestDB(t)
+	mock := setupSSO(t)
+	mock.Claims = map[string]any{"email": "alice@example.com", "groups": []string{"sre"}}
+
+	server := SetupServer()
+
+	rr := ssoLogin(t, server)
+	require.Equal(t, http.StatusFound, rr.Code, rr.Body.String())
+
+	users, err := db.GetUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	entries, err := db.GetAuditEntries(db.AuditFilter{TargetType: models.AuditUser})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "user.create", entries[0].Action)
+	assert.Equal(t, users[0].ID, entries[0].TargetID)
+	assert.Equal(t, models.ActorSystem, entries[0].ActorType, "nobody is logged in to make the change")
+	assert.Contains(t, string(entries[0].After), `"sso_issuer":"`+mock.Issuer()+`"`)
+
+	// A login that changes nothing isn't audited
+	ssoLogin(t, server)
+	entries, err = db.GetAuditEntries(db.AuditFilter{TargetType: models.AuditUser})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestOIDCLogin_GroupWithoutAccess(t *testing.T) {
+	setupTestDB(t)
+	mock := setupSSO(t)
+	mock.Claims = map[string]any{"email": "bob@example.com", "groups": []string{"marketing"}}
+
+	rr := ssoLogin(t, SetupServer())
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	for _, cookie := range rr.Result().Cookies() {
+		assert.NotEqual(t, auth.SessionCookie, cookie.Name)
+	}
+}
+
+func TestOIDCCallback_RejectsForeignState(t *testing.T) {
+	setupTestDB(t)
+	setupSSO(t)
+
+	// A callback the browser didn't start, e.g. a login CSRF attempt
+	req := httptest.NewRequest(http.MethodGet, "/login/oidc/callback?code=abc&state=xyz", nil)
+	rr := httptest.NewRecorder()
+	SetupServer().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestTriggerCheck_NeedsWriteScope(t *testing.T) {
+	setupTestDB(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	require.NoError(t, db.SaveMonitor(models.MonitorEntry{
+		ID:        "test-monitor",
+		TeamID:    models.DefaultTeam,
+		URL:       target.URL,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}))
+
+	_, readSecret, err := auth.CreateKey("dashboard", models.ScopeRead, models.DefaultTeam)
+	require.NoError(t, err)
+	_, writeSecret, err := auth.CreateKey("ci", models.ScopeWrite, models.DefaultTeam)
+	require.NoError(t, err)
+
+	server := SetupServer()
+
+	// Running a check records a result and can open an incident, so it is a write
+	check := func(secret string) int {
+		req := httptest.NewRequest(http.MethodPost, "/monitor/test-monitor/check", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, check(readSecret))
+	assert.Equal(t, http.StatusOK, check(writeSecret))
+}
@@ -0,0 +1,155 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Prefix on every sealed value, so the format can change later
+const sealedPrefix = "v1:"
+
+// ErrNoKey is returned when sealing or opening a value before Init has set the key
+var ErrNoKey = errors.New("no encryption key, secrets.Init was never called")
+
+var (
+	key   []byte
+	keyMu sync.Mutex
+)
+
+/*
+Function to set the key used to encrypt stored credentials. A passphrase (the
+SECRET_KEY env var) wins; otherwise a random key is read from keyFile, and
+created there on first start.
+*/
+func Init(passphrase string, keyFile string) error {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+
+	if passphrase != "" {
+		sum := sha256.Sum256([]byte(passphrase))
+		key = sum[:]
+		return nil
+	}
+
+	contents, err := os.ReadFile(keyFile)
+	if err == nil {
+		decoded, err := hex.DecodeString(strings.TrimSpace(string(contents)))
+		if err != nil || len(decoded) != 32 {
+			return fmt.Errorf("key file %s must hold 32 hex-encoded bytes", keyFile)
+		}
+		key = decoded
+		return nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading key file: %w", err)
+	}
+
+	generated := make([]byte, 32)
+	_, err = rand.Read(generated)
+	if err != nil {
+		return fmt.Errorf("error generating key: %w", err)
+	}
+
+	err = os.WriteFile(keyFile, []byte(hex.EncodeToString(generated)+"\n"), 0600)
+	if err != nil {
+		return fmt.Errorf("error writing key file: %w", err)
+	}
+
+	log.Printf("Generated a new encryption key in %s", keyFile)
+	key = generated
+	return nil
+}
+
+// currentKey returns the configured key. Without one, anything sealed would be lost on restart.
+func currentKey() ([]byte, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+
+	if key == nil {
+		return nil, ErrNoKey
+	}
+	return key, nil
+}
+
+/*
+Function to encrypt a value with AES-256-GCM for storage
+*/
+func Seal(plaintext []byte) (string, error) {
+	key, err := currentKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("error creating gcm: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+/*
+Function to decrypt a value produced by Seal
+*/
+func Open(sealed string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unrecognised sealed value")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding sealed value: %w", err)
+	}
+
+	key, err := currentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcm: %w", err)
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting sealed value (wrong key?): %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	require.NoError(t, Init("correct horse battery staple", ""))
+
+	sealed, err := Seal([]byte("hunter2"))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "hunter2")
+
+	plaintext, err := Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(plaintext))
+
+	// A different key can't read it
+	require.NoError(t, Init("another passphrase", ""))
+	_, err = Open(sealed)
+	assert.Error(t, err)
+}
+
+func TestSeal_NeedsAKey(t *testing.T) {
+	keyMu.Lock()
+	key = nil
+	keyMu.Unlock()
+
+	_, err := Seal([]byte("hunter2"))
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	_, err = Open(sealedPrefix + "AAAA")
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestInit_KeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "secret.key")
+
+	require.NoError(t, Init("", keyFile))
+	sealed, err := Seal([]byte("token"))
+	require.NoError(t, err)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Restarting with the same key file reads it back instead of generating a new key
+	require.NoError(t, Init("", keyFile))
+	plaintext, err := Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "token", string(plaintext))
+}
@@ -2,40 +2,79 @@ package worker
 
 import (
 	"log"
+	"net/url"
 	"time"
 
+	"github.com/KerlynD/URL-Monitor/backend/checks"
 	"github.com/KerlynD/URL-Monitor/backend/db"
-	"github.com/KerlynD/URL-Monitor/backend/handlers"
+	"github.com/KerlynD/URL-Monitor/backend/incidents"
 	"github.com/KerlynD/URL-Monitor/backend/metrics"
+	"github.com/KerlynD/URL-Monitor/backend/models"
 	tracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
+// How often the scheduler re-reads monitors from the DB to pick up runtime changes
+const scheduleSyncInterval = 5 * time.Second
+
+// Shortest the worker will sleep between cycles, so a busy schedule can't spin
+const minScheduleWait = 100 * time.Millisecond
+
+// Schedule and worker pool shared by the background loop and checkAllMonitors
+var (
+	scheduler = NewScheduler(30 * time.Second)
+	pool      = NewPool(10, 2)
+)
+
 /*
-Function to start a background goroutine that checks all monitors at a given interval
+Function to start a background goroutine that checks each monitor on its own check_interval
 */
-func StartMonitorChecker(interval time.Duration) {
+func StartMonitorChecker(defaultInterval time.Duration, concurrency int, perHostLimit int) {
 	/*
-		This function generates a ticker based off the given interval, then enters a goroutine
-		that immediately checks all monitors then loops off each tick checking each monitor.
+		This function creates a fresh scheduler (monitors without a check_interval use
+		defaultInterval) and a worker pool that runs at most concurrency checks at once,
+		and at most perHostLimit against any one hostname. It then enters a goroutine that hands whatever is due to the pool
+		and sleeps until the next monitor is due or the next DB sync, whichever comes first. It never waits for the checks
+		themselves; each monitor is rescheduled as its own check finishes.
 	*/
 
-	ticker := time.NewTicker(interval)
+	scheduler = NewScheduler(defaultInterval)
+	pool.Close()
+	pool = NewPool(concurrency, perHostLimit)
 
 	go func() {
-		checkAllMonitors()
-
-		for range ticker.C {
+		for {
 			checkAllMonitors()
+			time.Sleep(untilNextRun())
 		}
 	}()
 
-	log.Println("Monitor checker started with interval", interval)
+	log.Printf("Monitor checker started with default interval %v, concurrency %d, per-host limit %d",
+		defaultInterval, concurrency, perHostLimit)
+}
+
+// untilNextRun is how long the loop can sleep before something needs attention
+func untilNextRun() time.Duration {
+	wait := scheduleSyncInterval
+
+	if nextRun, ok := scheduler.NextRun(); ok {
+		if untilDue := time.Until(nextRun); untilDue < wait {
+			wait = untilDue
+		}
+	}
+
+	if wait < minScheduleWait {
+		wait = minScheduleWait
+	}
+
+	return wait
 }
 
 func checkAllMonitors() {
 	/*
-		This function gets all monitors from the database, then loops through each monitor,
-		calling handlers.PerformCheck() to check the monitor. We then save the result to the db.
+		This function gets all monitors from the database and syncs the unpaused ones into the scheduler,
+		then hands each monitor that is due to the worker pool, which checks them concurrently
+		within the global and per-host limits. The cycle ends once they are handed out, so a slow
+		check only delays its own monitor; monitors still being checked aren't due again until they finish.
 	*/
 	
 	span := tracer.StartSpan("worker.check_all_monitors",
@@ -44,7 +83,8 @@ func checkAllMonitors() {
 	)
 	defer span.Finish()
 
-	monitors, err := db.GetAllMonitors()
+	// The worker checks every team's monitors
+	monitors, err := db.GetAllMonitors(db.AllTeams)
 	if err != nil {
 		span.SetTag("error", true)
 		span.SetTag("error.message", err.Error())
@@ -55,83 +95,229 @@ func checkAllMonitors() {
 		return
 	}
 
+	// Paused monitors are left out of the schedule entirely
+	var active []models.MonitorEntry
+	for _, monitor := range monitors {
+		if !monitor.Paused {
+			active = append(active, monitor)
+		}
+	}
+
 	// Track how many monitors are being checked
 	if metrics.Client != nil {
-		metrics.Client.Gauge("monitors.active", float64(len(monitors)), nil, 1.0)
+		metrics.Client.Gauge("monitors.active", float64(len(active)), nil, 1.0)
 	}
 
-	for _, monitor := range monitors {
-		checkSpan := tracer.StartSpan("worker.check_monitor",
-			tracer.ChildOf(span.Context()),
-			tracer.Tag("monitor.url", monitor.URL),
-			tracer.Tag("monitor.id", monitor.ID),
-		)
-
-		// Track check attempt
-		if metrics.Client != nil {
-			metrics.Client.Incr("checks.performed",
-				[]string{"url:" + monitor.URL}, 1.0)
+	// Heartbeat monitors aren't polled: their jobs ping in, and only a missed ping is recorded here
+	var polled []models.MonitorEntry
+	for _, monitor := range active {
+		if monitor.Type == models.MonitorHeartbeat {
+			checkHeartbeat(span, monitor, time.Now())
+			continue
 		}
+		polled = append(polled, monitor)
+	}
 
-		// Time the check operation
-		startTime := time.Now()
-		result := handlers.PerformCheck(monitor.URL)
-		checkDuration := time.Since(startTime)
+	scheduler.Sync(polled, time.Now())
 
-		checkSpan.SetTag("check.isUp", result.IsUp)
-		checkSpan.SetTag("check.responseTime", result.ResponseTime)
-		checkSpan.SetTag("check.statusCode", result.StatusCode)
+	// Fan due monitors out to the pool without waiting on them. Each check reports
+	// back to the schedule it came from, even if a restart has replaced it since.
+	schedule := scheduler
+	due := schedule.Due(time.Now())
+	span.SetTag("monitors.due", len(due))
 
-		// Record check duration
-		if metrics.Client != nil {
-			metrics.Client.Timing("checks.duration",
-				checkDuration,
-				[]string{"url:" + monitor.URL}, 1.0)
-		}
+	for _, monitor := range due {
+		pool.Submit(monitorHost(monitor), func() {
+			checkMonitor(schedule, monitor)
+		})
+	}
 
-		// Record the actual response time from the URL
-		if metrics.Client != nil {
-			metrics.Client.Timing("checks.response_time",
-				result.ResponseTime,
-				[]string{"url:" + monitor.URL}, 1.0)
-		}
+	// Track cycle completion
+	if metrics.Client != nil {
+		metrics.Client.Incr("worker.check_cycle.complete", nil, 1.0)
+	}
+}
 
-		// Track success/failure
-		if metrics.Client != nil {
-			if result.IsUp {
-				metrics.Client.Incr("checks.success",
-					[]string{"url:" + monitor.URL}, 1.0)
-			} else {
-				metrics.Client.Incr("checks.failure",
-					[]string{"url:" + monitor.URL}, 1.0)
+func checkMonitor(schedule *Scheduler, monitor models.MonitorEntry) {
+	/*
+		This function checks a single monitor with the checker for its type, records the
+		check metrics, puts the monitor back in the schedule, and hands the result to
+		recordResult() to be saved. Checks outlive the cycle that started them, so each
+		one is its own trace.
+	*/
+	checkSpan := tracer.StartSpan("worker.check_monitor",
+		tracer.Tag("monitor.url", monitor.URL),
+		tracer.Tag("monitor.id", monitor.ID),
+		tracer.Tag("monitor.type", monitor.Type),
+		tracer.Tag("monitor.team", monitor.TeamID),
+	)
+	defer checkSpan.Finish()
+
+	// Track check attempt
+	if metrics.Client != nil {
+		metrics.Client.Incr("checks.performed",
+			metrics.MonitorTags(monitor), 1.0)
+	}
+
+	// Time the check operation
+	startTime := time.Now()
+	result := checks.Run(monitor)
+	checkDuration := time.Since(startTime)
+
+	// Due again one interval after it started, now that it is no longer in flight
+	if nextRun, ok := schedule.Finish(monitor.ID, time.Now()); ok {
+		monitor.NextCheckAt = &nextRun
+	}
+
+	checkSpan.SetTag("check.isUp", result.IsUp)
+	checkSpan.SetTag("check.responseTime", result.ResponseTime)
+	checkSpan.SetTag("check.statusCode", result.StatusCode)
+	if result.GRPCStatus != "" {
+		checkSpan.SetTag("check.grpcStatus", result.GRPCStatus)
+	}
+	if len(result.Attempts) > 0 {
+		checkSpan.SetTag("check.attempts", len(result.Attempts))
+	}
+
+	// Record check duration
+	if metrics.Client != nil {
+		metrics.Client.Timing("checks.duration",
+			checkDuration,
+			metrics.MonitorTags(monitor), 1.0)
+	}
+
+	// Record the actual response time from the URL
+	if metrics.Client != nil {
+		metrics.Client.Timing("checks.response_time",
+			result.ResponseTime,
+			metrics.MonitorTags(monitor), 1.0)
+	}
+
+	// Record each network phase that happened during the check
+	if metrics.Client != nil && result.Timings != nil {
+		phases := map[string]time.Duration{
+			"checks.dns_lookup":       result.Timings.DNSLookup,
+			"checks.tcp_connect":      result.Timings.TCPConnect,
+			"checks.tls_handshake":    result.Timings.TLSHandshake,
+			"checks.ttfb":             result.Timings.TimeToFirstByte,
+			"checks.content_transfer": result.Timings.ContentTransfer,
+		}
+		for name, duration := range phases {
+			if duration > 0 {
+				metrics.Client.Timing(name, duration, metrics.MonitorTags(monitor), 1.0)
 			}
 		}
+	}
 
-		saveResultSpan := tracer.StartSpan("db.save_result",
-			tracer.ChildOf(checkSpan.Context()),
-			tracer.Tag("monitor.id", monitor.ID),
-		)
-		err = db.SaveResult(monitor.ID, result)
-		saveResultSpan.Finish()
+	// Track how long the certificate has left, for https monitors
+	if metrics.Client != nil && result.Certificate != nil {
+		metrics.Client.Gauge("checks.cert_days_remaining",
+			float64(result.Certificate.DaysRemaining),
+			metrics.MonitorTags(monitor), 1.0)
+	}
 
-		if err != nil {
-			saveResultSpan.SetTag("error", true)
-			saveResultSpan.SetTag("error.message", err.Error())
-			
-			log.Printf("Error saving result for monitor %s: %v", monitor.ID, err)
+	// Track the health status grpc monitors report
+	if metrics.Client != nil && result.GRPCStatus != "" {
+		metrics.Client.Incr("checks.grpc_status",
+			append(metrics.MonitorTags(monitor), "status:"+result.GRPCStatus), 1.0)
+	}
 
-			if metrics.Client != nil {
-				metrics.Client.Incr("worker.save_result.error", nil, 1.0)
-			}
-			continue
+	// Track the extra attempts retries and confirmations cost
+	if metrics.Client != nil && len(result.Attempts) > 1 {
+		metrics.Client.Count("checks.retries",
+			int64(len(result.Attempts)-1),
+			metrics.MonitorTags(monitor), 1.0)
+	}
+
+	// Track success/failure, once the retries have settled it
+	if metrics.Client != nil {
+		if result.IsUp {
+			metrics.Client.Incr("checks.success",
+				metrics.MonitorTags(monitor), 1.0)
+		} else {
+			metrics.Client.Incr("checks.failure",
+				metrics.MonitorTags(monitor), 1.0)
 		}
+	}
 
-		checkSpan.Finish()
-		log.Printf("Checked monitor %s, result: %+v", monitor.ID, result)
+	recordResult(checkSpan, monitor, result)
+}
+
+/*
+Function to record a missed ping for a heartbeat monitor whose job is overdue
+*/
+func checkHeartbeat(parent tracer.Span, monitor models.MonitorEntry, now time.Time) {
+	result, overdue, err := checks.MissedPing(monitor, now)
+	if err != nil {
+		log.Printf("Error checking heartbeat for monitor %s: %v", monitor.ID, err)
+		return
+	}
+	if !overdue {
+		return
 	}
 
-	// Track cycle completion
+	heartbeatSpan := tracer.StartSpan("worker.check_heartbeat",
+		tracer.ChildOf(parent.Context()),
+		tracer.Tag("monitor.url", monitor.URL),
+		tracer.Tag("monitor.id", monitor.ID),
+		tracer.Tag("monitor.team", monitor.TeamID),
+		tracer.Tag("check.isUp", false),
+	)
+	defer heartbeatSpan.Finish()
+
 	if metrics.Client != nil {
-		metrics.Client.Incr("worker.check_cycle.complete", nil, 1.0)
+		metrics.Client.Incr("heartbeats.missed",
+			metrics.MonitorTags(monitor), 1.0)
+	}
+
+	recordResult(heartbeatSpan, monitor, result)
+}
+
+func recordResult(checkSpan tracer.Span, monitor models.MonitorEntry, result models.MonitorResult) {
+	/*
+		This function saves a result and, for polled monitors, the new schedule to the
+		db, then opens or resolves an incident if the monitor changed state
+	*/
+	saveResultSpan := tracer.StartSpan("db.save_result",
+		tracer.ChildOf(checkSpan.Context()),
+		tracer.Tag("monitor.id", monitor.ID),
+	)
+	err := db.SaveResult(monitor.ID, result)
+
+	if err != nil {
+		saveResultSpan.SetTag("error", true)
+		saveResultSpan.SetTag("error.message", err.Error())
+		saveResultSpan.Finish()
+
+		log.Printf("Error saving result for monitor %s: %v", monitor.ID, err)
+
+		if metrics.Client != nil {
+			metrics.Client.Incr("worker.save_result.error", nil, 1.0)
+		}
+		return
+	}
+	saveResultSpan.Finish()
+
+	if monitor.NextCheckAt != nil {
+		err = db.UpdateMonitorSchedule(monitor.ID, result.Timestamp, *monitor.NextCheckAt)
+		if err != nil {
+			log.Printf("Error saving schedule for monitor %s: %v", monitor.ID, err)
+		}
+	}
+
+	_, err = incidents.Evaluate(monitor, result)
+	if err != nil {
+		log.Printf("Error evaluating incidents for monitor %s: %v", monitor.ID, err)
+	}
+
+	log.Printf("Checked monitor %s, result: %+v", monitor.ID, result)
+}
+
+// monitorHost is the hostname a monitor's checks count against for the per-host cap
+func monitorHost(monitor models.MonitorEntry) string {
+	parsedURL, err := url.Parse(monitor.URL)
+	if err != nil || parsedURL.Hostname() == "" {
+		return monitor.URL
 	}
+	return parsedURL.Hostname()
 }
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/KerlynD/URL-Monitor/backend/metrics"
+)
+
+// poolJob is a submitted check and the hostname it counts against
+type poolJob struct {
+	host string
+	run  func()
+}
+
+/*
+Pool runs checks on a fixed set of workers with a cap per hostname, so one origin
+with many monitored paths isn't hit with all of them at once
+*/
+type Pool struct {
+	perHost int
+
+	mu      sync.Mutex
+	ready   *sync.Cond
+	pending []poolJob
+	running map[string]int // <- Jobs in flight per hostname
+	closed  bool
+
+	queued   atomic.Int64
+	inFlight atomic.Int64
+}
+
+/*
+Function to create a pool and start its concurrency workers
+*/
+func NewPool(concurrency, perHost int) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if perHost < 1 || perHost > concurrency {
+		perHost = concurrency
+	}
+
+	p := &Pool{
+		perHost: perHost,
+		running: make(map[string]int),
+	}
+	p.ready = sync.NewCond(&p.mu)
+
+	for i := 0; i < concurrency; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+/*
+Function to queue a job for the first free worker. Jobs whose host is already at
+its cap wait without holding a worker, so other hosts keep moving.
+*/
+func (p *Pool) Submit(host string, job func()) {
+	p.mu.Lock()
+	p.pending = append(p.pending, poolJob{host: host, run: job})
+	p.queued.Add(1)
+	p.mu.Unlock()
+
+	p.reportGauges()
+	p.ready.Signal()
+}
+
+/*
+Function to stop the workers once they finish their current job. Jobs still
+queued are dropped.
+*/
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.queued.Add(-int64(len(p.pending)))
+	p.pending = nil
+	p.mu.Unlock()
+
+	p.ready.Broadcast()
+}
+
+// QueueDepth is the number of submitted jobs still waiting for a worker
+func (p *Pool) QueueDepth() int64 { return p.queued.Load() }
+
+// InFlight is the number of jobs currently running
+func (p *Pool) InFlight() int64 { return p.inFlight.Load() }
+
+func (p *Pool) work() {
+	for {
+		p.mu.Lock()
+		job, ok := p.next()
+		for !ok && !p.closed {
+			p.ready.Wait()
+			job, ok = p.next()
+		}
+		if !ok {
+			p.mu.Unlock()
+			return
+		}
+
+		p.running[job.host]++
+		p.inFlight.Add(1) // <- Before queued drops, so the two never both read zero mid-handoff
+		p.queued.Add(-1)
+		p.mu.Unlock()
+		p.reportGauges()
+
+		job.run()
+
+		p.mu.Lock()
+		p.running[job.host]--
+		if p.running[job.host] == 0 {
+			delete(p.running, job.host)
+		}
+		p.inFlight.Add(-1)
+		p.mu.Unlock()
+		p.reportGauges()
+
+		// A slot for this host opened up, which may unblock a job another worker skipped
+		p.ready.Broadcast()
+	}
+}
+
+// next takes the oldest pending job whose host has room. Callers hold p.mu.
+func (p *Pool) next() (poolJob, bool) {
+	for i, job := range p.pending {
+		if p.running[job.host] < p.perHost {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			return job, true
+		}
+	}
+	return poolJob{}, false
+}
+
+func (p *Pool) reportGauges() {
+	if metrics.Client != nil {
+		metrics.Client.Gauge("checks.queue_depth", float64(p.queued.Load()), nil, 1.0)
+		metrics.Client.Gauge("checks.in_flight", float64(p.inFlight.Load()), nil, 1.0)
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runJobs submits one job per host entry and returns the peak concurrency seen overall and per host
+func runJobs(p *Pool, hosts []string) (int64, map[string]int64) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running atomic.Int64
+		peak    atomic.Int64
+		perHost = map[string]int64{}
+		hostMax = map[string]int64{}
+	)
+
+	for _, host := range hosts {
+		wg.Add(1)
+		p.Submit(host, func() {
+			defer wg.Done()
+
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+
+			mu.Lock()
+			perHost[host]++
+			if perHost[host] > hostMax[host] {
+				hostMax[host] = perHost[host]
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			perHost[host]--
+			mu.Unlock()
+			running.Add(-1)
+		})
+	}
+
+	wg.Wait()
+	return peak.Load(), hostMax
+}
+
+func TestPool_RespectsGlobalLimit(t *testing.T) {
+	p := NewPool(3, 3)
+
+	peak, _ := runJobs(p, []string{"a", "b", "c", "d", "e", "f", "g", "h"})
+
+	assert.LessOrEqual(t, peak, int64(3))
+	assert.Zero(t, p.QueueDepth())
+	assert.Zero(t, p.InFlight())
+}
+
+func TestPool_RespectsPerHostLimit(t *testing.T) {
+	// One busy origin is capped while the other host still gets slots
+	p := NewPool(10, 2)
+
+	_, hostMax := runJobs(p, []string{"busy", "busy", "busy", "busy", "busy", "busy", "other"})
+
+	assert.LessOrEqual(t, hostMax["busy"], int64(2))
+	assert.Equal(t, int64(1), hostMax["other"])
+}
@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+)
+
+// scheduledMonitor is one entry in the schedule queue
+type scheduledMonitor struct {
+	monitor models.MonitorEntry
+	lastRun time.Time
+	nextRun time.Time
+	index   int  // <- Position in the heap, kept up to date by Swap/Push; -1 while in flight
+	running bool // <- Handed out by Due and not finished yet, so out of the heap
+	dropped bool // <- Removed by Sync while running; Finish forgets it instead of requeuing
+}
+
+// scheduleQueue is a min-heap of monitors ordered by when they are next due
+type scheduleQueue []*scheduledMonitor
+
+func (q scheduleQueue) Len() int { return len(q) }
+
+func (q scheduleQueue) Less(i, j int) bool { return q[i].nextRun.Before(q[j].nextRun) }
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	entry := x.(*scheduledMonitor)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*q = old[:n-1]
+	return entry
+}
+
+/*
+Scheduler keeps a next-run time for every monitor so each one is checked on its
+own check_interval instead of a single global ticker. A monitor handed out by Due
+leaves the queue until Finish says its check is done, so a slow check is never
+started twice and never holds up anyone else's schedule.
+*/
+type Scheduler struct {
+	mu              sync.Mutex
+	queue           scheduleQueue
+	entries         map[string]*scheduledMonitor
+	defaultInterval time.Duration
+}
+
+func NewScheduler(defaultInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		entries:         make(map[string]*scheduledMonitor),
+		defaultInterval: defaultInterval,
+	}
+}
+
+/*
+Function to reconcile the schedule with the monitors currently in the DB
+*/
+func (s *Scheduler) Sync(monitors []models.MonitorEntry, now time.Time) {
+	/*
+		New monitors are added (due immediately unless the DB says they were checked
+		recently), monitors whose interval changed are rescheduled from their last run,
+		and monitors that no longer exist are dropped from the queue.
+	*/
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(monitors))
+
+	for _, monitor := range monitors {
+		seen[monitor.ID] = true
+
+		entry, ok := s.entries[monitor.ID]
+		if ok && entry.dropped {
+			// Back (e.g. resumed) before its last check finished; Finish requeues it
+			entry.dropped = false
+		}
+		if !ok {
+			entry = &scheduledMonitor{monitor: monitor, nextRun: now}
+			if monitor.LastCheckAt != nil {
+				entry.lastRun = *monitor.LastCheckAt
+				entry.nextRun = entry.lastRun.Add(s.intervalFor(monitor))
+			}
+
+			s.entries[monitor.ID] = entry
+			heap.Push(&s.queue, entry)
+			continue
+		}
+
+		// In flight monitors pick up the new interval when they finish
+		if !entry.running && s.intervalFor(entry.monitor) != s.intervalFor(monitor) {
+			entry.nextRun = now
+			if !entry.lastRun.IsZero() {
+				entry.nextRun = entry.lastRun.Add(s.intervalFor(monitor))
+			}
+			heap.Fix(&s.queue, entry.index)
+		}
+
+		entry.monitor = monitor
+	}
+
+	for id, entry := range s.entries {
+		if !seen[id] {
+			if entry.running {
+				// Kept until its check finishes, so it isn't started again meanwhile
+				entry.dropped = true
+				continue
+			}
+			heap.Remove(&s.queue, entry.index)
+			delete(s.entries, id)
+		}
+	}
+}
+
+/*
+Function to pop every monitor that is due at the given time. They stay out of the
+queue until Finish is called for them.
+*/
+func (s *Scheduler) Due(now time.Time) []models.MonitorEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []models.MonitorEntry
+
+	for len(s.queue) > 0 && !s.queue[0].nextRun.After(now) {
+		entry := heap.Pop(&s.queue).(*scheduledMonitor)
+		entry.lastRun = now
+		entry.running = true
+
+		// Hand back a copy that carries when the check started, for the worker to persist
+		monitor := entry.monitor
+		lastRun := entry.lastRun
+		monitor.LastCheckAt = &lastRun
+		monitor.NextCheckAt = nil
+
+		due = append(due, monitor)
+	}
+
+	return due
+}
+
+/*
+Function to put a monitor whose check has finished back in the queue. It is due
+one interval after its check started, or straight away if the check took longer
+than that. Returns false if the monitor was removed while it was being checked.
+*/
+func (s *Scheduler) Finish(id string, finishedAt time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || !entry.running {
+		return time.Time{}, false
+	}
+
+	entry.running = false
+	if entry.dropped {
+		delete(s.entries, id)
+		return time.Time{}, false
+	}
+
+	entry.nextRun = entry.lastRun.Add(s.intervalFor(entry.monitor))
+	if entry.nextRun.Before(finishedAt) {
+		entry.nextRun = finishedAt
+	}
+	heap.Push(&s.queue, entry)
+
+	return entry.nextRun, true
+}
+
+/*
+Function to get the time the earliest monitor is due, false if nothing is scheduled
+*/
+func (s *Scheduler) NextRun() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+
+	return s.queue[0].nextRun, true
+}
+
+// intervalFor converts check_interval (seconds) to a duration, falling back to the default
+func (s *Scheduler) intervalFor(monitor models.MonitorEntry) time.Duration {
+	if monitor.CheckInterval <= 0 {
+		return s.defaultInterval
+	}
+	return time.Duration(monitor.CheckInterval) * time.Second
+}
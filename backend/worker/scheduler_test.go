@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KerlynD/URL-Monitor/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finishAll reports the checks of the given monitors as finished at finishedAt
+func finishAll(s *Scheduler, ids []string, finishedAt time.Time) {
+	for _, id := range ids {
+		s.Finish(id, finishedAt)
+	}
+}
+
+func TestScheduler_DueOrdersByInterval(t *testing.T) {
+	// Monitors are all due on first sync, then come back on their own intervals
+	now := time.Now()
+	s := NewScheduler(30 * time.Second)
+
+	s.Sync([]models.MonitorEntry{
+		{ID: "fast", CheckInterval: 10},
+		{ID: "slow", CheckInterval: 60},
+		{ID: "default"},
+	}, now)
+
+	assert.Len(t, s.Due(now), 3)
+	finishAll(s, []string{"fast", "slow", "default"}, now)
+	assert.Empty(t, s.Due(now.Add(5*time.Second)))
+
+	due := s.Due(now.Add(10 * time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "fast", due[0].ID)
+
+	nextRun, ok := s.Finish("fast", now.Add(10*time.Second))
+	require.True(t, ok)
+	assert.Equal(t, now.Add(20*time.Second), nextRun)
+
+	due = s.Due(now.Add(30 * time.Second))
+	require.Len(t, due, 2)
+	assert.ElementsMatch(t, []string{"fast", "default"}, []string{due[0].ID, due[1].ID})
+	finishAll(s, []string{"fast", "default"}, now.Add(30*time.Second))
+
+	next, ok := s.NextRun()
+	require.True(t, ok)
+	assert.Equal(t, now.Add(40*time.Second), next)
+}
+
+func TestScheduler_SyncPicksUpChanges(t *testing.T) {
+	// New monitors, interval changes and deletions are applied without a restart
+	now := time.Now()
+	s := NewScheduler(30 * time.Second)
+
+	s.Sync([]models.MonitorEntry{{ID: "a", CheckInterval: 60}}, now)
+	s.Due(now)
+	finishAll(s, []string{"a"}, now)
+
+	// Shorter interval is measured from the last run
+	s.Sync([]models.MonitorEntry{
+		{ID: "a", CheckInterval: 15},
+		{ID: "b", CheckInterval: 60},
+	}, now.Add(time.Second))
+
+	due := s.Due(now.Add(time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "b", due[0].ID)
+	finishAll(s, []string{"b"}, now.Add(time.Second))
+
+	due = s.Due(now.Add(15 * time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "a", due[0].ID)
+	finishAll(s, []string{"a"}, now.Add(15*time.Second))
+
+	// Removed monitors drop out of the queue
+	s.Sync([]models.MonitorEntry{{ID: "a", CheckInterval: 15}}, now.Add(20*time.Second))
+	due = s.Due(now.Add(time.Hour))
+	require.Len(t, due, 1)
+	assert.Equal(t, "a", due[0].ID)
+}
+
+func TestScheduler_ResumesFromLastCheck(t *testing.T) {
+	// A monitor checked before a restart isn't checked again until its interval passes
+	now := time.Now()
+	lastCheck := now.Add(-20 * time.Second)
+	s := NewScheduler(30 * time.Second)
+
+	s.Sync([]models.MonitorEntry{{ID: "a", CheckInterval: 60, LastCheckAt: &lastCheck}}, now)
+
+	assert.Empty(t, s.Due(now))
+	assert.Len(t, s.Due(now.Add(40*time.Second)), 1)
+}
+
+func TestScheduler_InFlightMonitorsWaitForTheirCheck(t *testing.T) {
+	// A slow check keeps only its own monitor out of the queue, and it isn't started twice
+	now := time.Now()
+	s := NewScheduler(30 * time.Second)
+
+	s.Sync([]models.MonitorEntry{
+		{ID: "slow", CheckInterval: 10},
+		{ID: "fast", CheckInterval: 10},
+	}, now)
+	require.Len(t, s.Due(now), 2)
+	finishAll(s, []string{"fast"}, now)
+
+	due := s.Due(now.Add(10 * time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "fast", due[0].ID, "slow is still being checked")
+	finishAll(s, []string{"fast"}, now.Add(10*time.Second))
+
+	// Its check ran past its interval, so it is due straight away
+	nextRun, ok := s.Finish("slow", now.Add(25*time.Second))
+	require.True(t, ok)
+	assert.Equal(t, now.Add(25*time.Second), nextRun)
+
+	due = s.Due(now.Add(25 * time.Second))
+	assert.ElementsMatch(t, []string{"slow", "fast"}, []string{due[0].ID, due[1].ID})
+}
+
+func TestScheduler_RemovedWhileInFlight(t *testing.T) {
+	// A monitor deleted or paused mid-check isn't requeued when the check finishes
+	now := time.Now()
+	s := NewScheduler(30 * time.Second)
+
+	s.Sync([]models.MonitorEntry{{ID: "a", CheckInterval: 10}}, now)
+	require.Len(t, s.Due(now), 1)
+
+	s.Sync(nil, now.Add(time.Second))
+
+	_, ok := s.Finish("a", now.Add(2*time.Second))
+	assert.False(t, ok)
+	assert.Empty(t, s.Due(now.Add(time.Hour)))
+}
@@ -1,6 +1,9 @@
 package worker
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,6 +30,14 @@ func setupTestDB(t *testing.T) {
 	})
 }
 
+// waitForChecks waits until the pool has run every check handed to it
+func waitForChecks(t *testing.T) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return pool.QueueDepth() == 0 && pool.InFlight() == 0
+	}, 30*time.Second, 10*time.Millisecond)
+}
+
 // <----- MAIN TEST FUNCTIONS ----->
 
 func TestCheckAllMonitors_Success(t *testing.T) {
@@ -51,6 +62,7 @@ func TestCheckAllMonitors_Success(t *testing.T) {
 	db.SaveMonitor(monitor2)
 
 	checkAllMonitors()
+	waitForChecks(t)
 
 	result1, err := db.GetLatestResult("monitor1")
 	require.NoError(t, err)
@@ -61,6 +73,43 @@ func TestCheckAllMonitors_Success(t *testing.T) {
 	assert.True(t, result2.IsUp)
 }
 
+func TestCheckAllMonitors_SkipsPaused(t *testing.T) {
+	// Paused monitors are never checked
+	setupTestDB(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	db.SaveMonitor(models.MonitorEntry{
+		ID:            "active",
+		URL:           server.URL,
+		CheckInterval: 60,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	db.SaveMonitor(models.MonitorEntry{
+		ID:            "paused",
+		URL:           server.URL,
+		CheckInterval: 60,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		Paused:        true,
+	})
+
+	scheduler = NewScheduler(30 * time.Second)
+	checkAllMonitors()
+	waitForChecks(t)
+
+	result, err := db.GetLatestResult("active")
+	require.NoError(t, err)
+	assert.True(t, result.IsUp)
+
+	_, err = db.GetLatestResult("paused")
+	assert.Error(t, err)
+}
+
 func TestStartMonitorChecker_Success(t *testing.T) {
 	// Check without crashing
 	setupTestDB(t)
@@ -75,7 +124,7 @@ func TestStartMonitorChecker_Success(t *testing.T) {
 
 	db.SaveMonitor(monitor)
 
-	StartMonitorChecker(500 * time.Millisecond)
+	StartMonitorChecker(500*time.Millisecond, 10, 2)
 
 	// Wait for at least one check cycle to complete
 	time.Sleep(750 * time.Millisecond)
@@ -86,3 +135,81 @@ func TestStartMonitorChecker_Success(t *testing.T) {
 
 	// Background goroutine will continue running briefly after test ends
 }
+
+func TestCheckAllMonitors_RecordsMissedHeartbeat(t *testing.T) {
+	// A heartbeat monitor is never polled; once its ping is overdue one down result is recorded
+	setupTestDB(t)
+
+	db.SaveMonitor(models.MonitorEntry{
+		ID:            "backup",
+		Type:          models.MonitorHeartbeat,
+		URL:           "heartbeat://backup",
+		CheckInterval: 60,
+		Heartbeat:     &models.HeartbeatConfig{Period: 60, Grace: 30},
+		PingToken:     "backup-token",
+		CreatedAt:     time.Now().Add(-2 * time.Minute),
+		UpdatedAt:     time.Now().Add(-2 * time.Minute),
+	})
+	db.SaveMonitor(models.MonitorEntry{
+		ID:            "fresh",
+		Type:          models.MonitorHeartbeat,
+		URL:           "heartbeat://fresh",
+		CheckInterval: 60,
+		Heartbeat:     &models.HeartbeatConfig{Period: 60},
+		PingToken:     "fresh-token",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+
+	scheduler = NewScheduler(30 * time.Second)
+	checkAllMonitors()
+	checkAllMonitors()
+
+	results, err := db.GetResults("backup", db.ResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 1, "a missed ping is recorded once, not every cycle")
+	assert.False(t, results[0].IsUp)
+	assert.Equal(t, models.FailurePingOverdue, results[0].FailureReason)
+
+	_, err = db.GetLatestResult("fresh")
+	assert.Error(t, err)
+}
+
+func TestCheckAllMonitors_DoesNotWaitForSlowChecks(t *testing.T) {
+	// A hanging target doesn't hold up the cycle, and isn't checked again while it hangs
+	setupTestDB(t)
+
+	release := make(chan struct{})
+	var requests atomic.Int64
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	db.SaveMonitor(models.MonitorEntry{
+		ID:            "slow",
+		URL:           slow.URL,
+		CheckInterval: 1,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+
+	scheduler = NewScheduler(30 * time.Second)
+
+	start := time.Now()
+	checkAllMonitors()
+	assert.Less(t, time.Since(start), time.Second)
+
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	time.Sleep(1100 * time.Millisecond) // <- Past its interval
+	checkAllMonitors()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(1), requests.Load(), "still in flight, so not started again")
+
+	close(release)
+	waitForChecks(t)
+}
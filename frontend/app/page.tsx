@@ -8,8 +8,13 @@ import { DemoBanner } from "@/components/demo-banner";
 import { Monitor } from "@/types/monitor";
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
+// Read-only API key the dashboard lists monitors with (safe to ship to the browser)
+const READ_API_KEY = process.env.NEXT_PUBLIC_API_KEY || "";
 const REFRESH_INTERVAL = 60000; // 60 seconds
 
+const authHeaders = (apiKey: string): Record<string, string> =>
+  apiKey ? { Authorization: `Bearer ${apiKey}` } : {};
+
 export default function Home() {
   const [monitors, setMonitors] = useState<Monitor[]>([]);
   const [loading, setLoading] = useState(true);
@@ -17,7 +22,9 @@ export default function Home() {
 
   const loadMonitors = async () => {
     try {
-      const response = await fetch(`${API_BASE_URL}/monitor`);
+      const response = await fetch(`${API_BASE_URL}/monitor`, {
+        headers: authHeaders(READ_API_KEY),
+      });
       
       if (!response.ok) {
         throw new Error("Failed to fetch monitors");
@@ -34,17 +41,17 @@ export default function Home() {
     }
   };
 
-  const handleAddMonitor = async (url: string, password: string) => {
+  const handleAddMonitor = async (url: string, apiKey: string) => {
     try {
       const response = await fetch(`${API_BASE_URL}/monitor`, {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...authHeaders(apiKey),
         },
         body: JSON.stringify({
           url: url,
           check_interval: 60,
-          password: password,
         }),
       });
 
@@ -58,6 +65,7 @@ export default function Home() {
       // Trigger immediate check
       await fetch(`${API_BASE_URL}/monitor/${newMonitor.id}/check`, {
         method: "POST",
+        headers: authHeaders(apiKey),
       });
 
       // Reload monitors
@@ -70,14 +78,17 @@ export default function Home() {
     }
   };
 
-  const handleCheckNow = async (monitorId: string) => {
+  // Running a check needs a key with write access, like adding a monitor
+  const handleCheckNow = async (monitorId: string, apiKey: string) => {
     try {
       const response = await fetch(`${API_BASE_URL}/monitor/${monitorId}/check`, {
         method: "POST",
+        headers: authHeaders(apiKey),
       });
 
       if (!response.ok) {
-        throw new Error("Failed to trigger check");
+        const error = await response.json().catch(() => ({}));
+        throw new Error(error.error || "Failed to trigger check");
       }
 
       // Reload monitors after a short delay
@@ -15,12 +15,12 @@ import {
 import { Plus, Lock } from "lucide-react";
 
 interface AddMonitorFormProps {
-  onAddMonitor: (url: string, password: string) => Promise<{ success: boolean; error?: string }>;
+  onAddMonitor: (url: string, apiKey: string) => Promise<{ success: boolean; error?: string }>;
 }
 
 export function AddMonitorForm({ onAddMonitor }: AddMonitorFormProps) {
   const [url, setUrl] = useState("");
-  const [password, setPassword] = useState("");
+  const [apiKey, setApiKey] = useState("");
   const [isDialogOpen, setIsDialogOpen] = useState(false);
   const [isSubmitting, setIsSubmitting] = useState(false);
   const [message, setMessage] = useState<{ type: "success" | "error"; text: string } | null>(null);
@@ -33,7 +33,7 @@ export function AddMonitorForm({ onAddMonitor }: AddMonitorFormProps) {
       return;
     }
 
-    // Open password dialog
+    // Open API key dialog
     setMessage(null);
     setIsDialogOpen(true);
   };
@@ -41,19 +41,19 @@ export function AddMonitorForm({ onAddMonitor }: AddMonitorFormProps) {
   const handleFinalSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
 
-    if (!password.trim()) {
-      setMessage({ type: "error", text: "Please enter the password" });
+    if (!apiKey.trim()) {
+      setMessage({ type: "error", text: "Please enter an API key" });
       return;
     }
 
     setIsSubmitting(true);
     setMessage(null);
 
-    const result = await onAddMonitor(url.trim(), password.trim());
+    const result = await onAddMonitor(url.trim(), apiKey.trim());
 
     if (result.success) {
       setUrl("");
-      setPassword("");
+      setApiKey("");
       setIsDialogOpen(false);
       setMessage({ type: "success", text: "Monitor added and checked successfully!" });
       setTimeout(() => setMessage(null), 3000);
@@ -66,7 +66,7 @@ export function AddMonitorForm({ onAddMonitor }: AddMonitorFormProps) {
 
   const handleDialogClose = () => {
     setIsDialogOpen(false);
-    setPassword("");
+    setApiKey("");
     setMessage(null);
   };
 
@@ -117,19 +117,19 @@ export function AddMonitorForm({ onAddMonitor }: AddMonitorFormProps) {
           <DialogHeader>
             <DialogTitle className="flex items-center gap-2">
               <Lock className="h-5 w-5" />
-              Password Required
+              API Key Required
             </DialogTitle>
             <DialogDescription>
-              Enter the admin password to add a new monitor for <strong className="text-foreground">{url}</strong>
+              Enter an API key with write access to add a new monitor for <strong className="text-foreground">{url}</strong>
             </DialogDescription>
           </DialogHeader>
           <form onSubmit={handleFinalSubmit}>
             <div className="space-y-4 py-4">
               <Input
                 type="password"
-                placeholder="Enter password"
-                value={password}
-                onChange={(e) => setPassword(e.target.value)}
+                placeholder="um_..."
+                value={apiKey}
+                onChange={(e) => setApiKey(e.target.value)}
                 disabled={isSubmitting}
                 autoFocus
                 required
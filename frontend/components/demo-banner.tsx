@@ -12,7 +12,7 @@ export function DemoBanner() {
             Demo Portfolio Project
           </p>
           <p className="text-xs text-blue-700 dark:text-blue-300">
-            This is a public demo. All monitors are shared across visitors. You can view and check any monitor, but you need a password to add new ones. Contact me for access!
+            This is a public demo. All monitors are shared across visitors. You can view any monitor, but you need an API key with write access to add or check them. Contact me for access!
           </p>
         </div>
       </div>
@@ -4,11 +4,21 @@ import { Monitor } from "@/types/monitor";
 import { Card, CardContent, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog";
+import { Lock } from "lucide-react";
 import { useState } from "react";
 
 interface MonitorCardProps {
   monitor: Monitor;
-  onCheckNow: (monitorId: string) => Promise<{ success: boolean; error?: string }>;
+  onCheckNow: (monitorId: string, apiKey: string) => Promise<{ success: boolean; error?: string }>;
 }
 
 function simplifyError(error: string): string {
@@ -33,6 +43,9 @@ function simplifyError(error: string): string {
 
 export function MonitorCard({ monitor, onCheckNow }: MonitorCardProps) {
   const [isChecking, setIsChecking] = useState(false);
+  const [apiKey, setApiKey] = useState("");
+  const [isDialogOpen, setIsDialogOpen] = useState(false);
+  const [checkError, setCheckError] = useState<string | null>(null);
 
   const status = monitor.last_result?.is_up ? "up" : monitor.last_result ? "down" : "unknown";
   const statusColor = status === "up" ? "success" : status === "down" ? "destructive" : "secondary";
@@ -65,12 +78,35 @@ export function MonitorCard({ monitor, onCheckNow }: MonitorCardProps) {
     return `${diffDays}d ago`;
   };
 
-  const handleCheckNow = async () => {
+  const handleCheckNow = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    if (!apiKey.trim()) {
+      setCheckError("Please enter an API key");
+      return;
+    }
+
     setIsChecking(true);
-    await onCheckNow(monitor.id);
+    setCheckError(null);
+
+    const result = await onCheckNow(monitor.id, apiKey.trim());
+
+    if (result.success) {
+      setApiKey("");
+      setIsDialogOpen(false);
+    } else {
+      setCheckError(result.error || "Failed to trigger check");
+    }
+
     setIsChecking(false);
   };
 
+  const handleDialogClose = () => {
+    setIsDialogOpen(false);
+    setApiKey("");
+    setCheckError(null);
+  };
+
   const formatUrl = (url: string) => {
     return url.replace(/^https?:\/\//, "").replace(/\/$/, "");
   };
@@ -137,12 +173,58 @@ export function MonitorCard({ monitor, onCheckNow }: MonitorCardProps) {
           variant="outline" 
           size="sm"
           className="w-full hover:bg-primary hover:text-primary-foreground transition-colors" 
-          onClick={handleCheckNow}
+          onClick={() => setIsDialogOpen(true)}
           disabled={isChecking}
         >
           {isChecking ? "Checking..." : "Check Now"}
         </Button>
       </CardFooter>
+
+      <Dialog open={isDialogOpen} onOpenChange={handleDialogClose}>
+        <DialogContent className="sm:max-w-[425px]">
+          <DialogHeader>
+            <DialogTitle className="flex items-center gap-2">
+              <Lock className="h-5 w-5" />
+              API Key Required
+            </DialogTitle>
+            <DialogDescription>
+              Enter an API key with write access to check <strong className="text-foreground">{formatUrl(monitor.url)}</strong> now
+            </DialogDescription>
+          </DialogHeader>
+          <form onSubmit={handleCheckNow}>
+            <div className="space-y-4 py-4">
+              <Input
+                type="password"
+                placeholder="um_..."
+                value={apiKey}
+                onChange={(e) => setApiKey(e.target.value)}
+                disabled={isChecking}
+                autoFocus
+                required
+              />
+
+              {checkError && (
+                <div className="bg-red-50 text-red-700 border border-red-200 dark:bg-red-950/30 dark:text-red-400 dark:border-red-900 p-3 rounded-md text-sm font-medium">
+                  {checkError}
+                </div>
+              )}
+            </div>
+            <DialogFooter>
+              <Button
+                type="button"
+                variant="outline"
+                onClick={handleDialogClose}
+                disabled={isChecking}
+              >
+                Cancel
+              </Button>
+              <Button type="submit" disabled={isChecking}>
+                {isChecking ? "Checking..." : "Check Now"}
+              </Button>
+            </DialogFooter>
+          </form>
+        </DialogContent>
+      </Dialog>
     </Card>
   );
 }
@@ -14,5 +14,7 @@ export interface Monitor {
   check_interval: number;
   created_at: string;
   updated_at: string;
+  last_check_at?: string | null;
+  next_check_at?: string | null;
   last_result?: MonitorResult;
 }